@@ -2,9 +2,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
+	"os"
 
 	"github.com/phosphor-project/phosphor/internal/bridge"
+	"github.com/phosphor-project/phosphor/internal/config"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/mac"
@@ -12,8 +16,28 @@ import (
 )
 
 func main() {
+	// "phosphor serve" takes the same settings plus -headless
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
+	}
+
+	// Load settings from the config file, PHOSPHOR_* variables and flags
+	settings, configPath, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal("[Phosphor] ", err)
+	}
+	runDesktop(settings, configPath)
+}
+
+// runDesktop opens the Wails window, which starts the receiver through the
+// bridge and stops it when the window closes.
+func runDesktop(settings config.Config, configPath string) {
 	// Create the application bridge
-	app := bridge.NewApp()
+	app := bridge.NewApp(settings, configPath)
 
 	// Configure and run the Wails application
 	err := wails.Run(&options.App{
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/phosphor-project/phosphor/internal/config"
+	"github.com/phosphor-project/phosphor/internal/receiver"
+)
+
+// defaultQueryAddress serves the query API in headless mode when no
+// query_address is configured, since there is no window to read from.
+const defaultQueryAddress = "127.0.0.1:4319"
+
+// serve runs "phosphor serve [-headless] [settings flags]".
+func serve(args []string) {
+	flags := flag.NewFlagSet("phosphor serve", flag.ContinueOnError)
+	headless := flags.Bool("headless", false, "run the receiver without a window until interrupted")
+	settings, configPath, err := config.LoadFlags(flags, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal("[Phosphor] ", err)
+	}
+
+	if !*headless {
+		runDesktop(settings, configPath)
+		return
+	}
+	runHeadless(settings)
+}
+
+// runHeadless starts the receiver and keeps it running until SIGINT or
+// SIGTERM, then drains in-flight exports and exits.
+func runHeadless(settings config.Config) {
+	if settings.Receiver.QueryAddress == "" {
+		settings.Receiver.QueryAddress = defaultQueryAddress
+	}
+
+	// Subscribe before starting so a signal during startup is not lost
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	r := receiver.NewOTLPReceiver(settings.ReceiverConfig())
+	if err := r.Start(); err != nil {
+		log.Fatal("[Phosphor] Failed to start receiver: ", err)
+	}
+	log.Printf("[Phosphor] Running headless, press Ctrl+C to stop")
+
+	sig := <-signals
+	// A second signal falls through to the default handler and exits at once
+	signal.Stop(signals)
+	log.Printf("[Phosphor] Received %v, shutting down", sig)
+	r.Stop()
+}
@@ -2,10 +2,11 @@
  * Main App Component - Root application layout
  */
 
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { useTelemetry } from './hooks';
 import { Sidebar, Header, TraceView, LogView, MetricView } from './components';
 import type { TabType } from './types';
+import { getApp, isWailsContext } from './types/wails';
 
 // ============================================================================
 // App Component
@@ -15,6 +16,14 @@ export const App: React.FC = () => {
   const [state, actions] = useTelemetry();
   const [activeTab, setActiveTab] = useState<TabType>('traces');
 
+  // Open on the tab chosen in the config file
+  useEffect(() => {
+    if (!isWailsContext()) return;
+    getApp().GetUIConfig()
+      .then((ui) => setActiveTab(ui.defaultTab))
+      .catch(console.error);
+  }, []);
+
   const [searchQueries, setSearchQueries] = useState({
     traces: '',
     metrics: '',
@@ -116,6 +125,7 @@ export const App: React.FC = () => {
           onClear={actions.clearAll}
           isLoading={state.isLoading}
           lastUpdate={state.lastUpdate}
+          droppedEvents={state.droppedEvents}
         />
 
         {/* Content Area */}
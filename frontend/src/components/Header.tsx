@@ -16,6 +16,7 @@ interface HeaderProps {
   onClear: () => void;
   isLoading: boolean;
   lastUpdate: Date | null;
+  droppedEvents: number;
 }
 
 // ============================================================================
@@ -45,6 +46,7 @@ export const Header: React.FC<HeaderProps> = ({
   onClear,
   isLoading,
   lastUpdate,
+  droppedEvents,
 }) => {
   const formatLastUpdate = (date: Date | null): string => {
     if (!date) return 'Never';
@@ -73,6 +75,14 @@ export const Header: React.FC<HeaderProps> = ({
           <span className="text-xs text-surface-500">
             Last update: <span className="text-surface-400">{formatLastUpdate(lastUpdate)}</span>
           </span>
+          {droppedEvents > 0 && (
+            <span
+              className="text-xs text-amber-400"
+              title="Events skipped to keep the UI responsive; all data remains in the backend buffers"
+            >
+              Dropped {droppedEvents.toLocaleString()} events
+            </span>
+          )}
         </div>
       </div>
 
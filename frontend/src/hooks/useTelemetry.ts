@@ -1,6 +1,6 @@
 /**
  * useTelemetry Hook
- * Manages the application state for traces, metrics, logs, and statistics.
+ * Manages the application state for traces, metrics, logs, profiles, and statistics.
  * Handles real-time updates from Wails backend and maintains ring buffers.
  */
 
@@ -9,9 +9,12 @@ import type {
   Span,
   Metric,
   LogRecord,
+  Profile,
   TelemetryStats,
   TelemetryBatch,
-  TelemetryEvent,
+  EventBatch,
+  ReceiverStatus,
+  RuntimeConfig,
   SeverityLevel,
 } from '../types/telemetry';
 import { getApp, getRuntime, isWailsContext } from '../types/wails';
@@ -24,11 +27,15 @@ export interface TelemetryState {
   traces: Span[];
   metrics: Metric[];
   logs: LogRecord[];
+  profiles: Profile[];
   stats: TelemetryStats;
   isStreaming: boolean;
   isLoading: boolean;
   error: string | null;
   lastUpdate: Date | null;
+  droppedEvents: number;
+  receiverStatus: ReceiverStatus | null;
+  config: RuntimeConfig | null;
 }
 
 export interface TelemetryActions {
@@ -37,37 +44,67 @@ export interface TelemetryActions {
   toggleStreaming: () => void;
   refresh: () => void;
   clearAll: () => void;
+  setConfig: (config: RuntimeConfig) => Promise<void>;
 }
 
 // ============================================================================
 // Constants
 // ============================================================================
 
-const MAX_BUFFER_SIZE = 1000;
+interface Capacities {
+  traces: number;
+  metrics: number;
+  logs: number;
+  profiles: number;
+}
+
+// Matches receiver.DefaultConfig; replaced by the backend's settings once loaded
+const DEFAULT_CAPACITIES: Capacities = {
+  traces: 1000,
+  metrics: 1000,
+  logs: 1000,
+  profiles: 100,
+};
 
 const INITIAL_STATS: TelemetryStats = {
   traceCount: 0,
   metricCount: 0,
   logCount: 0,
-  traceCapacity: MAX_BUFFER_SIZE,
-  metricCapacity: MAX_BUFFER_SIZE,
-  logCapacity: MAX_BUFFER_SIZE,
+  profileCount: 0,
+  traceCapacity: DEFAULT_CAPACITIES.traces,
+  metricCapacity: DEFAULT_CAPACITIES.metrics,
+  logCapacity: DEFAULT_CAPACITIES.logs,
+  profileCapacity: DEFAULT_CAPACITIES.profiles,
   traceUsage: 0,
   metricUsage: 0,
   logUsage: 0,
+  profileUsage: 0,
 };
 
 const INITIAL_STATE: TelemetryState = {
   traces: [],
   metrics: [],
   logs: [],
+  profiles: [],
   stats: INITIAL_STATS,
   isStreaming: false,
   isLoading: true,
   error: null,
   lastUpdate: null,
+  droppedEvents: 0,
+  receiverStatus: null,
+  config: null,
 };
 
+// Drops the oldest entries until the map fits its capacity
+function trimToCapacity<T>(items: Map<string, T>, capacity: number) {
+  while (items.size > capacity) {
+    const firstKey = items.keys().next().value;
+    if (firstKey === undefined) break;
+    items.delete(firstKey);
+  }
+}
+
 // ============================================================================
 // Hook Implementation
 // ============================================================================
@@ -79,20 +116,18 @@ export function useTelemetry(): [TelemetryState, TelemetryActions] {
   const tracesRef = useRef<Map<string, Span>>(new Map());
   const metricsRef = useRef<Map<string, Metric>>(new Map());
   const logsRef = useRef<Map<string, LogRecord>>(new Map());
+  const profilesRef = useRef<Map<string, Profile>>(new Map());
+  const capacitiesRef = useRef<Capacities>(DEFAULT_CAPACITIES);
 
   // Data processing helper
   const processBatch = useCallback((batch: TelemetryBatch) => {
     const now = new Date();
+    const capacities = capacitiesRef.current;
 
     // Process Traces
     if (batch.spans && batch.spans.length > 0) {
       batch.spans.forEach(span => {
         tracesRef.current.set(span.id, span);
-        // Maintain buffer size
-        if (tracesRef.current.size > MAX_BUFFER_SIZE) {
-          const firstKey = tracesRef.current.keys().next().value;
-          if (firstKey) tracesRef.current.delete(firstKey);
-        }
       });
     }
 
@@ -100,10 +135,6 @@ export function useTelemetry(): [TelemetryState, TelemetryActions] {
     if (batch.metrics && batch.metrics.length > 0) {
       batch.metrics.forEach(metric => {
         metricsRef.current.set(metric.id, metric);
-        if (metricsRef.current.size > MAX_BUFFER_SIZE) {
-          const firstKey = metricsRef.current.keys().next().value;
-          if (firstKey) metricsRef.current.delete(firstKey);
-        }
       });
     }
 
@@ -111,38 +142,64 @@ export function useTelemetry(): [TelemetryState, TelemetryActions] {
     if (batch.logs && batch.logs.length > 0) {
       batch.logs.forEach(log => {
         logsRef.current.set(log.id, log);
-        if (logsRef.current.size > MAX_BUFFER_SIZE) {
-          const firstKey = logsRef.current.keys().next().value;
-          if (firstKey) logsRef.current.delete(firstKey);
-        }
       });
     }
 
+    // Process Profiles
+    if (batch.profiles && batch.profiles.length > 0) {
+      batch.profiles.forEach(profile => {
+        profilesRef.current.set(profile.id, profile);
+      });
+    }
+
+    // Maintain buffer sizes, which may have shrunk since the last batch
+    trimToCapacity(tracesRef.current, capacities.traces);
+    trimToCapacity(metricsRef.current, capacities.metrics);
+    trimToCapacity(logsRef.current, capacities.logs);
+    trimToCapacity(profilesRef.current, capacities.profiles);
+
     // Update state efficiently
     const traceArr = Array.from(tracesRef.current.values());
     const metricArr = Array.from(metricsRef.current.values());
     const logArr = Array.from(logsRef.current.values());
+    const profileArr = Array.from(profilesRef.current.values());
 
     setState(prev => ({
       ...prev,
       traces: traceArr,
       metrics: metricArr,
       logs: logArr,
+      profiles: profileArr,
       stats: {
         traceCount: tracesRef.current.size,
         metricCount: metricsRef.current.size,
         logCount: logsRef.current.size,
-        traceCapacity: MAX_BUFFER_SIZE,
-        metricCapacity: MAX_BUFFER_SIZE,
-        logCapacity: MAX_BUFFER_SIZE,
-        traceUsage: tracesRef.current.size / MAX_BUFFER_SIZE,
-        metricUsage: metricsRef.current.size / MAX_BUFFER_SIZE,
-        logUsage: logsRef.current.size / MAX_BUFFER_SIZE,
+        profileCount: profilesRef.current.size,
+        traceCapacity: capacities.traces,
+        metricCapacity: capacities.metrics,
+        logCapacity: capacities.logs,
+        profileCapacity: capacities.profiles,
+        traceUsage: tracesRef.current.size / capacities.traces,
+        metricUsage: metricsRef.current.size / capacities.metrics,
+        logUsage: logsRef.current.size / capacities.logs,
+        profileUsage: profilesRef.current.size / capacities.profiles,
       },
       lastUpdate: now,
     }));
   }, []);
 
+  // Adopt the backend's buffer capacities, trimming what is already held
+  const applyConfig = useCallback((config: RuntimeConfig) => {
+    capacitiesRef.current = {
+      traces: config.traceCapacity,
+      metrics: config.metricCapacity,
+      logs: config.logCapacity,
+      profiles: config.profileCapacity,
+    };
+    setState(prev => ({ ...prev, config }));
+    processBatch({});
+  }, [processBatch]);
+
   // Synthetic Data Generator for Browser Dev
   useEffect(() => {
     if (isWailsContext()) return;
@@ -248,6 +305,10 @@ export function useTelemetry(): [TelemetryState, TelemetryActions] {
     // Use proper helper, not setState directly if possible, but here we need to set loading
     setState(prev => ({ ...prev, isLoading: true }));
 
+    getApp().GetConfig()
+      .then(applyConfig)
+      .catch(console.error);
+
     getApp().GetStats()
       .then((stats) => {
         setState(prev => ({ ...prev, stats, isLoading: false }));
@@ -271,26 +332,37 @@ export function useTelemetry(): [TelemetryState, TelemetryActions] {
         }));
       });
 
-    // Listen for real-time events
-    const unsubscribe = getRuntime().EventsOn("telemetry", (data: unknown) => {
+    getApp().GetReceiverStatus()
+      .then((receiverStatus) => setState(prev => ({ ...prev, receiverStatus })))
+      .catch(console.error);
+
+    // Track receiver lifecycle so degraded or failed listeners are visible
+    const unsubscribeState = getRuntime().EventsOn("receiver:state", (data: unknown) => {
+      const receiverStatus = data as ReceiverStatus;
+      setState(prev => ({
+        ...prev,
+        receiverStatus,
+        error: receiverStatus.state === 'failed'
+          ? `Receiver failed to start: ${receiverStatus.reason ?? 'unknown error'}`
+          : prev.error,
+      }));
+    });
+
+    // Listen for real-time events, coalesced by the backend into batches
+    const unsubscribe = getRuntime().EventsOn("telemetry:batch", (data: unknown) => {
       // Type assertion safe here as we control the backend emission
-      const event = data as TelemetryEvent;
-      const batch: TelemetryBatch = {};
-
-      if (event.type === 'trace' && event.span) {
-        batch.spans = [event.span];
-      } else if (event.type === 'metric' && event.metric) {
-        batch.metrics = [event.metric];
-      } else if (event.type === 'log' && event.log) {
-        batch.logs = [event.log];
+      const batch = data as EventBatch;
+      if (batch.dropped > 0) {
+        setState(prev => ({ ...prev, droppedEvents: prev.droppedEvents + batch.dropped }));
       }
       processBatch(batch);
     });
 
     return () => {
       if (unsubscribe) unsubscribe();
+      if (unsubscribeState) unsubscribeState();
     };
-  }, [processBatch]);
+  }, [processBatch, applyConfig]);
 
   const startStreaming = useCallback(async () => {
     if (!isWailsContext()) {
@@ -351,13 +423,16 @@ export function useTelemetry(): [TelemetryState, TelemetryActions] {
     tracesRef.current.clear();
     metricsRef.current.clear();
     logsRef.current.clear();
+    profilesRef.current.clear();
 
     setState(prev => ({
       ...prev,
       traces: [],
       metrics: [],
       logs: [],
+      profiles: [],
       stats: INITIAL_STATS,
+      droppedEvents: 0,
     }));
 
     if (isWailsContext()) {
@@ -369,11 +444,22 @@ export function useTelemetry(): [TelemetryState, TelemetryActions] {
     }
   }, []);
 
+  const setConfig = useCallback(async (config: RuntimeConfig) => {
+    if (!isWailsContext()) {
+      applyConfig(config);
+      return;
+    }
+    // Errors propagate so the caller can show why the settings were refused
+    await getApp().SetConfig(config);
+    applyConfig(await getApp().GetConfig());
+  }, [applyConfig]);
+
   return [state, {
     startStreaming,
     stopStreaming,
     toggleStreaming,
     refresh,
     clearAll,
+    setConfig,
   }];
 }
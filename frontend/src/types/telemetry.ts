@@ -7,7 +7,7 @@
 // Core Types
 // ============================================================================
 
-export type SignalType = 'trace' | 'metric' | 'log';
+export type SignalType = 'trace' | 'metric' | 'log' | 'profile';
 
 export type SeverityLevel =
   | 'unspecified'
@@ -127,6 +127,7 @@ export interface Span {
 
   // Metadata
   receivedAt: string; // ISO date string
+  source?: string; // ClientInfo.id of the exporting client
 }
 
 // ============================================================================
@@ -180,6 +181,7 @@ export interface Metric {
 
   // Metadata
   receivedAt: string; // ISO date string
+  source?: string; // ClientInfo.id of the exporting client
 }
 
 // ============================================================================
@@ -217,6 +219,82 @@ export interface LogRecord {
 
   // Metadata
   receivedAt: string; // ISO date string
+  source?: string; // ClientInfo.id of the exporting client
+}
+
+// ============================================================================
+// Profile Types
+// ============================================================================
+
+export interface ProfileValueType {
+  type: string;
+  unit: string;
+}
+
+export interface ProfileFunction {
+  name: string;
+  systemName?: string;
+  filename?: string;
+  startLine?: number;
+}
+
+export interface ProfileLine {
+  functionIndex: number; // Index into Profile.functions
+  line?: number;
+  column?: number;
+}
+
+export interface ProfileLocation {
+  address?: string; // Hex instruction address
+  mapping?: string; // Binary or shared library
+  lines?: ProfileLine[];
+}
+
+export interface ProfileSample {
+  locationIndices: number[]; // Stack into Profile.locations, leaf first
+  values: number[]; // Measured in Profile.sampleType
+  attributes?: Attribute[];
+  timestampsUnixNano?: number[];
+
+  // Span the sample was taken in
+  traceId?: string;
+  spanId?: string;
+}
+
+/** A collected profile; mirrors models.Profile */
+export interface Profile {
+  // Identity
+  id: string;
+  profileId?: string;
+
+  // Timing
+  timeUnixNano: number;
+  timestamp: string; // ISO date string
+  durationMs: number;
+
+  // What was sampled
+  sampleType: ProfileValueType;
+  periodType: ProfileValueType;
+  period?: number;
+
+  // Data
+  samples: ProfileSample[];
+  locations: ProfileLocation[];
+  functions: ProfileFunction[];
+
+  originalPayloadFormat?: string;
+
+  // Context
+  resource: Resource;
+  instrumentationScope: InstrumentationScope;
+  attributes?: Attribute[];
+
+  // Counts
+  droppedAttributesCount?: number;
+
+  // Metadata
+  receivedAt: string; // ISO date string
+  source?: string; // ClientInfo.id of the exporting client
 }
 
 // ============================================================================
@@ -227,18 +305,160 @@ export interface TelemetryStats {
   traceCount: number;
   metricCount: number;
   logCount: number;
+  profileCount: number;
   traceCapacity: number;
   metricCapacity: number;
   logCapacity: number;
+  profileCapacity: number;
   traceUsage: number;
   metricUsage: number;
   logUsage: number;
+  profileUsage: number;
+}
+
+/** Mirrors receiver.ReceiverStats in internal/receiver/grpc.go */
+export interface ReceiverStats {
+  tracesReceived: number;
+  metricsReceived: number;
+  logsReceived: number;
+  profilesReceived: number;
+  errors: number;
+  authFailures: number;
+  tracesRejected: number;
+  metricsRejected: number;
+  logsRejected: number;
+  profilesRejected: number;
+  throttled: number;
+  faultsInjected: number;
+  inFlightItems: number;
+  subscribers: SubscriberStats[];
+}
+
+/** Mirrors eventbus.SubscriberStats in pkg/eventbus/bus.go */
+export interface SubscriberStats {
+  name: string;
+  delivered: number;
+  dropped: number;
+  pending: number;
+  capacity: number;
+  lagMs: number;
+}
+
+/** A client connection that exported telemetry; mirrors models.ClientInfo */
+export interface ClientInfo {
+  id: string;
+  transport: string;
+  peerAddress: string;
+  userAgent?: string;
+  serviceNames: string[];
+  connected: boolean;
+  firstSeen: string; // ISO date string
+  lastSeen: string; // ISO date string
+  exports: number;
+  bytes: number;
+  spans: number;
+  metrics: number;
+  logs: number;
+  profiles: number;
+}
+
+/** Health of a Prometheus scrape target; mirrors receiver.ScrapeTargetStats */
+export interface ScrapeTargetStats {
+  url: string;
+  job: string;
+  health: 'up' | 'down' | 'unknown';
+  lastScrape: string; // ISO date string
+  lastDurationMs: number;
+  lastError?: string;
+  lastSamples: number;
+  scrapes: number;
+  failures: number;
+  intervalMs: number;
+}
+
+/** Fault injection settings for the Export handlers; mirrors receiver.FaultConfig */
+export interface FaultConfig {
+  latencyMs: number;
+  latencyJitterMs: number;
+  unavailablePercent: number;
+  resourceExhaustedPercent: number;
+  resetPercent: number;
+  rejectPercent: number;
+  retryAfterMs: number;
+}
+
+/** Receiver settings that can change at runtime; mirrors receiver.RuntimeConfig */
+export interface RuntimeConfig {
+  port: number;
+  httpPort: number;
+  httpEnabled: boolean;
+  statsdAddress: string; // Empty disables the protocol, as for the addresses below
+  syslogTcpAddress: string;
+  syslogUdpAddress: string;
+  forwardAddress: string;
+  traceCapacity: number;
+  metricCapacity: number;
+  logCapacity: number;
+  profileCapacity: number;
+}
+
+/** Frontend defaults from the config file; mirrors config.UIConfig */
+export interface UIConfig {
+  startStreaming: boolean;
+  maxEventRate: number;
+  defaultTab: TabType;
+}
+
+/** Lifecycle state of the receiver; mirrors receiver.ReceiverStatus */
+export interface ReceiverStatus {
+  state: 'starting' | 'listening' | 'degraded' | 'failed' | 'stopped';
+  reason?: string;
+  grpcAddresses: string[] | null;
+  httpAddresses: string[] | null;
+  since: string; // ISO date string
+}
+
+/** Health of an upstream forwarding endpoint; mirrors receiver.UpstreamStats */
+export interface UpstreamStats {
+  endpoint: string;
+  protocol: 'grpc' | 'http/protobuf';
+  health: 'up' | 'down' | 'unknown';
+  queued: number;
+  queueCapacity: number;
+  forwarded: number;
+  retries: number;
+  failed: number;
+  dropped: number;
+  lastSuccess: string; // ISO date string
+  lastLatencyMs: number;
+  lastError?: string;
+}
+
+export interface Rejection {
+  signal: SignalType;
+  serviceName: string;
+  reason: string;
+  timestamp: string; // ISO date string
 }
 
 export interface TelemetryBatch {
   spans?: Span[];
   metrics?: Metric[];
   logs?: LogRecord[];
+  profiles?: Profile[];
+}
+
+/** A coalesced group of real-time events; mirrors models.EventBatch */
+export interface EventBatch extends TelemetryBatch {
+  dropped: number;
+}
+
+/** Mirrors bridge.DeliveryStats in internal/bridge/batcher.go */
+export interface DeliveryStats {
+  batchesEmitted: number;
+  eventsEmitted: number;
+  eventsDropped: number;
+  maxEventsPerSecond: number;
 }
 
 export interface TelemetryEvent {
@@ -246,6 +466,7 @@ export interface TelemetryEvent {
   span?: Span;
   metric?: Metric;
   log?: LogRecord;
+  profile?: Profile;
   timestamp: string; // ISO date string
 }
 
@@ -7,8 +7,19 @@ import type {
   Span,
   Metric,
   LogRecord,
+  Profile,
   TelemetryStats,
   TelemetryBatch,
+  ReceiverStats,
+  ReceiverStatus,
+  Rejection,
+  DeliveryStats,
+  ClientInfo,
+  ScrapeTargetStats,
+  UpstreamStats,
+  FaultConfig,
+  RuntimeConfig,
+  UIConfig,
 } from './telemetry';
 
 // ============================================================================
@@ -32,14 +43,32 @@ export interface AppBindings {
   GetLogs(): Promise<LogRecord[]>;
   GetRecentLogs(count: number): Promise<LogRecord[]>;
 
+  // Profile methods
+  GetProfiles(): Promise<Profile[]>;
+  GetRecentProfiles(count: number): Promise<Profile[]>;
+  GetProfilesForSpan(traceId: string, spanId: string): Promise<Profile[]>;
+
   // Stats methods
   GetStats(): Promise<TelemetryStats>;
+  GetReceiverStatus(): Promise<ReceiverStatus>;
+  GetReceiverStats(): Promise<ReceiverStats>;
+  GetRejections(): Promise<Rejection[]>;
+  GetClients(): Promise<ClientInfo[]>;
+  GetScrapeTargets(): Promise<ScrapeTargetStats[]>;
+  GetUpstreams(): Promise<UpstreamStats[]>;
 
   // Control methods
   StartStreaming(): Promise<void>;
   StopStreaming(): Promise<void>;
   IsStreaming(): Promise<boolean>;
   ClearAll(): Promise<void>;
+  SetMaxEventRate(eventsPerSecond: number): Promise<void>;
+  GetDeliveryStats(): Promise<DeliveryStats>;
+  GetFaults(): Promise<FaultConfig>;
+  SetFaults(config: FaultConfig): Promise<void>;
+  GetConfig(): Promise<RuntimeConfig>;
+  SetConfig(config: RuntimeConfig): Promise<void>;
+  GetUIConfig(): Promise<UIConfig>;
 
   // Batch methods
   GetAllTelemetry(): Promise<TelemetryBatch>;
@@ -59,7 +88,7 @@ export interface WailsRuntime {
 // Event Types
 // ============================================================================
 
-export type TelemetryEventName = 'telemetry:event' | 'telemetry:cleared';
+export type TelemetryEventName = 'telemetry:batch' | 'telemetry:cleared' | 'receiver:state';
 
 // ============================================================================
 // Window Extensions
@@ -3,9 +3,13 @@ package bridge
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
+	"time"
 
+	"github.com/phosphor-project/phosphor/internal/config"
 	"github.com/phosphor-project/phosphor/internal/receiver"
 	"github.com/phosphor-project/phosphor/pkg/models"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -17,14 +21,29 @@ type App struct {
 	ctx      context.Context
 	receiver *receiver.OTLPReceiver
 
+	// Loaded settings and the file that UI changes are saved to
+	config     config.Config
+	configPath string
+	configMu   sync.Mutex
+
+	// Detaches the bridge from the receiver's event bus
+	unsubscribe func()
+
+	// Detaches the bridge from receiver lifecycle notifications
+	unsubscribeState func()
+
+	// Coalesces events into telemetry:batch emissions
+	batcher *eventBatcher
+
 	// Streaming control
 	streaming   bool
 	streamingMu sync.RWMutex
 }
 
-// NewApp creates a new App instance.
-func NewApp() *App {
-	return &App{}
+// NewApp creates a new App instance with the given settings. Settings
+// changed from the UI are saved to configPath; an empty path disables saving.
+func NewApp(settings config.Config, configPath string) *App {
+	return &App{config: settings, configPath: configPath}
 }
 
 // Startup is called when the Wails application starts.
@@ -32,22 +51,38 @@ func NewApp() *App {
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 
-	// Initialize the receiver with default configuration
-	config := receiver.DefaultConfig()
-	a.receiver = receiver.NewOTLPReceiver(config)
+	// Initialize the receiver from the loaded settings
+	a.configMu.Lock()
+	settings := a.config
+	a.configMu.Unlock()
+	a.receiver = receiver.NewOTLPReceiver(settings.ReceiverConfig())
+	a.streaming = settings.UI.StartStreaming
+
+	// Batch events so bursts don't flood the webview with one emit per item
+	batchConfig := DefaultBatchConfig()
+	batchConfig.MaxEventsPerSecond = settings.UI.MaxEventRate
+	a.batcher = newEventBatcher(batchConfig, func(batch models.EventBatch) {
+		start := time.Now()
+		runtime.EventsEmit(a.ctx, "telemetry:batch", batch)
+		a.receiver.ObserveEmit(time.Since(start))
+	})
 
 	// Register event callback for real-time streaming
-	a.receiver.OnEvent(func(event models.TelemetryEvent) {
+	a.unsubscribe = a.receiver.OnEvent("wails-bridge", func(event models.TelemetryEvent) {
 		a.streamingMu.RLock()
 		streaming := a.streaming
 		a.streamingMu.RUnlock()
 
 		if streaming {
-			// Emit event to frontend via Wails runtime
-			runtime.EventsEmit(a.ctx, "telemetry:event", event)
+			a.batcher.add(event)
 		}
 	})
 
+	// Forward lifecycle changes so the UI can show degraded or failed listeners
+	a.unsubscribeState = a.receiver.OnStateChange("wails-bridge", func(status receiver.ReceiverStatus) {
+		runtime.EventsEmit(a.ctx, "receiver:state", status)
+	})
+
 	// Start the OTLP receiver
 	if err := a.receiver.Start(); err != nil {
 		log.Printf("[Phosphor] Failed to start receiver: %v", err)
@@ -59,6 +94,15 @@ func (a *App) Startup(ctx context.Context) {
 
 // Shutdown is called when the Wails application is closing.
 func (a *App) Shutdown(ctx context.Context) {
+	if a.unsubscribe != nil {
+		a.unsubscribe()
+	}
+	if a.unsubscribeState != nil {
+		a.unsubscribeState()
+	}
+	if a.batcher != nil {
+		a.batcher.close()
+	}
 	if a.receiver != nil {
 		a.receiver.Stop()
 	}
@@ -119,6 +163,33 @@ func (a *App) GetRecentLogs(count int) []models.LogRecord {
 	return a.receiver.GetRecentLogs(count)
 }
 
+// --- Profile Methods ---
+
+// GetProfiles returns all stored profiles (up to buffer capacity).
+func (a *App) GetProfiles() []models.Profile {
+	if a.receiver == nil {
+		return []models.Profile{}
+	}
+	return a.receiver.GetProfiles()
+}
+
+// GetRecentProfiles returns the last n profiles.
+func (a *App) GetRecentProfiles(count int) []models.Profile {
+	if a.receiver == nil {
+		return []models.Profile{}
+	}
+	return a.receiver.GetRecentProfiles(count)
+}
+
+// GetProfilesForSpan returns the profile samples taken while the given span
+// was active, e.g. to show where a slow span spent its CPU time.
+func (a *App) GetProfilesForSpan(traceID, spanID string) []models.Profile {
+	if a.receiver == nil {
+		return []models.Profile{}
+	}
+	return a.receiver.GetProfilesForSpan(traceID, spanID)
+}
+
 // --- Stats Methods ---
 
 // GetStats returns current telemetry statistics.
@@ -129,6 +200,54 @@ func (a *App) GetStats() models.TelemetryStats {
 	return a.receiver.GetStats()
 }
 
+// GetReceiverStatus returns the receiver's lifecycle state and listen addresses.
+func (a *App) GetReceiverStatus() receiver.ReceiverStatus {
+	if a.receiver == nil {
+		return receiver.ReceiverStatus{State: receiver.StateStopped}
+	}
+	return a.receiver.GetStatus()
+}
+
+// GetReceiverStats returns ingestion counters such as rejected exports.
+func (a *App) GetReceiverStats() receiver.ReceiverStats {
+	if a.receiver == nil {
+		return receiver.ReceiverStats{}
+	}
+	return a.receiver.GetReceiverStats()
+}
+
+// GetClients returns the client connections that have exported telemetry.
+func (a *App) GetClients() []models.ClientInfo {
+	if a.receiver == nil {
+		return []models.ClientInfo{}
+	}
+	return a.receiver.GetClients()
+}
+
+// GetRejections returns recent items refused during validation and why.
+func (a *App) GetRejections() []models.Rejection {
+	if a.receiver == nil {
+		return []models.Rejection{}
+	}
+	return a.receiver.GetRejections()
+}
+
+// GetScrapeTargets returns the health of each Prometheus scrape target.
+func (a *App) GetScrapeTargets() []receiver.ScrapeTargetStats {
+	if a.receiver == nil {
+		return []receiver.ScrapeTargetStats{}
+	}
+	return a.receiver.GetScrapeTargets()
+}
+
+// GetUpstreams returns the health of each upstream forwarding endpoint.
+func (a *App) GetUpstreams() []receiver.UpstreamStats {
+	if a.receiver == nil {
+		return []receiver.UpstreamStats{}
+	}
+	return a.receiver.GetUpstreams()
+}
+
 // --- Control Methods ---
 
 // StartStreaming enables real-time event streaming to the frontend.
@@ -154,11 +273,101 @@ func (a *App) IsStreaming() bool {
 	return a.streaming
 }
 
+// SetMaxEventRate caps how many events per second are streamed to the
+// frontend; excess events are dropped and reported in each batch. 0 disables the cap.
+func (a *App) SetMaxEventRate(eventsPerSecond int) {
+	if a.batcher != nil {
+		a.batcher.setMaxEventsPerSecond(eventsPerSecond)
+	}
+	if err := a.saveConfig(func(c *config.Config) { c.UI.MaxEventRate = eventsPerSecond }); err != nil {
+		log.Printf("[Phosphor] %v", err)
+	}
+}
+
+// GetDeliveryStats returns counters for events streamed to the frontend.
+func (a *App) GetDeliveryStats() DeliveryStats {
+	if a.batcher == nil {
+		return DeliveryStats{}
+	}
+	return a.batcher.getStats()
+}
+
+// GetFaults returns the receiver's active fault injection settings.
+func (a *App) GetFaults() receiver.FaultConfig {
+	if a.receiver == nil {
+		return receiver.FaultConfig{}
+	}
+	return a.receiver.GetFaults()
+}
+
+// SetFaults changes fault injection at runtime; the zero config disables it.
+func (a *App) SetFaults(faults receiver.FaultConfig) error {
+	if a.receiver == nil {
+		return errors.New("receiver not running")
+	}
+	if err := a.receiver.SetFaults(faults); err != nil {
+		return err
+	}
+	return a.saveConfig(func(c *config.Config) { c.Receiver.Faults = faults })
+}
+
+// GetConfig returns the receiver settings that can change while running.
+func (a *App) GetConfig() receiver.RuntimeConfig {
+	if a.receiver == nil {
+		return receiver.RuntimeConfig{}
+	}
+	return a.receiver.GetRuntimeConfig()
+}
+
+// SetConfig changes ports, enabled protocols and buffer capacities at
+// runtime. Listeners restart if needed; stored telemetry is kept.
+func (a *App) SetConfig(settings receiver.RuntimeConfig) error {
+	if a.receiver == nil {
+		return errors.New("receiver not running")
+	}
+	a.configMu.Lock()
+	loaded := a.config.Runtime()
+	a.configMu.Unlock()
+
+	if err := a.receiver.Reconfigure(settings); err != nil {
+		return err
+	}
+	// Save only what the UI changed, so environment and flag overrides in
+	// the loaded settings never end up in the file.
+	return a.saveConfig(func(c *config.Config) { c.SetRuntimeChanges(loaded, settings) })
+}
+
+// GetUIConfig returns the frontend defaults from the loaded settings.
+func (a *App) GetUIConfig() config.UIConfig {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	return a.config.UI
+}
+
+// saveConfig applies a change made from the UI to the loaded settings and
+// persists it to the config file.
+func (a *App) saveConfig(change func(*config.Config)) error {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+
+	change(&a.config)
+	if a.configPath == "" {
+		return nil
+	}
+	if err := config.Update(a.configPath, change); err != nil {
+		return fmt.Errorf("settings applied but not saved: %w", err)
+	}
+	return nil
+}
+
 // ClearAll clears all stored telemetry data.
 func (a *App) ClearAll() {
 	if a.receiver != nil {
 		a.receiver.ClearAll()
 	}
+	if a.batcher != nil {
+		a.batcher.reset()
+	}
 	// Notify frontend to clear its state
 	runtime.EventsEmit(a.ctx, "telemetry:cleared", nil)
 }
@@ -170,8 +379,9 @@ func (a *App) GetAllTelemetry() models.TelemetryBatch {
 		return models.TelemetryBatch{}
 	}
 	return models.TelemetryBatch{
-		Spans:   a.receiver.GetTraces(),
-		Metrics: a.receiver.GetMetrics(),
-		Logs:    a.receiver.GetLogs(),
+		Spans:    a.receiver.GetTraces(),
+		Metrics:  a.receiver.GetMetrics(),
+		Logs:     a.receiver.GetLogs(),
+		Profiles: a.receiver.GetProfiles(),
 	}
 }
@@ -0,0 +1,45 @@
+package bridge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phosphor-project/phosphor/internal/config"
+	"github.com/phosphor-project/phosphor/internal/receiver"
+)
+
+func TestSetConfigSavesOnlyChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("receiver:\n  grpc_port: 5317\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PHOSPHOR_GRPC_PORT", "6317")
+	t.Setenv("PHOSPHOR_LOG_CAPACITY", "70")
+
+	settings, _, err := config.Load([]string{"-config", path})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	a := NewApp(settings, path)
+	a.receiver = receiver.NewOTLPReceiver(settings.ReceiverConfig())
+
+	runtime := a.GetConfig()
+	runtime.TraceCapacity = 20
+	if err := a.SetConfig(runtime); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+
+	os.Unsetenv("PHOSPHOR_GRPC_PORT")
+	os.Unsetenv("PHOSPHOR_LOG_CAPACITY")
+	saved, _, err := config.Load([]string{"-config", path})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.Retention.Traces != 20 {
+		t.Errorf("Traces = %d, want the UI change saved", saved.Retention.Traces)
+	}
+	if saved.Receiver.GRPCPort != 5317 || saved.Retention.Logs != 1000 {
+		t.Errorf("GRPCPort, Logs = %d, %d, want 5317, 1000 from the file, not the environment", saved.Receiver.GRPCPort, saved.Retention.Logs)
+	}
+}
@@ -0,0 +1,192 @@
+package bridge
+
+import (
+	"sync"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+// BatchConfig controls how real-time events are coalesced before being sent
+// to the webview.
+type BatchConfig struct {
+	FlushInterval      time.Duration // Maximum time an event waits before emission (default: 100ms)
+	MaxBatchSize       int           // Flush early once this many events are pending (default: 500)
+	MaxEventsPerSecond int           // Events beyond this rate are dropped and summarized (0 = unlimited)
+}
+
+// DefaultBatchConfig returns a BatchConfig tuned to keep the webview responsive.
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		FlushInterval:      100 * time.Millisecond,
+		MaxBatchSize:       500,
+		MaxEventsPerSecond: 5000,
+	}
+}
+
+// DeliveryStats reports how events have been delivered to the frontend.
+type DeliveryStats struct {
+	BatchesEmitted     uint64 `json:"batchesEmitted"`
+	EventsEmitted      uint64 `json:"eventsEmitted"`
+	EventsDropped      uint64 `json:"eventsDropped"`
+	MaxEventsPerSecond int    `json:"maxEventsPerSecond"`
+}
+
+// eventBatcher accumulates telemetry events and hands them to emit as
+// size- or time-bounded batches, enforcing an optional events-per-second cap.
+type eventBatcher struct {
+	emit   func(models.EventBatch)
+	emitMu sync.Mutex // Serializes flushes so batches are emitted in order
+
+	mu          sync.Mutex
+	config      BatchConfig
+	pending     models.EventBatch
+	pendingSize int
+	windowStart time.Time
+	windowCount int
+	stats       DeliveryStats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newEventBatcher creates a batcher and starts its flush loop.
+func newEventBatcher(config BatchConfig, emit func(models.EventBatch)) *eventBatcher {
+	defaults := DefaultBatchConfig()
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaults.MaxBatchSize
+	}
+
+	b := &eventBatcher{
+		emit:        emit,
+		config:      config,
+		windowStart: time.Now(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	b.stats.MaxEventsPerSecond = config.MaxEventsPerSecond
+
+	go b.run()
+	return b
+}
+
+// add queues an event for the next batch, or counts it as dropped when the
+// configured rate has been exceeded in the current one-second window.
+func (b *eventBatcher) add(event models.TelemetryEvent) {
+	if b.enqueue(event) {
+		b.flushPending()
+	}
+}
+
+// enqueue appends event to the pending batch and reports whether the batch is full.
+func (b *eventBatcher) enqueue(event models.TelemetryEvent) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(b.windowStart) >= time.Second {
+		b.windowStart = now
+		b.windowCount = 0
+	}
+	if b.config.MaxEventsPerSecond > 0 && b.windowCount >= b.config.MaxEventsPerSecond {
+		b.pending.Dropped++
+		b.stats.EventsDropped++
+		return false
+	}
+	b.windowCount++
+
+	switch event.Type {
+	case models.SignalTypeTrace:
+		if event.Span != nil {
+			b.pending.Spans = append(b.pending.Spans, *event.Span)
+		}
+	case models.SignalTypeMetric:
+		if event.Metric != nil {
+			b.pending.Metrics = append(b.pending.Metrics, *event.Metric)
+		}
+	case models.SignalTypeLog:
+		if event.Log != nil {
+			b.pending.Logs = append(b.pending.Logs, *event.Log)
+		}
+	case models.SignalTypeProfile:
+		if event.Profile != nil {
+			b.pending.Profiles = append(b.pending.Profiles, *event.Profile)
+		}
+	}
+	b.pendingSize++
+
+	return b.pendingSize >= b.config.MaxBatchSize
+}
+
+// run flushes pending events on every tick.
+func (b *eventBatcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			b.flushPending()
+			return
+		case <-ticker.C:
+			b.flushPending()
+		}
+	}
+}
+
+// flushPending emits the pending batch if it carries events or a drop summary.
+func (b *eventBatcher) flushPending() {
+	b.emitMu.Lock()
+	defer b.emitMu.Unlock()
+
+	b.mu.Lock()
+	if b.pendingSize == 0 && b.pending.Dropped == 0 {
+		b.mu.Unlock()
+		return
+	}
+	batch := b.pending
+	b.stats.BatchesEmitted++
+	b.stats.EventsEmitted += uint64(b.pendingSize)
+	b.pending = models.EventBatch{}
+	b.pendingSize = 0
+	b.mu.Unlock()
+
+	b.emit(batch)
+}
+
+// setMaxEventsPerSecond changes the rate cap; 0 disables it.
+func (b *eventBatcher) setMaxEventsPerSecond(n int) {
+	if n < 0 {
+		n = 0
+	}
+	b.mu.Lock()
+	b.config.MaxEventsPerSecond = n
+	b.stats.MaxEventsPerSecond = n
+	b.mu.Unlock()
+}
+
+// reset discards pending events, e.g. after the user clears all data.
+func (b *eventBatcher) reset() {
+	b.mu.Lock()
+	b.pending = models.EventBatch{}
+	b.pendingSize = 0
+	b.mu.Unlock()
+}
+
+// getStats returns a snapshot of delivery counters.
+func (b *eventBatcher) getStats() DeliveryStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// close flushes remaining events and stops the flush loop.
+func (b *eventBatcher) close() {
+	close(b.stop)
+	<-b.done
+}
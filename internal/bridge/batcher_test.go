@@ -0,0 +1,73 @@
+package bridge
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+func spanEvent() models.TelemetryEvent {
+	return models.TelemetryEvent{Type: models.SignalTypeTrace, Span: &models.Span{Name: "op"}}
+}
+
+func TestBatcherCoalescesEvents(t *testing.T) {
+	var mu sync.Mutex
+	var batches []models.EventBatch
+	b := newEventBatcher(BatchConfig{FlushInterval: time.Hour, MaxBatchSize: 10}, func(batch models.EventBatch) {
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+	})
+
+	for i := 0; i < 25; i++ {
+		b.add(spanEvent())
+	}
+	b.close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	total := 0
+	for _, batch := range batches {
+		if len(batch.Spans) > 10 {
+			t.Errorf("batch of %d spans exceeds MaxBatchSize", len(batch.Spans))
+		}
+		total += len(batch.Spans)
+	}
+	if total != 25 {
+		t.Errorf("emitted %d spans, want 25", total)
+	}
+	if len(batches) != 3 {
+		t.Errorf("emitted %d batches, want 3", len(batches))
+	}
+}
+
+func TestBatcherRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var got models.EventBatch
+	b := newEventBatcher(BatchConfig{FlushInterval: time.Hour, MaxBatchSize: 1000, MaxEventsPerSecond: 5}, func(batch models.EventBatch) {
+		mu.Lock()
+		got.Spans = append(got.Spans, batch.Spans...)
+		got.Dropped += batch.Dropped
+		mu.Unlock()
+	})
+
+	for i := 0; i < 12; i++ {
+		b.add(spanEvent())
+	}
+	b.close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got.Spans) != 5 {
+		t.Errorf("emitted %d spans, want 5", len(got.Spans))
+	}
+	if got.Dropped != 7 {
+		t.Errorf("Dropped = %d, want 7", got.Dropped)
+	}
+	if stats := b.getStats(); stats.EventsDropped != 7 || stats.EventsEmitted != 5 {
+		t.Errorf("stats = %+v, want 5 emitted and 7 dropped", stats)
+	}
+}
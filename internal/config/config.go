@@ -0,0 +1,344 @@
+// Package config loads Phosphor's settings. Built-in defaults are overridden
+// by a YAML file in the user config dir, then by PHOSPHOR_* environment
+// variables, then by command-line flags.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/phosphor-project/phosphor/internal/receiver"
+	"gopkg.in/yaml.v3"
+)
+
+// pathEnv overrides the config file location, as does the -config flag.
+const pathEnv = "PHOSPHOR_CONFIG"
+
+// Config holds every Phosphor setting, laid out as in the config file.
+type Config struct {
+	Receiver   ReceiverConfig          `yaml:"receiver"`   // Listeners and ingestion
+	Retention  RetentionConfig         `yaml:"retention"`  // How much telemetry is kept in memory
+	Forwarding receiver.UpstreamConfig `yaml:"forwarding"` // OTLP endpoints that receive a copy of every export
+	UI         UIConfig                `yaml:"ui"`         // Desktop app defaults
+}
+
+// ReceiverConfig holds the receiver.Config settings other than buffer
+// capacities and forwarding, which have their own sections.
+type ReceiverConfig struct {
+	GRPCPort            int           `yaml:"grpc_port"`             // OTLP/gRPC port (default: 4317)
+	HTTPPort            int           `yaml:"http_port"`             // OTLP/HTTP port (default: 4318)
+	HTTPEnabled         bool          `yaml:"http_enabled"`          // Serve OTLP/HTTP, Zipkin, Jaeger Thrift and remote write
+	ListenAddresses     []string      `yaml:"listen_addresses"`      // gRPC addresses used instead of grpc_port
+	HTTPListenAddresses []string      `yaml:"http_listen_addresses"` // HTTP addresses used instead of http_port
+	PortFallback        bool          `yaml:"port_fallback"`         // Listen on a free port when a port is taken
+	DrainTimeout        time.Duration `yaml:"drain_timeout"`         // How long shutdown waits for in-flight exports
+	EventQueueSize      int           `yaml:"event_queue_size"`      // Per-subscriber event queue length
+	QueryAddress        string        `yaml:"query_address"`         // Serve stored telemetry as JSON on this address
+	QueryAllowClear     bool          `yaml:"query_allow_clear"`     // Let DELETE /api/telemetry clear every buffer
+
+	TLS           receiver.TLSConfig           `yaml:"tls"`
+	Auth          receiver.AuthConfig          `yaml:"auth"`
+	Admission     receiver.AdmissionConfig     `yaml:"admission"`
+	Scrape        receiver.ScrapeConfig        `yaml:"scrape"`
+	StatsD        receiver.StatsDConfig        `yaml:"statsd"`
+	Syslog        receiver.SyslogConfig        `yaml:"syslog"`
+	FluentForward receiver.ForwardConfig       `yaml:"fluent_forward"`
+	Tail          receiver.TailConfig          `yaml:"tail"`
+	Faults        receiver.FaultConfig         `yaml:"faults"`
+	SelfTelemetry receiver.SelfTelemetryConfig `yaml:"self_telemetry"`
+}
+
+// RetentionConfig sets how many items of each signal are kept in memory.
+type RetentionConfig struct {
+	Traces   int `yaml:"traces"`   // Spans (default: 1000)
+	Metrics  int `yaml:"metrics"`  // Metrics (default: 1000)
+	Logs     int `yaml:"logs"`     // Log records (default: 1000)
+	Profiles int `yaml:"profiles"` // Profiles (default: 100)
+}
+
+// UIConfig holds defaults for the desktop frontend.
+type UIConfig struct {
+	StartStreaming bool   `json:"startStreaming" yaml:"start_streaming"` // Stream events as soon as the window opens
+	MaxEventRate   int    `json:"maxEventRate" yaml:"max_event_rate"`    // Events per second streamed to the frontend (0 = unlimited)
+	DefaultTab     string `json:"defaultTab" yaml:"default_tab"`         // "traces", "metrics" or "logs"
+}
+
+// Default returns the built-in settings, matching receiver.DefaultConfig.
+func Default() Config {
+	r := receiver.DefaultConfig()
+	return Config{
+		Receiver: ReceiverConfig{
+			GRPCPort:       r.Port,
+			HTTPPort:       r.HTTPPort,
+			HTTPEnabled:    !r.DisableHTTP,
+			PortFallback:   r.PortFallback,
+			DrainTimeout:   r.DrainTimeout,
+			EventQueueSize: r.EventQueueSize,
+		},
+		Retention: RetentionConfig{
+			Traces:   r.TraceCapacity,
+			Metrics:  r.MetricCapacity,
+			Logs:     r.LogCapacity,
+			Profiles: r.ProfileCapacity,
+		},
+		UI: UIConfig{
+			MaxEventRate: 5000,
+			DefaultTab:   "traces",
+		},
+	}
+}
+
+// ReceiverConfig returns the settings as a receiver.Config.
+func (c Config) ReceiverConfig() receiver.Config {
+	r := receiver.DefaultConfig()
+	r.Port = c.Receiver.GRPCPort
+	r.HTTPPort = c.Receiver.HTTPPort
+	r.DisableHTTP = !c.Receiver.HTTPEnabled
+	r.ListenAddresses = c.Receiver.ListenAddresses
+	r.HTTPListenAddresses = c.Receiver.HTTPListenAddresses
+	r.PortFallback = c.Receiver.PortFallback
+	r.DrainTimeout = c.Receiver.DrainTimeout
+	r.EventQueueSize = c.Receiver.EventQueueSize
+	r.QueryAddress = c.Receiver.QueryAddress
+	r.QueryAllowClear = c.Receiver.QueryAllowClear
+	r.TLS = c.Receiver.TLS
+	r.Auth = c.Receiver.Auth
+	r.Admission = c.Receiver.Admission
+	r.Scrape = c.Receiver.Scrape
+	r.StatsD = c.Receiver.StatsD
+	r.Syslog = c.Receiver.Syslog
+	r.Forward = c.Receiver.FluentForward
+	r.Tail = c.Receiver.Tail
+	r.Faults = c.Receiver.Faults
+	r.SelfTelemetry = c.Receiver.SelfTelemetry
+	r.TraceCapacity = c.Retention.Traces
+	r.MetricCapacity = c.Retention.Metrics
+	r.LogCapacity = c.Retention.Logs
+	r.ProfileCapacity = c.Retention.Profiles
+	r.Upstream = c.Forwarding
+	return r
+}
+
+// SetRuntime stores settings changed through receiver.Reconfigure.
+func (c *Config) SetRuntime(r receiver.RuntimeConfig) {
+	c.Receiver.GRPCPort = r.Port
+	c.Receiver.HTTPPort = r.HTTPPort
+	c.Receiver.HTTPEnabled = r.HTTPEnabled
+	c.Receiver.StatsD.ListenAddress = r.StatsDAddress
+	c.Receiver.Syslog.TCPAddress = r.SyslogTCPAddress
+	c.Receiver.Syslog.UDPAddress = r.SyslogUDPAddress
+	c.Receiver.FluentForward.ListenAddress = r.ForwardAddress
+	c.Retention.Traces = r.TraceCapacity
+	c.Retention.Metrics = r.MetricCapacity
+	c.Retention.Logs = r.LogCapacity
+	c.Retention.Profiles = r.ProfileCapacity
+}
+
+// Runtime returns the settings that receiver.Reconfigure can change.
+func (c Config) Runtime() receiver.RuntimeConfig {
+	return receiver.RuntimeConfig{
+		Port:             c.Receiver.GRPCPort,
+		HTTPPort:         c.Receiver.HTTPPort,
+		HTTPEnabled:      c.Receiver.HTTPEnabled,
+		StatsDAddress:    c.Receiver.StatsD.ListenAddress,
+		SyslogTCPAddress: c.Receiver.Syslog.TCPAddress,
+		SyslogUDPAddress: c.Receiver.Syslog.UDPAddress,
+		ForwardAddress:   c.Receiver.FluentForward.ListenAddress,
+		TraceCapacity:    c.Retention.Traces,
+		MetricCapacity:   c.Retention.Metrics,
+		LogCapacity:      c.Retention.Logs,
+		ProfileCapacity:  c.Retention.Profiles,
+	}
+}
+
+// SetRuntimeChanges stores only the settings that differ between from and
+// to. Saving a UI change this way keeps values that the loaded settings took
+// from the environment or flags out of the config file.
+func (c *Config) SetRuntimeChanges(from, to receiver.RuntimeConfig) {
+	setChanged(&c.Receiver.GRPCPort, from.Port, to.Port)
+	setChanged(&c.Receiver.HTTPPort, from.HTTPPort, to.HTTPPort)
+	setChanged(&c.Receiver.HTTPEnabled, from.HTTPEnabled, to.HTTPEnabled)
+	setChanged(&c.Receiver.StatsD.ListenAddress, from.StatsDAddress, to.StatsDAddress)
+	setChanged(&c.Receiver.Syslog.TCPAddress, from.SyslogTCPAddress, to.SyslogTCPAddress)
+	setChanged(&c.Receiver.Syslog.UDPAddress, from.SyslogUDPAddress, to.SyslogUDPAddress)
+	setChanged(&c.Receiver.FluentForward.ListenAddress, from.ForwardAddress, to.ForwardAddress)
+	setChanged(&c.Retention.Traces, from.TraceCapacity, to.TraceCapacity)
+	setChanged(&c.Retention.Metrics, from.MetricCapacity, to.MetricCapacity)
+	setChanged(&c.Retention.Logs, from.LogCapacity, to.LogCapacity)
+	setChanged(&c.Retention.Profiles, from.ProfileCapacity, to.ProfileCapacity)
+}
+
+// setChanged sets *field to to when it differs from from.
+func setChanged[T comparable](field *T, from, to T) {
+	if from != to {
+		*field = to
+	}
+}
+
+// Validate reports the first invalid setting.
+func (c Config) Validate() error {
+	if err := c.ReceiverConfig().Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if c.Receiver.EventQueueSize < 0 {
+		return fmt.Errorf("invalid configuration: receiver.event_queue_size %d is negative", c.Receiver.EventQueueSize)
+	}
+	if c.UI.MaxEventRate < 0 {
+		return fmt.Errorf("invalid configuration: ui.max_event_rate %d is negative", c.UI.MaxEventRate)
+	}
+	switch c.UI.DefaultTab {
+	case "traces", "metrics", "logs":
+	default:
+		return fmt.Errorf("invalid configuration: ui.default_tab %q is not traces, metrics or logs", c.UI.DefaultTab)
+	}
+	return nil
+}
+
+// Path returns the default config file location,
+// <user config dir>/phosphor/config.yaml.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config dir: %w", err)
+	}
+	return filepath.Join(dir, "phosphor", "config.yaml"), nil
+}
+
+// Load builds the settings from the defaults, the config file, PHOSPHOR_*
+// environment variables and args, each overriding the one before, and
+// validates them. It also returns the config file path, which is -config or
+// PHOSPHOR_CONFIG when set; the file need not exist. Load returns
+// flag.ErrHelp when args ask for usage.
+func Load(args []string) (Config, string, error) {
+	return LoadFlags(flag.NewFlagSet("phosphor", flag.ContinueOnError), args)
+}
+
+// LoadFlags is like Load but registers the settings on flags, so callers can
+// define flags of their own first.
+func LoadFlags(flags *flag.FlagSet, args []string) (Config, string, error) {
+	return load(flags, args, os.LookupEnv)
+}
+
+func load(flags *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (Config, string, error) {
+	type override struct {
+		setting setting
+		value   string
+	}
+	var overrides []override
+
+	path := flags.String("config", "", "config file (default: <user config dir>/phosphor/config.yaml)")
+	for _, s := range settings {
+		record := func(value string) error {
+			overrides = append(overrides, override{s, value})
+			return nil
+		}
+		if s.boolean {
+			flags.BoolFunc(s.flag, s.usage, record)
+		} else {
+			flags.Func(s.flag, s.usage, record)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, "", err
+	}
+	if flags.NArg() > 0 {
+		return Config{}, "", fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if *path == "" {
+		*path, _ = lookupEnv(pathEnv)
+	}
+	if *path == "" {
+		if p, err := Path(); err == nil {
+			*path = p
+		}
+	}
+
+	config := Default()
+	if err := readFile(*path, &config); err != nil {
+		return Config{}, "", err
+	}
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env()); ok {
+			if err := s.set(&config, value); err != nil {
+				return Config{}, "", fmt.Errorf("%s: %w", s.env(), err)
+			}
+		}
+	}
+	for _, o := range overrides {
+		if err := o.setting.set(&config, o.value); err != nil {
+			return Config{}, "", fmt.Errorf("-%s: %w", o.setting.flag, err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, "", err
+	}
+	return config, *path, nil
+}
+
+// readFile decodes the YAML file at path over config. A missing file leaves
+// config unchanged; unknown keys are errors so typos don't go unnoticed.
+func readFile(path string, config *Config) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Update applies change to the settings stored in the file at path and
+// writes them back. Environment and flag overrides are not written, but
+// comments in the file are lost.
+func Update(path string, change func(*Config)) error {
+	if path == "" {
+		return errors.New("no config file location")
+	}
+
+	config := Default()
+	if err := readFile(path, &config); err != nil {
+		return err
+	}
+	change(&config)
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves half a config.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
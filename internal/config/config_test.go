@@ -0,0 +1,172 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phosphor-project/phosphor/internal/receiver"
+)
+
+// writeFile creates a config file with the given contents in a temp dir.
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testFlags returns a flag set that reports errors instead of exiting.
+func testFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// env returns a lookup function over a fixed set of variables.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	path := writeFile(t, `
+receiver:
+  grpc_port: 5317
+  http_port: 5318
+  drain_timeout: 2s
+  syslog:
+    udp_address: ":5514"
+retention:
+  traces: 50
+  logs: 60
+forwarding:
+  endpoints:
+    - endpoint: collector:4317
+ui:
+  default_tab: logs
+`)
+	vars := map[string]string{
+		"PHOSPHOR_CONFIG":          path,
+		"PHOSPHOR_HTTP_PORT":       "6318",
+		"PHOSPHOR_TLS":             "true",
+		"PHOSPHOR_TLS_SELF_SIGNED": "1",
+		"PHOSPHOR_LOG_CAPACITY":    "70",
+	}
+	args := []string{"-log-capacity", "80", "--start-streaming", "-upstream", "https://otlp.example.com"}
+
+	config, gotPath, err := load(testFlags(), args, env(vars))
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if gotPath != path {
+		t.Errorf("path = %q, want %q", gotPath, path)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"file", config.Receiver.GRPCPort, 5317},
+		{"file duration", config.Receiver.DrainTimeout, 2 * time.Second},
+		{"file nested", config.Receiver.Syslog.UDPAddress, ":5514"},
+		{"file retention", config.Retention.Traces, 50},
+		{"default", config.Retention.Metrics, 1000},
+		{"env over file", config.Receiver.HTTPPort, 6318},
+		{"env bool", config.Receiver.TLS.Enabled, true},
+		{"flag over env", config.Retention.Logs, 80},
+		{"bare bool flag", config.UI.StartStreaming, true},
+		{"flag replaces file list", len(config.Forwarding.Endpoints), 1},
+		{"upstream protocol", config.Forwarding.Endpoints[0].Protocol, receiver.UpstreamHTTP},
+		{"ui", config.UI.DefaultTab, "logs"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	r := config.ReceiverConfig()
+	if r.Port != 5317 || r.LogCapacity != 80 || r.Upstream.Endpoints[0].Endpoint != "https://otlp.example.com" {
+		t.Errorf("ReceiverConfig() = %+v, want the merged settings", r)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		vars    map[string]string
+		args    []string
+		wantErr string
+	}{
+		{"unknown key", "receiver:\n  grpcport: 1\n", nil, nil, "field grpcport not found"},
+		{"bad yaml type", "retention:\n  traces: lots\n", nil, nil, "cannot unmarshal"},
+		{"bad env", "", map[string]string{"PHOSPHOR_GRPC_PORT": "x"}, nil, `PHOSPHOR_GRPC_PORT: invalid integer "x"`},
+		{"bad flag", "", nil, []string{"-drain-timeout", "5"}, `-drain-timeout: invalid duration "5"`},
+		{"unknown flag", "", nil, []string{"-nope"}, "flag provided but not defined"},
+		{"stray argument", "", nil, []string{"serve"}, `unexpected argument "serve"`},
+		{"invalid port", "", nil, []string{"-grpc-port", "70000"}, "port 70000 out of range"},
+		{"invalid capacity", "retention:\n  profiles: 0\n", nil, nil, "profile capacity 0"},
+		{"invalid tab", "ui:\n  default_tab: home\n", nil, nil, `ui.default_tab "home"`},
+		{"invalid faults", "receiver:\n  faults:\n    reject_percent: 150\n", nil, nil, "faults: percentage 150"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]string{"PHOSPHOR_CONFIG": writeFile(t, tt.file)}
+			for k, v := range tt.vars {
+				vars[k] = v
+			}
+			_, _, err := load(testFlags(), append([]string{"-config", vars["PHOSPHOR_CONFIG"]}, tt.args...), env(vars))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := writeFile(t, "receiver:\n  auth:\n    bearer_token: secret\n")
+
+	err := Update(path, func(c *Config) {
+		c.SetRuntime(receiver.RuntimeConfig{
+			Port:            4317,
+			HTTPPort:        9318,
+			HTTPEnabled:     true,
+			TraceCapacity:   20,
+			MetricCapacity:  1000,
+			LogCapacity:     1000,
+			ProfileCapacity: 100,
+		})
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	config, _, err := load(testFlags(), []string{"-config", path}, env(nil))
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if config.Receiver.HTTPPort != 9318 || config.Retention.Traces != 20 {
+		t.Errorf("saved settings = %d/%d, want 9318/20", config.Receiver.HTTPPort, config.Retention.Traces)
+	}
+	if config.Receiver.Auth.BearerToken != "secret" {
+		t.Errorf("BearerToken = %q, want the file's other settings kept", config.Receiver.Auth.BearerToken)
+	}
+	if config.Receiver.DrainTimeout != 5*time.Second {
+		t.Errorf("DrainTimeout = %v, want durations to round-trip", config.Receiver.DrainTimeout)
+	}
+
+	if err := Update(path, func(c *Config) { c.Retention.Logs = 0 }); err == nil {
+		t.Error("Update() error = nil, want invalid settings refused")
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phosphor-project/phosphor/internal/receiver"
+)
+
+// setting is a value that both a flag and an environment variable can
+// override. Lists and nested entries such as scrape targets or tailed files
+// are only configurable in the file.
+type setting struct {
+	flag    string // Flag name; the variable is PHOSPHOR_ and the name in upper snake case
+	usage   string
+	boolean bool // The flag may be given without a value
+	set     func(c *Config, value string) error
+}
+
+// env returns the environment variable for s, e.g. PHOSPHOR_GRPC_PORT.
+func (s setting) env() string {
+	return "PHOSPHOR_" + strings.ToUpper(strings.ReplaceAll(s.flag, "-", "_"))
+}
+
+var settings = []setting{
+	intSetting("grpc-port", "OTLP/gRPC port", func(c *Config) *int { return &c.Receiver.GRPCPort }),
+	intSetting("http-port", "OTLP/HTTP port", func(c *Config) *int { return &c.Receiver.HTTPPort }),
+	boolSetting("http-enabled", "serve OTLP/HTTP, Zipkin, Jaeger Thrift and remote write", func(c *Config) *bool { return &c.Receiver.HTTPEnabled }),
+	listSetting("listen", "comma-separated gRPC listen addresses, e.g. :4317,unix:///tmp/phosphor.sock", func(c *Config) *[]string { return &c.Receiver.ListenAddresses }),
+	listSetting("http-listen", "comma-separated OTLP/HTTP listen addresses", func(c *Config) *[]string { return &c.Receiver.HTTPListenAddresses }),
+	boolSetting("port-fallback", "listen on a free port when a port is taken", func(c *Config) *bool { return &c.Receiver.PortFallback }),
+	durationSetting("drain-timeout", "how long shutdown waits for in-flight exports", func(c *Config) *time.Duration { return &c.Receiver.DrainTimeout }),
+
+	boolSetting("tls", "serve TLS", func(c *Config) *bool { return &c.Receiver.TLS.Enabled }),
+	stringSetting("tls-cert", "PEM server certificate", func(c *Config) *string { return &c.Receiver.TLS.CertFile }),
+	stringSetting("tls-key", "PEM server private key", func(c *Config) *string { return &c.Receiver.TLS.KeyFile }),
+	stringSetting("tls-client-ca", "PEM CA bundle for verifying client certificates", func(c *Config) *string { return &c.Receiver.TLS.ClientCAFile }),
+	boolSetting("tls-self-signed", "generate a self-signed development certificate", func(c *Config) *bool { return &c.Receiver.TLS.SelfSigned }),
+	stringSetting("auth-token", "bearer token required from exporters", func(c *Config) *string { return &c.Receiver.Auth.BearerToken }),
+	stringSetting("api-key", "API key required from exporters", func(c *Config) *string { return &c.Receiver.Auth.APIKey }),
+	intSetting("max-items-per-second", "sustained ingest rate limit (0 = unlimited)", func(c *Config) *int { return &c.Receiver.Admission.MaxItemsPerSecond }),
+
+	stringSetting("statsd-address", "StatsD UDP address, e.g. :8125", func(c *Config) *string { return &c.Receiver.StatsD.ListenAddress }),
+	stringSetting("syslog-tcp-address", "syslog TCP address, e.g. :5514", func(c *Config) *string { return &c.Receiver.Syslog.TCPAddress }),
+	stringSetting("syslog-udp-address", "syslog UDP address, e.g. :5514", func(c *Config) *string { return &c.Receiver.Syslog.UDPAddress }),
+	stringSetting("fluent-forward-address", "Fluent Forward address, e.g. :24224", func(c *Config) *string { return &c.Receiver.FluentForward.ListenAddress }),
+	durationSetting("self-telemetry-interval", "how often internal metrics are recorded (0 disables)", func(c *Config) *time.Duration { return &c.Receiver.SelfTelemetry.Interval }),
+	stringSetting("self-metrics-address", "serve internal metrics at /metrics on this address", func(c *Config) *string { return &c.Receiver.SelfTelemetry.MetricsAddress }),
+	stringSetting("query-address", "serve stored telemetry as JSON on this address, e.g. 127.0.0.1:4319", func(c *Config) *string { return &c.Receiver.QueryAddress }),
+	boolSetting("query-allow-clear", "let DELETE /api/telemetry on the query API clear every buffer", func(c *Config) *bool { return &c.Receiver.QueryAllowClear }),
+
+	intSetting("trace-capacity", "spans kept in memory", func(c *Config) *int { return &c.Retention.Traces }),
+	intSetting("metric-capacity", "metrics kept in memory", func(c *Config) *int { return &c.Retention.Metrics }),
+	intSetting("log-capacity", "log records kept in memory", func(c *Config) *int { return &c.Retention.Logs }),
+	intSetting("profile-capacity", "profiles kept in memory", func(c *Config) *int { return &c.Retention.Profiles }),
+
+	{
+		flag:  "upstream",
+		usage: "comma-separated OTLP endpoints to forward to; http:// and https:// URLs use OTLP/HTTP",
+		set: func(c *Config, value string) error {
+			c.Forwarding.Endpoints = nil
+			for _, endpoint := range splitList(value) {
+				protocol := receiver.UpstreamGRPC
+				if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+					protocol = receiver.UpstreamHTTP
+				}
+				c.Forwarding.Endpoints = append(c.Forwarding.Endpoints, receiver.UpstreamEndpoint{Endpoint: endpoint, Protocol: protocol})
+			}
+			return nil
+		},
+	},
+
+	boolSetting("start-streaming", "stream events as soon as the window opens", func(c *Config) *bool { return &c.UI.StartStreaming }),
+	intSetting("max-event-rate", "events per second streamed to the UI (0 = unlimited)", func(c *Config) *int { return &c.UI.MaxEventRate }),
+	stringSetting("default-tab", "tab shown on start: traces, metrics or logs", func(c *Config) *string { return &c.UI.DefaultTab }),
+}
+
+func stringSetting(name, usage string, field func(*Config) *string) setting {
+	return setting{flag: name, usage: usage, set: func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+func intSetting(name, usage string, field func(*Config) *int) setting {
+	return setting{flag: name, usage: usage, set: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field(c) = n
+		return nil
+	}}
+}
+
+func boolSetting(name, usage string, field func(*Config) *bool) setting {
+	return setting{flag: name, usage: usage, boolean: true, set: func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field(c) = b
+		return nil
+	}}
+}
+
+func durationSetting(name, usage string, field func(*Config) *time.Duration) setting {
+	return setting{flag: name, usage: usage, set: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, want e.g. 5s", value)
+		}
+		*field(c) = d
+		return nil
+	}}
+}
+
+func listSetting(name, usage string, field func(*Config) *[]string) setting {
+	return setting{flag: name, usage: usage, set: func(c *Config, value string) error {
+		*field(c) = splitList(value)
+		return nil
+	}}
+}
+
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,197 @@
+package receiver
+
+import (
+	"sync"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// defaultRetryAfter is the backoff suggested to exporters when no RetryAfter is configured.
+const defaultRetryAfter = time.Second
+
+// AdmissionConfig bounds how much ingestion work the receiver accepts at once.
+// Zero values disable the corresponding limit.
+type AdmissionConfig struct {
+	MaxInFlightRequests int           `yaml:"max_in_flight_requests"` // Concurrent Export calls being processed
+	MaxInFlightItems    int           `yaml:"max_in_flight_items"`    // Spans, metrics and log records being processed
+	MaxItemsPerSecond   int           `yaml:"max_items_per_second"`   // Sustained ingest rate across all signals
+	RetryAfter          time.Duration `yaml:"retry_after"`            // Backoff hinted to exporters (default: 1s)
+}
+
+// enabled reports whether any admission limit is configured.
+func (c AdmissionConfig) enabled() bool {
+	return c.MaxInFlightRequests > 0 || c.MaxInFlightItems > 0 || c.MaxItemsPerSecond > 0
+}
+
+// admissionController tracks in-flight work and an items-per-second token bucket.
+type admissionController struct {
+	config AdmissionConfig
+
+	mu               sync.Mutex
+	inFlightRequests int
+	inFlightItems    int
+	tokens           float64
+	lastRefill       time.Time
+}
+
+// newAdmissionController creates a controller with a full token bucket.
+func newAdmissionController(config AdmissionConfig) *admissionController {
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = defaultRetryAfter
+	}
+	return &admissionController{
+		config:     config,
+		tokens:     float64(config.MaxItemsPerSecond),
+		lastRefill: time.Now(),
+	}
+}
+
+// acquire admits a request carrying n items. On success the returned release
+// function must be called once processing completes. On rejection it returns a
+// gRPC status carrying RetryInfo so exporters back off:
+// Unavailable when in-flight limits are hit, ResourceExhausted when the rate is exceeded.
+func (a *admissionController) acquire(n int) (func(), error) {
+	if !a.config.enabled() {
+		return func() {}, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.config.MaxInFlightRequests > 0 && a.inFlightRequests >= a.config.MaxInFlightRequests {
+		return nil, a.reject(codes.Unavailable, "too many concurrent export requests")
+	}
+	// Always admit a request when nothing else is in flight so oversized batches can progress.
+	if a.config.MaxInFlightItems > 0 && a.inFlightItems > 0 && a.inFlightItems+n > a.config.MaxInFlightItems {
+		return nil, a.reject(codes.Unavailable, "too many items in flight")
+	}
+
+	if a.config.MaxItemsPerSecond > 0 {
+		a.refill()
+		cost := float64(n)
+		if limit := float64(a.config.MaxItemsPerSecond); cost > limit {
+			cost = limit
+		}
+		if a.tokens < cost {
+			return nil, a.reject(codes.ResourceExhausted, "ingest rate limit exceeded")
+		}
+		a.tokens -= cost
+	}
+
+	a.inFlightRequests++
+	a.inFlightItems += n
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mu.Lock()
+			a.inFlightRequests--
+			a.inFlightItems -= n
+			a.mu.Unlock()
+		})
+	}, nil
+}
+
+// refill tops up the token bucket based on elapsed time. Callers must hold mu.
+func (a *admissionController) refill() {
+	now := time.Now()
+	limit := float64(a.config.MaxItemsPerSecond)
+	a.tokens += now.Sub(a.lastRefill).Seconds() * limit
+	if a.tokens > limit {
+		a.tokens = limit
+	}
+	a.lastRefill = now
+}
+
+// reject builds a retryable status with a RetryInfo detail.
+func (a *admissionController) reject(code codes.Code, msg string) error {
+	st := status.New(code, msg)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(a.config.RetryAfter),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// inFlight returns the current number of admitted requests and items.
+func (a *admissionController) inFlight() (int, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.inFlightRequests, a.inFlightItems
+}
+
+// admit runs admission control for an export and records throttled requests.
+func (r *OTLPReceiver) admit(items int) (func(), error) {
+	release, err := r.admission.acquire(items)
+	if err != nil {
+		r.statsMu.Lock()
+		r.stats.Throttled++
+		r.statsMu.Unlock()
+		return nil, err
+	}
+	return release, nil
+}
+
+// retryDelay extracts the RetryInfo delay from a status, if present.
+func retryDelay(st *status.Status) (time.Duration, bool) {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			return info.RetryDelay.AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// countSpans returns the number of spans in a trace export request.
+func countSpans(req *coltracepb.ExportTraceServiceRequest) int {
+	n := 0
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			n += len(ss.Spans)
+		}
+	}
+	return n
+}
+
+// countMetrics returns the number of metrics in a metrics export request.
+func countMetrics(req *colmetricspb.ExportMetricsServiceRequest) int {
+	n := 0
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			n += len(sm.Metrics)
+		}
+	}
+	return n
+}
+
+// countLogs returns the number of log records in a logs export request.
+func countLogs(req *collogspb.ExportLogsServiceRequest) int {
+	n := 0
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			n += len(sl.LogRecords)
+		}
+	}
+	return n
+}
+
+// countProfiles returns the number of profiles in a profiles export request.
+func countProfiles(req *colprofilespb.ExportProfilesServiceRequest) int {
+	n := 0
+	for _, rp := range req.ResourceProfiles {
+		for _, sp := range rp.ScopeProfiles {
+			n += len(sp.Profiles)
+		}
+	}
+	return n
+}
@@ -0,0 +1,61 @@
+package receiver
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdmissionInFlightLimit(t *testing.T) {
+	a := newAdmissionController(AdmissionConfig{MaxInFlightRequests: 1, RetryAfter: 2 * time.Second})
+
+	release, err := a.acquire(10)
+	if err != nil {
+		t.Fatalf("first acquire() error = %v", err)
+	}
+
+	_, err = a.acquire(10)
+	st := status.Convert(err)
+	if st.Code() != codes.Unavailable {
+		t.Fatalf("code = %v, want Unavailable", st.Code())
+	}
+	if delay, ok := retryDelay(st); !ok || delay != 2*time.Second {
+		t.Errorf("retryDelay() = %v, %v; want 2s, true", delay, ok)
+	}
+
+	release()
+	release() // releasing twice must not double-decrement
+
+	if reqs, items := a.inFlight(); reqs != 0 || items != 0 {
+		t.Errorf("inFlight() = %d, %d after release; want 0, 0", reqs, items)
+	}
+	if _, err := a.acquire(10); err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+}
+
+func TestAdmissionRateLimit(t *testing.T) {
+	a := newAdmissionController(AdmissionConfig{MaxItemsPerSecond: 100})
+
+	release, err := a.acquire(100)
+	if err != nil {
+		t.Fatalf("acquire() within burst error = %v", err)
+	}
+	release()
+
+	_, err = a.acquire(50)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("code = %v, want ResourceExhausted", status.Code(err))
+	}
+}
+
+func TestAdmissionDisabled(t *testing.T) {
+	a := newAdmissionController(AdmissionConfig{})
+	for i := 0; i < 100; i++ {
+		if _, err := a.acquire(1_000_000); err != nil {
+			t.Fatalf("acquire() with no limits error = %v", err)
+		}
+	}
+}
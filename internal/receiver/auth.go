@@ -0,0 +1,111 @@
+package receiver
+
+import (
+	"context"
+	"crypto/subtle"
+	"mime"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// defaultAPIKeyHeader is used when AuthConfig.APIKey is set without a header name.
+const defaultAPIKeyHeader = "x-api-key"
+
+// AuthConfig holds optional static credentials required from exporters.
+// When neither BearerToken nor APIKey is set, ingestion is unauthenticated.
+type AuthConfig struct {
+	BearerToken  string `yaml:"bearer_token"`   // Accept "Authorization: Bearer <token>"
+	APIKey       string `yaml:"api_key"`        // Accept this value in APIKeyHeader
+	APIKeyHeader string `yaml:"api_key_header"` // Header carrying the API key (default: "x-api-key")
+}
+
+// enabled reports whether any credential is configured.
+func (c AuthConfig) enabled() bool {
+	return c.BearerToken != "" || c.APIKey != ""
+}
+
+// headerName returns the lower-cased API key header.
+func (c AuthConfig) headerName() string {
+	if c.APIKeyHeader == "" {
+		return defaultAPIKeyHeader
+	}
+	return strings.ToLower(c.APIKeyHeader)
+}
+
+// authorized checks a request's headers against the configured credentials.
+// get returns all values for a lower-cased header name.
+func (c AuthConfig) authorized(get func(name string) []string) bool {
+	if !c.enabled() {
+		return true
+	}
+
+	if c.BearerToken != "" {
+		for _, v := range get("authorization") {
+			scheme, token, ok := strings.Cut(v, " ")
+			if ok && strings.EqualFold(scheme, "bearer") && secureEqual(strings.TrimSpace(token), c.BearerToken) {
+				return true
+			}
+		}
+	}
+
+	if c.APIKey != "" {
+		for _, v := range get(c.headerName()) {
+			if secureEqual(v, c.APIKey) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// secureEqual compares secrets in constant time.
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// recordAuthFailure counts a rejected export.
+func (r *OTLPReceiver) recordAuthFailure() {
+	r.statsMu.Lock()
+	r.stats.AuthFailures++
+	r.statsMu.Unlock()
+}
+
+// authUnaryInterceptor rejects gRPC exports that lack valid credentials.
+func (r *OTLPReceiver) authUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !r.config.Auth.enabled() {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if !r.config.Auth.authorized(md.Get) {
+		r.recordAuthFailure()
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid credentials")
+	}
+	return handler(ctx, req)
+}
+
+// authMiddleware applies the same credential check to OTLP/HTTP requests.
+func (r *OTLPReceiver) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.config.Auth.enabled() {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		get := func(name string) []string { return req.Header.Values(name) }
+		if !r.config.Auth.authorized(get) {
+			r.recordAuthFailure()
+			contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeStatus(w, contentType, http.StatusUnauthorized, status.New(codes.Unauthenticated, "missing or invalid credentials"))
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
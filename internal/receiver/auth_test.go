@@ -0,0 +1,108 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Auth = AuthConfig{BearerToken: "s3cret", APIKey: "key-1", APIKeyHeader: "X-Phosphor-Key"}
+	r := NewOTLPReceiver(cfg)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		wantCode codes.Code
+	}{
+		{"no credentials", metadata.MD{}, codes.Unauthenticated},
+		{"valid bearer", metadata.Pairs("authorization", "Bearer s3cret"), codes.OK},
+		{"lowercase scheme", metadata.Pairs("authorization", "bearer s3cret"), codes.OK},
+		{"wrong bearer", metadata.Pairs("authorization", "Bearer nope"), codes.Unauthenticated},
+		{"valid api key", metadata.Pairs("x-phosphor-key", "key-1"), codes.OK},
+		{"api key in wrong header", metadata.Pairs("x-api-key", "key-1"), codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := r.authUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+
+	if got := r.GetReceiverStats().AuthFailures; got != 3 {
+		t.Errorf("AuthFailures = %d, want 3", got)
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Auth = AuthConfig{BearerToken: "s3cret", APIKey: "key-1"}
+	r := NewOTLPReceiver(cfg)
+	handler := r.authMiddleware(newHTTPHandler(r))
+
+	body, err := proto.Marshal(testTraceRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		wantCode int
+	}{
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"wrong bearer", "Authorization", "Bearer nope", http.StatusUnauthorized},
+		{"valid bearer", "Authorization", "Bearer s3cret", http.StatusOK},
+		{"valid api key", "X-Api-Key", "key-1", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/traces", bytes.NewReader(body))
+			req.Header.Set("Content-Type", contentTypeProtobuf)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusUnauthorized {
+				return
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q, want Bearer", got)
+			}
+			var st spb.Status
+			if err := proto.Unmarshal(rec.Body.Bytes(), &st); err != nil || codes.Code(st.Code) != codes.Unauthenticated {
+				t.Errorf("body = %v (%v), want an Unauthenticated status", &st, err)
+			}
+		})
+	}
+
+	if got := r.GetReceiverStats().AuthFailures; got != 2 {
+		t.Errorf("AuthFailures = %d, want 2", got)
+	}
+	if got := len(r.GetTraces()); got != 2 {
+		t.Errorf("GetTraces() returned %d spans, want 2 from the authorized requests", got)
+	}
+}
@@ -0,0 +1,232 @@
+package receiver
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// Transport names used in client identifiers.
+const (
+	transportGRPC   = "grpc"
+	transportHTTP   = "http"
+	transportScrape = "scrape"
+	transportStatsD = "statsd"
+	transportSyslog = "syslog"
+	transportFluent = "fluent"
+	transportFile   = "file"
+)
+
+// maxTrackedClients bounds the client table; the least recently seen
+// disconnected clients are evicted first.
+const maxTrackedClients = 1000
+
+// transportKey tags a request context with the transport it arrived on.
+type transportKey struct{}
+
+// withTransport records the ingestion transport in ctx.
+func withTransport(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, transportKey{}, transport)
+}
+
+// transportFromContext returns the ingestion transport, defaulting to gRPC.
+func transportFromContext(ctx context.Context) string {
+	if t, ok := ctx.Value(transportKey{}).(string); ok {
+		return t
+	}
+	return transportGRPC
+}
+
+// clientID builds the source identifier for a client connection.
+func clientID(transport, addr string) string {
+	return transport + "://" + addr
+}
+
+// trackedClient is the mutable state behind a models.ClientInfo.
+type trackedClient struct {
+	info     models.ClientInfo
+	services map[string]struct{}
+}
+
+// clientTracker records per-connection export activity.
+type clientTracker struct {
+	mu      sync.Mutex
+	clients map[string]*trackedClient
+}
+
+// newClientTracker creates an empty tracker.
+func newClientTracker() *clientTracker {
+	return &clientTracker{clients: make(map[string]*trackedClient)}
+}
+
+// identify returns the source identifier for the export in ctx, registering
+// the client on first sight.
+func (t *clientTracker) identify(ctx context.Context) string {
+	transport := transportFromContext(ctx)
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = peerAddress(p.Addr)
+	}
+	userAgent := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			userAgent = ua[0]
+		}
+	}
+
+	id := clientID(transport, addr)
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.clients[id]
+	if !ok {
+		t.evictLocked()
+		c = &trackedClient{
+			info: models.ClientInfo{
+				ID:          id,
+				Transport:   transport,
+				PeerAddress: addr,
+				FirstSeen:   now,
+			},
+			services: make(map[string]struct{}),
+		}
+		t.clients[id] = c
+	}
+	c.info.Connected = true
+	c.info.LastSeen = now
+	if userAgent != "" {
+		c.info.UserAgent = userAgent
+	}
+	return id
+}
+
+// recordExport adds an export's counts to the client identified by id.
+func (t *clientTracker) recordExport(id string, signal models.SignalType, items, bytes int, services map[string]struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.clients[id]
+	if !ok {
+		return
+	}
+	c.info.Exports++
+	c.info.Bytes += uint64(bytes)
+	switch signal {
+	case models.SignalTypeTrace:
+		c.info.Spans += uint64(items)
+	case models.SignalTypeMetric:
+		c.info.Metrics += uint64(items)
+	case models.SignalTypeLog:
+		c.info.Logs += uint64(items)
+	case models.SignalTypeProfile:
+		c.info.Profiles += uint64(items)
+	}
+	for name := range services {
+		c.services[name] = struct{}{}
+	}
+}
+
+// disconnect marks a client connection as closed.
+func (t *clientTracker) disconnect(transport, addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c, ok := t.clients[clientID(transport, addr)]; ok {
+		c.info.Connected = false
+	}
+}
+
+// evictLocked makes room for a new client. Callers must hold mu.
+func (t *clientTracker) evictLocked() {
+	if len(t.clients) < maxTrackedClients {
+		return
+	}
+	var victim *trackedClient
+	for _, c := range t.clients {
+		if victim == nil ||
+			(victim.info.Connected && !c.info.Connected) ||
+			(victim.info.Connected == c.info.Connected && c.info.LastSeen.Before(victim.info.LastSeen)) {
+			victim = c
+		}
+	}
+	delete(t.clients, victim.info.ID)
+}
+
+// list returns a snapshot of all clients ordered by first sight.
+func (t *clientTracker) list() []models.ClientInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]models.ClientInfo, 0, len(t.clients))
+	for _, c := range t.clients {
+		info := c.info
+		info.ServiceNames = make([]string, 0, len(c.services))
+		for name := range c.services {
+			info.ServiceNames = append(info.ServiceNames, name)
+		}
+		sort.Strings(info.ServiceNames)
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].FirstSeen.Before(result[j].FirstSeen) })
+	return result
+}
+
+// clear forgets all clients.
+func (t *clientTracker) clear() {
+	t.mu.Lock()
+	t.clients = make(map[string]*trackedClient)
+	t.mu.Unlock()
+}
+
+// connAddrKey carries a gRPC connection's remote address between stats callbacks.
+type connAddrKey struct{}
+
+// connStatsHandler observes gRPC connection lifecycle to mark clients
+// disconnected, and counts failed calls.
+type connStatsHandler struct {
+	receiver *OTLPReceiver
+}
+
+// TagConn implements stats.Handler.
+func (h *connStatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return context.WithValue(ctx, connAddrKey{}, peerAddress(info.RemoteAddr))
+}
+
+// HandleConn implements stats.Handler.
+func (h *connStatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnEnd); !ok {
+		return
+	}
+	if addr, ok := ctx.Value(connAddrKey{}).(string); ok {
+		h.receiver.clients.disconnect(transportGRPC, addr)
+	}
+}
+
+// TagRPC implements stats.Handler.
+func (h *connStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler. It sees failures the handlers never
+// do, such as requests that cannot be decoded.
+func (h *connStatsHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	end, ok := s.(*stats.End)
+	if !ok || end.Error == nil || !countsAsError(status.Code(end.Error)) {
+		return
+	}
+	h.receiver.recordError("gRPC call failed: %v", end.Error)
+}
+
+// GetClients returns the clients that have exported telemetry.
+func (r *OTLPReceiver) GetClients() []models.ClientInfo {
+	return r.clients.list()
+}
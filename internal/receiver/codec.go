@@ -0,0 +1,43 @@
+package receiver
+
+import (
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/mem"
+)
+
+// gogoMessage is implemented by gogo/protobuf generated types such as Jaeger's
+// api_v2 messages, which the standard protobuf codec cannot reflect over.
+type gogoMessage interface {
+	Marshal() ([]byte, error)
+	Unmarshal([]byte) error
+}
+
+// serverCodec serves OTLP messages with the standard protobuf codec and Jaeger
+// messages with their generated gogo marshalers, so one gRPC server can host both.
+type serverCodec struct{}
+
+// Marshal implements encoding.CodecV2.
+func (serverCodec) Marshal(v any) (mem.BufferSlice, error) {
+	if m, ok := v.(gogoMessage); ok {
+		data, err := m.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		return mem.BufferSlice{mem.SliceBuffer(data)}, nil
+	}
+	return encoding.GetCodecV2(proto.Name).Marshal(v)
+}
+
+// Unmarshal implements encoding.CodecV2.
+func (serverCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	if m, ok := v.(gogoMessage); ok {
+		return m.Unmarshal(data.Materialize())
+	}
+	return encoding.GetCodecV2(proto.Name).Unmarshal(data, v)
+}
+
+// Name implements encoding.CodecV2.
+func (serverCodec) Name() string {
+	return proto.Name
+}
@@ -0,0 +1,207 @@
+package receiver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Content types of the Prometheus exposition formats.
+const (
+	contentTypeOpenMetrics = "application/openmetrics-text"
+	contentTypePromText    = "text/plain"
+)
+
+// promTypes maps # TYPE values to remote-write metadata types so scraped and
+// remote-written series share one conversion path.
+var promTypes = map[string]int32{
+	"counter":        promTypeCounter,
+	"gauge":          promTypeGauge,
+	"histogram":      promTypeHistogram,
+	"gaugehistogram": 4,
+	"summary":        promTypeSummary,
+	"info":           6,
+	"stateset":       7,
+	"unknown":        promTypeUnknown,
+	"untyped":        promTypeUnknown,
+}
+
+// parseExposition parses a Prometheus text (0.0.4) or OpenMetrics 1.0 payload.
+// Samples without an explicit timestamp are stamped with defaultTimestampMs.
+func parseExposition(body []byte, contentType string, defaultTimestampMs int64) ([]promSeries, map[string]promMetadata, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	openMetrics := mediaType == contentTypeOpenMetrics
+
+	var series []promSeries
+	metadata := make(map[string]promMetadata)
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxHTTPBodySize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			if line == "# EOF" {
+				break
+			}
+			parseExpositionComment(line, metadata)
+			continue
+		}
+
+		s, err := parseSampleLine(line, openMetrics, defaultTimestampMs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		// OpenMetrics _created series carry start times, not values.
+		if base, ok := strings.CutSuffix(s.labels[promLabelName], "_created"); ok {
+			if md, ok := metadata[base]; ok && md.typ != promTypeUnknown && md.typ != promTypeGauge {
+				continue
+			}
+		}
+		series = append(series, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return series, metadata, nil
+}
+
+// parseExpositionComment records # HELP, # TYPE and # UNIT metadata.
+func parseExpositionComment(line string, metadata map[string]promMetadata) {
+	fields := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "#")), " ", 3)
+	if len(fields) < 3 {
+		return
+	}
+	keyword, name, value := fields[0], fields[1], fields[2]
+
+	md := metadata[name]
+	switch keyword {
+	case "HELP":
+		md.help = unescapeHelp(value)
+	case "TYPE":
+		md.typ = promTypes[strings.ToLower(value)]
+	case "UNIT":
+		md.unit = value
+	default:
+		return
+	}
+	metadata[name] = md
+}
+
+// unescapeHelp reverses the escaping applied to # HELP text.
+func unescapeHelp(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\"`, `"`).Replace(s)
+}
+
+// parseSampleLine parses `name{label="value",...} value [timestamp] [# exemplar]`.
+func parseSampleLine(line string, openMetrics bool, defaultTimestampMs int64) (promSeries, error) {
+	s := promSeries{labels: make(map[string]string)}
+
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return s, fmt.Errorf("invalid sample %q", line)
+	}
+	s.labels[promLabelName] = line[:end]
+	rest := line[end:]
+
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		if rest, err = parseLabels(rest[1:], s.labels); err != nil {
+			return s, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return s, fmt.Errorf("missing value for %s", s.labels[promLabelName])
+	}
+	value, err := parsePromFloat(fields[0])
+	if err != nil {
+		return s, fmt.Errorf("invalid value %q: %w", fields[0], err)
+	}
+
+	timestampMs := defaultTimestampMs
+	if len(fields) > 1 && fields[1] != "#" {
+		if openMetrics {
+			// OpenMetrics timestamps are seconds, possibly fractional.
+			seconds, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return s, fmt.Errorf("invalid timestamp %q: %w", fields[1], err)
+			}
+			timestampMs = int64(math.Round(seconds * 1000))
+		} else {
+			timestampMs, err = strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return s, fmt.Errorf("invalid timestamp %q: %w", fields[1], err)
+			}
+		}
+	}
+
+	s.samples = []promSample{{value: value, timestampMs: timestampMs}}
+	return s, nil
+}
+
+// parseLabels parses a label set up to and including the closing brace and
+// returns the remainder of the line.
+func parseLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if strings.HasPrefix(s, "}") {
+			return s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return "", fmt.Errorf("invalid label set near %q", s)
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		if !strings.HasPrefix(s, `"`) {
+			return "", fmt.Errorf("label %s value is not quoted", name)
+		}
+
+		var value strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("unterminated value for label %s", name)
+		}
+		labels[name] = value.String()
+
+		s = strings.TrimLeft(s[i+1:], " \t")
+		s = strings.TrimPrefix(s, ",")
+	}
+}
+
+// parsePromFloat parses a sample value, accepting the exposition spellings of
+// NaN and infinities.
+func parsePromFloat(s string) (float64, error) {
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "+Inf", "Inf":
+		return math.Inf(+1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
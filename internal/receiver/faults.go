@@ -0,0 +1,328 @@
+package receiver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// internalServiceName is the service name of telemetry Phosphor records about itself.
+const internalServiceName = "phosphor"
+
+// Injected fault kinds, reported in the phosphor.fault.type attribute.
+const (
+	faultLatency           = "latency"
+	faultUnavailable       = "unavailable"
+	faultResourceExhausted = "resource_exhausted"
+	faultPartialSuccess    = "partial_success"
+	faultConnectionReset   = "connection_reset"
+)
+
+// FaultConfig injects failures into the OTLP Export handlers so exporter retry
+// behaviour can be exercised. It only applies to exports arriving over gRPC or
+// HTTP. Percentages range from 0 to 100 and zero disables a fault. Unlike the
+// rest of Config it is exchanged with the frontend, hence the JSON tags.
+type FaultConfig struct {
+	LatencyMs                int     `json:"latencyMs" yaml:"latency_ms"`                                // Delay added to every export
+	LatencyJitterMs          int     `json:"latencyJitterMs" yaml:"latency_jitter_ms"`                   // Random extra delay of up to this much
+	UnavailablePercent       float64 `json:"unavailablePercent" yaml:"unavailable_percent"`              // Exports refused with Unavailable (HTTP 503)
+	ResourceExhaustedPercent float64 `json:"resourceExhaustedPercent" yaml:"resource_exhausted_percent"` // Exports refused with ResourceExhausted (HTTP 429)
+	ResetPercent             float64 `json:"resetPercent" yaml:"reset_percent"`                          // Exports whose connection is reset without a response
+	RejectPercent            float64 `json:"rejectPercent" yaml:"reject_percent"`                        // Items dropped and reported through partial success
+	RetryAfterMs             int     `json:"retryAfterMs" yaml:"retry_after_ms"`                         // RetryInfo delay sent with injected errors; zero omits it
+}
+
+// enabled reports whether any fault is configured.
+func (c FaultConfig) enabled() bool {
+	return c.LatencyMs > 0 || c.LatencyJitterMs > 0 || c.UnavailablePercent > 0 ||
+		c.ResourceExhaustedPercent > 0 || c.ResetPercent > 0 || c.RejectPercent > 0
+}
+
+// validate checks that durations are non-negative and percentages add up.
+func (c FaultConfig) validate() error {
+	if c.LatencyMs < 0 || c.LatencyJitterMs < 0 || c.RetryAfterMs < 0 {
+		return fmt.Errorf("latency and retry delays must not be negative")
+	}
+	for _, p := range []float64{c.UnavailablePercent, c.ResourceExhaustedPercent, c.ResetPercent, c.RejectPercent} {
+		if p < 0 || p > 100 {
+			return fmt.Errorf("percentage %v out of range 0-100", p)
+		}
+	}
+	if total := c.UnavailablePercent + c.ResourceExhaustedPercent + c.ResetPercent; total > 100 {
+		return fmt.Errorf("unavailable, resource exhausted and reset percentages add up to %v, more than 100", total)
+	}
+	return nil
+}
+
+// faultInjector holds the active fault configuration.
+type faultInjector struct {
+	mu     sync.RWMutex
+	config FaultConfig
+}
+
+func (f *faultInjector) get() FaultConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.config
+}
+
+func (f *faultInjector) set(config FaultConfig) {
+	f.mu.Lock()
+	f.config = config
+	f.mu.Unlock()
+}
+
+// itemFaults rejects individual items of one export. A nil *itemFaults
+// rejects nothing.
+type itemFaults struct {
+	percent  float64
+	rejected int
+}
+
+// reject reports whether the next item should be dropped.
+func (f *itemFaults) reject() bool {
+	if f == nil || rand.Float64()*100 >= f.percent {
+		return false
+	}
+	f.rejected++
+	return true
+}
+
+// injectedRejection is the partial success reason for items dropped by a fault.
+const injectedRejection = "rejected by fault injection"
+
+// SetFaults replaces the active fault configuration. The zero FaultConfig
+// turns fault injection off.
+func (r *OTLPReceiver) SetFaults(config FaultConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	r.faults.set(config)
+	log.Printf("[Phosphor] Fault injection %s", describeFaults(config))
+	return nil
+}
+
+// GetFaults returns the active fault configuration.
+func (r *OTLPReceiver) GetFaults() FaultConfig {
+	return r.faults.get()
+}
+
+// describeFaults summarises a configuration for the log.
+func describeFaults(c FaultConfig) string {
+	if !c.enabled() {
+		return "disabled"
+	}
+	return fmt.Sprintf("enabled: latency=%dms+%dms unavailable=%v%% resource_exhausted=%v%% reset=%v%% reject=%v%%",
+		c.LatencyMs, c.LatencyJitterMs, c.UnavailablePercent, c.ResourceExhaustedPercent, c.ResetPercent, c.RejectPercent)
+}
+
+// injectFaults applies the configured faults to an export before it is
+// processed. It returns the error to answer with, if the export fails, and
+// the per-item rejections to apply otherwise.
+func (r *OTLPReceiver) injectFaults(ctx context.Context, signal models.SignalType) (*itemFaults, error) {
+	config := r.faults.get()
+	if !config.enabled() {
+		return nil, nil
+	}
+	transport := transportFromContext(ctx)
+	if transport != transportGRPC && transport != transportHTTP {
+		return nil, nil
+	}
+
+	if delay := time.Duration(config.LatencyMs) * time.Millisecond; delay > 0 || config.LatencyJitterMs > 0 {
+		if config.LatencyJitterMs > 0 {
+			delay += time.Duration(rand.IntN(config.LatencyJitterMs+1)) * time.Millisecond
+		}
+		r.recordFault(ctx, signal, faultLatency, fmt.Sprintf("delayed %s export by %s", signal, delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+
+	roll := rand.Float64() * 100
+	switch {
+	case roll < config.ResetPercent:
+		msg := fmt.Sprintf("reset connection during %s export", signal)
+		if !r.conns.reset(ctx) {
+			msg = fmt.Sprintf("answered %s export with Unavailable; the connection cannot be reset", signal)
+		}
+		r.recordFault(ctx, signal, faultConnectionReset, msg)
+		return nil, status.Error(codes.Unavailable, "connection reset by fault injection")
+	case roll < config.ResetPercent+config.UnavailablePercent:
+		r.recordFault(ctx, signal, faultUnavailable, fmt.Sprintf("answered %s export with Unavailable", signal))
+		return nil, injectedStatus(codes.Unavailable, config.RetryAfterMs)
+	case roll < config.ResetPercent+config.UnavailablePercent+config.ResourceExhaustedPercent:
+		r.recordFault(ctx, signal, faultResourceExhausted, fmt.Sprintf("answered %s export with ResourceExhausted", signal))
+		return nil, injectedStatus(codes.ResourceExhausted, config.RetryAfterMs)
+	}
+
+	if config.RejectPercent > 0 {
+		return &itemFaults{percent: config.RejectPercent}, nil
+	}
+	return nil, nil
+}
+
+// finishFaults records the partial success rejections made during an export.
+func (r *OTLPReceiver) finishFaults(ctx context.Context, signal models.SignalType, faults *itemFaults) {
+	if faults == nil || faults.rejected == 0 {
+		return
+	}
+	r.recordFault(ctx, signal, faultPartialSuccess, fmt.Sprintf("rejected %d items of %s export", faults.rejected, signal))
+}
+
+// injectedStatus builds an injected error, with RetryInfo when a delay is configured.
+func injectedStatus(code codes.Code, retryAfterMs int) error {
+	st := status.New(code, "injected fault")
+	if retryAfterMs > 0 {
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(retryAfterMs) * time.Millisecond),
+		}); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// recordFault logs an injected fault and stores it as a warning log from the
+// internal phosphor service, so it shows up in the UI alongside the exports.
+func (r *OTLPReceiver) recordFault(ctx context.Context, signal models.SignalType, kind, msg string) {
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = peerAddress(p.Addr)
+	}
+	client := clientID(transportFromContext(ctx), addr)
+	log.Printf("[Phosphor] Fault injected: %s (%s)", msg, client)
+
+	now := time.Now()
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(now.UnixNano()),
+		ObservedTimeUnixNano: uint64(now.UnixNano()),
+		SeverityNumber:       logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+		SeverityText:         "WARN",
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "Fault injected: " + msg}},
+		Attributes: []*commonpb.KeyValue{
+			stringKV("phosphor.fault.type", kind),
+			stringKV("phosphor.fault.signal", string(signal)),
+			stringKV("phosphor.client", client),
+		},
+	}
+	resource := models.ConvertResource(&resourcepb.Resource{
+		Attributes: []*commonpb.KeyValue{stringKV("service.name", internalServiceName)},
+	})
+	scope := models.InstrumentationScope{Name: "phosphor/faults"}
+	converted, err := models.ConvertLogRecord(record, resource, scope)
+	if err != nil {
+		return
+	}
+	converted.Source = client
+	r.logs.Push(converted)
+	r.emitEvent(models.TelemetryEvent{
+		Type:      models.SignalTypeLog,
+		Log:       &converted,
+		Timestamp: converted.ReceivedAt,
+	})
+
+	r.statsMu.Lock()
+	r.stats.FaultsInjected++
+	r.statsMu.Unlock()
+}
+
+// connRegistry tracks accepted TCP connections by remote address so a fault
+// can reset the connection an export arrived on.
+type connRegistry struct {
+	mu    sync.Mutex
+	conns map[string]net.Conn
+}
+
+func newConnRegistry() *connRegistry {
+	return &connRegistry{conns: make(map[string]net.Conn)}
+}
+
+// track wraps listeners so their TCP connections are registered.
+func (c *connRegistry) track(listeners []net.Listener) []net.Listener {
+	tracked := make([]net.Listener, len(listeners))
+	for i, l := range listeners {
+		tracked[i] = &trackedListener{Listener: l, registry: c}
+	}
+	return tracked
+}
+
+// reset aborts the TCP connection of the export in ctx with an RST. It
+// returns false when the connection is unknown, e.g. for unix sockets.
+func (c *connRegistry) reset(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return false
+	}
+	c.mu.Lock()
+	conn, ok := c.conns[p.Addr.String()]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if tcp, ok := conn.(*trackedConn).Conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+	return true
+}
+
+// trackedListener registers accepted TCP connections with a connRegistry.
+type trackedListener struct {
+	net.Listener
+	registry *connRegistry
+}
+
+// Accept implements net.Listener.
+func (l *trackedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := conn.(*net.TCPConn); !ok {
+		return conn, nil
+	}
+	tracked := &trackedConn{Conn: conn, registry: l.registry, key: conn.RemoteAddr().String()}
+	l.registry.mu.Lock()
+	l.registry.conns[tracked.key] = tracked
+	l.registry.mu.Unlock()
+	return tracked, nil
+}
+
+// trackedConn unregisters itself when closed.
+type trackedConn struct {
+	net.Conn
+	registry *connRegistry
+	key      string
+	once     sync.Once
+}
+
+// Close implements net.Conn.
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.registry.mu.Lock()
+		if c.registry.conns[c.key] == c {
+			delete(c.registry.conns, c.key)
+		}
+		c.registry.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
@@ -0,0 +1,146 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestFaultConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  FaultConfig
+		wantErr bool
+	}{
+		{"zero", FaultConfig{}, false},
+		{"all faults", FaultConfig{LatencyMs: 10, UnavailablePercent: 50, ResourceExhaustedPercent: 25, ResetPercent: 25, RejectPercent: 100}, false},
+		{"negative latency", FaultConfig{LatencyMs: -1}, true},
+		{"percentage above 100", FaultConfig{RejectPercent: 101}, true},
+		{"errors above 100 in total", FaultConfig{UnavailablePercent: 60, ResourceExhaustedPercent: 60}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInjectedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   FaultConfig
+		wantCode codes.Code
+		wantKind string
+	}{
+		{"unavailable", FaultConfig{UnavailablePercent: 100, RetryAfterMs: 2000}, codes.Unavailable, faultUnavailable},
+		{"resource exhausted", FaultConfig{ResourceExhaustedPercent: 100, RetryAfterMs: 2000}, codes.ResourceExhausted, faultResourceExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewOTLPReceiver(DefaultConfig())
+			if err := r.SetFaults(tt.config); err != nil {
+				t.Fatalf("SetFaults() error = %v", err)
+			}
+
+			_, err := r.traceService.Export(context.Background(), testTraceRequest())
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Fatalf("Export() code = %v, want %v", st.Code(), tt.wantCode)
+			}
+			if delay, ok := retryDelay(st); !ok || delay != 2*time.Second {
+				t.Errorf("retryDelay = %v, %v; want 2s", delay, ok)
+			}
+			if got := len(r.GetTraces()); got != 0 {
+				t.Errorf("GetTraces() returned %d spans, want 0", got)
+			}
+
+			logs := r.GetLogs()
+			if len(logs) != 1 || logs[0].Resource.ServiceName != internalServiceName {
+				t.Fatalf("GetLogs() = %v, want one fault event from %q", logs, internalServiceName)
+			}
+			if got := attributeValue(logs[0].Attributes, "phosphor.fault.type"); got != tt.wantKind {
+				t.Errorf("phosphor.fault.type = %v, want %q", got, tt.wantKind)
+			}
+			if got := r.GetReceiverStats().FaultsInjected; got != 1 {
+				t.Errorf("FaultsInjected = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestInjectedPartialSuccessAndLatency(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	if err := r.SetFaults(FaultConfig{LatencyMs: 50, RejectPercent: 100}); err != nil {
+		t.Fatalf("SetFaults() error = %v", err)
+	}
+
+	start := time.Now()
+	resp, err := r.traceService.Export(context.Background(), testTraceRequest())
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Export() took %v, want at least 50ms", elapsed)
+	}
+	if resp.PartialSuccess.GetRejectedSpans() != 1 || resp.PartialSuccess.GetErrorMessage() != injectedRejection {
+		t.Errorf("PartialSuccess = %v, want the span rejected by fault injection", resp.PartialSuccess)
+	}
+	if got := len(r.GetTraces()); got != 0 {
+		t.Errorf("GetTraces() returned %d spans, want 0", got)
+	}
+
+	kinds := make(map[any]bool)
+	for _, l := range r.GetLogs() {
+		kinds[attributeValue(l.Attributes, "phosphor.fault.type")] = true
+	}
+	if !kinds[faultLatency] || !kinds[faultPartialSuccess] {
+		t.Errorf("fault events = %v, want latency and partial_success", kinds)
+	}
+
+	// Internal sources are never subject to faults.
+	ctx := withTransport(context.Background(), transportStatsD)
+	if resp, err := r.traceService.Export(ctx, testTraceRequest()); err != nil || resp.PartialSuccess != nil {
+		t.Errorf("Export(statsd) = %v, %v; want accepted", resp, err)
+	}
+
+	if err := r.SetFaults(FaultConfig{}); err != nil {
+		t.Fatalf("SetFaults() error = %v", err)
+	}
+	if resp, err := r.traceService.Export(context.Background(), testTraceRequest()); err != nil || resp.PartialSuccess != nil {
+		t.Errorf("Export() after disabling faults = %v, %v; want accepted", resp, err)
+	}
+}
+
+func TestInjectedConnectionReset(t *testing.T) {
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(t.TempDir(), "otlp.sock")}
+	config.HTTPListenAddresses = []string{"127.0.0.1:0"}
+	config.Faults = FaultConfig{ResetPercent: 100}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	body, err := proto.Marshal(testTraceRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post("http://"+r.HTTPAddresses()[0]+"/v1/traces", contentTypeProtobuf, bytes.NewReader(body))
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("POST succeeded with %s, want the connection reset", resp.Status)
+	}
+	if got := r.GetReceiverStats().FaultsInjected; got != 1 {
+		t.Errorf("FaultsInjected = %d, want 1", got)
+	}
+}
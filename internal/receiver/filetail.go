@@ -0,0 +1,331 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/peer"
+)
+
+// TailConfig configures following local log files.
+type TailConfig struct {
+	Files        []TailFile    `yaml:"files"`         // Files to follow; tailing is disabled when empty
+	PollInterval time.Duration `yaml:"poll_interval"` // How often files are checked for new lines (default: 250ms)
+}
+
+// TailFile is a log file to follow.
+type TailFile struct {
+	Path        string        `yaml:"path"`         // File to follow; it need not exist yet
+	ServiceName string        `yaml:"service_name"` // Resource service.name (default: the file name without extension)
+	Format      string        `yaml:"format"`       // LogFormatAuto, LogFormatJSON, LogFormatLogfmt or LogFormatText
+	FromStart   bool          `yaml:"from_start"`   // Read existing content on start instead of only new lines
+	Keys        LogKeyMapping `yaml:"keys"`         // Field names for timestamp, severity, message and trace context
+}
+
+const (
+	// maxTailRead bounds how much of a file is read per poll.
+	maxTailRead = 4 * 1024 * 1024
+
+	// maxTailLine bounds a line; longer lines are split.
+	maxTailLine = 256 * 1024
+
+	// Log attribute keys for the source file, per OpenTelemetry semantic conventions.
+	attrLogFileName = "log.file.name"
+	attrLogFilePath = "log.file.path"
+)
+
+// fileAddr identifies a tailed file as the peer of its exports.
+type fileAddr string
+
+func (a fileAddr) Network() string { return "file" }
+func (a fileAddr) String() string  { return string(a) }
+
+// tailer follows one file across rotation and truncation.
+type tailer struct {
+	config TailFile
+	keys   LogKeyMapping
+
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	started bool // whether the first poll has run
+}
+
+// newTailer applies defaults for a file.
+func newTailer(config TailFile) *tailer {
+	if config.ServiceName == "" {
+		base := filepath.Base(config.Path)
+		config.ServiceName = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return &tailer{config: config, keys: config.Keys.withDefaults()}
+}
+
+// poll returns the complete lines appended since the previous poll. A file
+// replaced at its path (rotation) is read to the end, over as many polls as
+// that takes, before switching to the new file; a file that shrank
+// (truncation) is re-read from the start.
+func (t *tailer) poll() ([]string, error) {
+	first := !t.started
+	t.started = true
+
+	info, err := os.Stat(t.config.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if t.file == nil {
+		if info == nil {
+			return nil, nil
+		}
+		if t.info != nil && os.SameFile(t.info, info) {
+			err = t.reopen()
+		} else {
+			// Files that appear after start are new, so read them in full.
+			err = t.open(!first || t.config.FromStart)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lines []string
+	switch {
+	case info != nil && !os.SameFile(t.info, info):
+		var drained bool
+		lines, drained, err = t.read()
+		if err != nil || !drained {
+			return lines, err
+		}
+		if len(bytes.TrimSpace(t.partial)) > 0 {
+			lines = append(lines, string(t.partial))
+		}
+		t.close()
+		if err := t.open(true); err != nil {
+			return lines, err
+		}
+	case info != nil && info.Size() < t.offset:
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		t.offset = 0
+		t.partial = nil
+	}
+
+	more, _, err := t.read()
+	return append(lines, more...), err
+}
+
+// open opens the file at the configured path, positioned at the start or end.
+func (t *tailer) open(fromStart bool) error {
+	f, err := os.Open(t.config.Path)
+	if err != nil {
+		return err
+	}
+	// Stat the opened file so a rotation between Stat and Open is noticed next poll.
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	t.offset = 0
+	if !fromStart {
+		if t.offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	t.file, t.info, t.partial = f, info, nil
+	return nil
+}
+
+// reopen resumes a file closed by a restart at the offset reached before it.
+func (t *tailer) reopen() error {
+	f, err := os.Open(t.config.Path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	t.file = f
+	return nil
+}
+
+// read consumes newly appended data, up to maxTailRead, keeping an incomplete
+// final line for the next poll. drained reports whether it reached the end.
+func (t *tailer) read() (lines []string, drained bool, err error) {
+	buf := make([]byte, 64*1024)
+	for read := 0; read < maxTailRead; {
+		n, err := t.file.Read(buf)
+		t.offset += int64(n)
+		read += n
+
+		data := append(t.partial, buf[:n]...)
+		for {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			if line := bytes.TrimRight(data[:i], "\r"); len(bytes.TrimSpace(line)) > 0 {
+				lines = append(lines, string(line))
+			}
+			data = data[i+1:]
+		}
+		if len(data) > maxTailLine {
+			lines = append(lines, string(data))
+			data = nil
+		}
+		t.partial = append([]byte(nil), data...)
+
+		if errors.Is(err, io.EOF) || n == 0 {
+			return lines, true, nil
+		}
+		if err != nil {
+			return lines, false, err
+		}
+	}
+	return lines, false, nil
+}
+
+func (t *tailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// logRecord converts a line to a log record, parsing it according to the
+// file's format and key mapping.
+func (t *tailer) logRecord(line string, observed time.Time) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+		Attributes: []*commonpb.KeyValue{
+			stringKV(attrLogFileName, filepath.Base(t.config.Path)),
+			stringKV(attrLogFilePath, t.config.Path),
+		},
+	}
+	if fields, ok := parseLogLine(line, t.config.Format); ok {
+		structuredLogRecord(fields, t.keys, record)
+	}
+	if record.Body == nil {
+		record.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: line}}
+	}
+	return record
+}
+
+// request builds an OTLP logs request for lines read from the file.
+func (t *tailer) request(lines []string, observed time.Time) *collogspb.ExportLogsServiceRequest {
+	sl := &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: "filelog"}}
+	for _, line := range lines {
+		sl.LogRecords = append(sl.LogRecords, t.logRecord(line, observed))
+	}
+	return &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource:  &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringKV(attrServiceName, t.config.ServiceName)}},
+			ScopeLogs: []*logspb.ScopeLogs{sl},
+		}},
+	}
+}
+
+// fileTailer polls every configured file on one goroutine.
+type fileTailer struct {
+	receiver *OTLPReceiver
+	interval time.Duration
+	tailers  []*tailer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newFileTailer prepares a tailer per file.
+func newFileTailer(r *OTLPReceiver, config TailConfig) *fileTailer {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 250 * time.Millisecond
+	}
+	ft := &fileTailer{receiver: r, interval: config.PollInterval, done: make(chan struct{})}
+	for _, file := range config.Files {
+		ft.tailers = append(ft.tailers, newTailer(file))
+	}
+	return ft
+}
+
+// resume carries each file's position over from a stopped fileTailer, so a
+// restart neither re-reads lines nor skips those written while it ran.
+func (ft *fileTailer) resume(previous *fileTailer) {
+	if previous == nil {
+		return
+	}
+	for _, t := range ft.tailers {
+		for _, p := range previous.tailers {
+			if p.config.Path == t.config.Path {
+				t.info, t.offset, t.partial, t.started = p.info, p.offset, p.partial, p.started
+			}
+		}
+	}
+}
+
+// start polls immediately, so existing files are positioned before any new
+// writes, and then on every interval.
+func (ft *fileTailer) start() {
+	for _, t := range ft.tailers {
+		log.Printf("[Phosphor] Tailing %s (format=%q)", t.config.Path, t.config.Format)
+	}
+	ft.pollAll()
+
+	ft.wg.Add(1)
+	go func() {
+		defer ft.wg.Done()
+		ticker := time.NewTicker(ft.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ft.done:
+				return
+			case <-ticker.C:
+				ft.pollAll()
+			}
+		}
+	}()
+}
+
+// stop ends polling and closes the files, keeping their positions for resume.
+func (ft *fileTailer) stop() {
+	close(ft.done)
+	ft.wg.Wait()
+	for _, t := range ft.tailers {
+		t.close()
+	}
+}
+
+func (ft *fileTailer) pollAll() {
+	for _, t := range ft.tailers {
+		lines, err := t.poll()
+		if err != nil {
+			ft.receiver.recordError("Failed to read %s: %v", t.config.Path, err)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		ctx := withTransport(context.Background(), transportFile)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: fileAddr(t.config.Path)})
+		if _, err := ft.receiver.logsService.Export(ctx, t.request(lines, time.Now())); err != nil {
+			ft.receiver.recordError("Failed to export lines from %s: %v", t.config.Path, err)
+		}
+	}
+}
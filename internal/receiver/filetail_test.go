@@ -0,0 +1,346 @@
+package receiver
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		format string
+		want   map[string]interface{}
+	}{
+		{"json", `{"level":"info","msg":"started","port":8080,"ratio":0.5}`, LogFormatAuto,
+			map[string]interface{}{"level": "info", "msg": "started", "port": int64(8080), "ratio": 0.5}},
+		{"logfmt", `ts=2024-01-05T12:00:00Z level=warn msg="disk \"almost\" full" retry`, LogFormatAuto,
+			map[string]interface{}{"ts": "2024-01-05T12:00:00Z", "level": "warn", "msg": `disk "almost" full`, "retry": true}},
+		{"prose is not logfmt", `listening on port=8080`, LogFormatAuto, nil},
+		{"forced logfmt", `listening on port=8080`, LogFormatLogfmt,
+			map[string]interface{}{"listening": true, "on": true, "port": "8080"}},
+		{"text format", `{"msg":"ignored"}`, LogFormatText, nil},
+		{"invalid json", `{"msg":`, LogFormatJSON, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseLogLine(tt.line, tt.format)
+			if ok != (tt.want != nil) {
+				t.Fatalf("parseLogLine() ok = %v, want %v", ok, tt.want != nil)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLogLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructuredLogRecord(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const spanID = "00f067aa0ba902b7"
+
+	tests := []struct {
+		name         string
+		fields       map[string]interface{}
+		keys         LogKeyMapping
+		wantBody     string
+		wantSeverity logspb.SeverityNumber
+		wantTime     time.Time
+		wantTrace    bool
+		wantAttrs    int
+	}{
+		{
+			name:         "default keys",
+			fields:       map[string]interface{}{"time": "2024-01-05T12:00:00.5Z", "level": "ERROR", "msg": "boom", "trace_id": traceID, "span_id": spanID, "user": "ann"},
+			wantBody:     "boom",
+			wantSeverity: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+			wantTime:     time.Date(2024, 1, 5, 12, 0, 0, 5e8, time.UTC),
+			wantTrace:    true,
+			wantAttrs:    1,
+		},
+		{
+			name:   "custom keys",
+			fields: map[string]interface{}{"@t": int64(1704456000000), "sev": "warning", "text": "slow", "dd.trace_id": traceID, "dd.span_id": spanID},
+			keys: LogKeyMapping{
+				Timestamp: []string{"@t"}, Severity: []string{"sev"}, Message: []string{"text"},
+				TraceID: []string{"dd.trace_id"}, SpanID: []string{"dd.span_id"},
+			},
+			wantBody:     "slow",
+			wantSeverity: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+			wantTime:     time.UnixMilli(1704456000000),
+			wantTrace:    true,
+		},
+		{
+			name:      "malformed ids stay attributes",
+			fields:    map[string]interface{}{"msg": "x", "trace_id": "not-hex", "span_id": "00000000000000000000000000000000"},
+			wantBody:  "x",
+			wantAttrs: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &logspb.LogRecord{}
+			structuredLogRecord(tt.fields, tt.keys.withDefaults(), record)
+
+			if got := record.GetBody().GetStringValue(); got != tt.wantBody {
+				t.Errorf("Body = %q, want %q", got, tt.wantBody)
+			}
+			if record.SeverityNumber != tt.wantSeverity {
+				t.Errorf("SeverityNumber = %v, want %v", record.SeverityNumber, tt.wantSeverity)
+			}
+			if !tt.wantTime.IsZero() && record.TimeUnixNano != uint64(tt.wantTime.UnixNano()) {
+				t.Errorf("TimeUnixNano = %d, want %d", record.TimeUnixNano, tt.wantTime.UnixNano())
+			}
+			if tt.wantTrace {
+				if hex.EncodeToString(record.TraceId) != traceID || hex.EncodeToString(record.SpanId) != spanID {
+					t.Errorf("trace/span = %x/%x, want %s/%s", record.TraceId, record.SpanId, traceID, spanID)
+				}
+			} else if record.TraceId != nil || record.SpanId != nil {
+				t.Errorf("trace/span = %x/%x, want none", record.TraceId, record.SpanId)
+			}
+			if len(record.Attributes) != tt.wantAttrs {
+				t.Errorf("Attributes = %v, want %d", record.Attributes, tt.wantAttrs)
+			}
+		})
+	}
+}
+
+func TestTailerRotationAndTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	write := func(flag int, data string) {
+		t.Helper()
+		f, err := os.OpenFile(path, flag|os.O_WRONLY|os.O_CREATE, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(data)
+		f.Close()
+	}
+	poll := func(tl *tailer, want ...string) {
+		t.Helper()
+		lines, err := tl.poll()
+		if err != nil {
+			t.Fatalf("poll() error = %v", err)
+		}
+		if len(lines) != len(want) || (len(want) > 0 && !reflect.DeepEqual(lines, want)) {
+			t.Fatalf("poll() = %q, want %q", lines, want)
+		}
+	}
+
+	write(os.O_TRUNC, "existing\n")
+	tl := newTailer(TailFile{Path: path})
+	defer tl.close()
+	poll(tl) // starts at the end of an existing file
+
+	write(os.O_APPEND, "one\ntw")
+	poll(tl, "one")
+	write(os.O_APPEND, "o\r\n\n")
+	poll(tl, "two")
+
+	// Rotation: the old file is renamed and a new one created at the path.
+	write(os.O_APPEND, "last before rotate")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	write(os.O_TRUNC, "after rotate\n")
+	poll(tl, "last before rotate", "after rotate")
+
+	// Truncation in place (copytruncate).
+	write(os.O_TRUNC, "fresh\n")
+	poll(tl, "fresh")
+
+	if os.Remove(path) != nil {
+		t.Fatal("remove failed")
+	}
+	poll(tl)
+
+	if got := newTailer(TailFile{Path: path}).config.ServiceName; got != "app" {
+		t.Errorf("default ServiceName = %q, want 'app'", got)
+	}
+}
+
+func TestTailerDrainsRotatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tl := newTailer(TailFile{Path: path})
+	defer tl.close()
+	if _, err := tl.poll(); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+
+	// More than one poll's worth is left in the file when it is rotated.
+	line := strings.Repeat("x", 99) + "\n"
+	want := 2*maxTailRead/len(line) + 1
+	if err := os.WriteFile(path, []byte(strings.Repeat(line, want-1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("after rotate\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for polls := 0; polls < 5; polls++ {
+		more, err := tl.poll()
+		if err != nil {
+			t.Fatalf("poll() error = %v", err)
+		}
+		lines = append(lines, more...)
+	}
+	if len(lines) != want || lines[len(lines)-1] != "after rotate" {
+		t.Errorf("poll() returned %d lines ending %q, want %d ending with the new file", len(lines), lines[len(lines)-1], want)
+	}
+}
+
+func TestFileTailerExports(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.log")
+	if err := os.WriteFile(path, []byte(
+		`{"time":"2024-01-05T12:00:00Z","level":"error","msg":"checkout failed","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"}`+"\n"+
+			"plain text line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewOTLPReceiver(DefaultConfig())
+	ft := newFileTailer(r, TailConfig{Files: []TailFile{{Path: path, ServiceName: "api", FromStart: true}}})
+	ft.pollAll()
+	defer ft.tailers[0].close()
+
+	logs := r.GetLogs()
+	if len(logs) != 2 {
+		t.Fatalf("GetLogs() returned %d records, want 2", len(logs))
+	}
+	l := logs[0]
+	if l.Body != "checkout failed" || l.Severity != models.SeverityError {
+		t.Errorf("record = %v/%q, want 'checkout failed'/error", l.Body, l.Severity)
+	}
+	if l.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || l.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("trace/span = %q/%q, want IDs from the line", l.TraceID, l.SpanID)
+	}
+	if l.Resource.ServiceName != "api" {
+		t.Errorf("ServiceName = %q, want 'api'", l.Resource.ServiceName)
+	}
+	if got := attributeValue(l.Attributes, attrLogFilePath); got != path {
+		t.Errorf("log.file.path = %v, want %q", got, path)
+	}
+	if logs[1].Body != "plain text line" || logs[1].Severity != models.SeverityUnspecified {
+		t.Errorf("plain record = %v/%q", logs[1].Body, logs[1].Severity)
+	}
+}
+
+func TestParseLogTimestamp(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   time.Time
+		wantOK bool
+	}{
+		{"rfc3339", "2024-01-05T12:00:00.5Z", time.Date(2024, 1, 5, 12, 0, 0, 5e8, time.UTC), true},
+		{"seconds", int64(1700000000), time.Unix(1700000000, 0), true},
+		{"milliseconds", int64(1700000000123), time.Unix(1700000000, 123e6), true},
+		{"microseconds", int64(1700000000123456), time.Unix(1700000000, 123456e3), true},
+		{"nanoseconds", int64(1700000000123456789), time.Unix(1700000000, 123456789), true},
+		{"nanoseconds string", "1700000000123456789", time.Unix(1700000000, 123456789), true},
+		{"fractional seconds", 1700000000.5, time.Unix(1700000000, 5e8), true},
+		{"fractional seconds string", "1700000000.25", time.Unix(1700000000, 25e7), true},
+		{"not a time", "yesterday", time.Time{}, false},
+		{"unsupported type", true, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseLogTimestamp(tt.value)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("parseLogTimestamp(%v) = %v, %t; want %v, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestStartExportsTailedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"127.0.0.1:0"}
+	config.DisableHTTP = true
+	config.Tail = TailConfig{Files: []TailFile{{Path: path, FromStart: true}}}
+	r := NewOTLPReceiver(config)
+
+	// The first poll exports during Start, which must not wait on itself.
+	started := make(chan error, 1)
+	go func() { started <- r.Start() }()
+	select {
+	case err := <-started:
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() did not return with a tailed file to export")
+	}
+	defer r.Stop()
+
+	if got := len(r.GetLogs()); got != 1 {
+		t.Errorf("GetLogs() returned %d records, want 1", got)
+	}
+}
+
+func TestFileTailerResumesAfterRestart(t *testing.T) {
+	for _, fromStart := range []bool{true, false} {
+		t.Run(fmt.Sprintf("from start %t", fromStart), func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "api.log")
+			if err := os.WriteFile(path, []byte("one\ntwo\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			config := DefaultConfig()
+			config.ListenAddresses = []string{"127.0.0.1:0"}
+			config.HTTPListenAddresses = []string{"127.0.0.1:0"}
+			config.Tail = TailConfig{Files: []TailFile{{Path: path, FromStart: fromStart}}, PollInterval: time.Hour}
+			r := NewOTLPReceiver(config)
+			if err := r.Start(); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer r.Stop()
+			before := len(r.GetLogs())
+
+			// Written after the last poll; the restart must pick it up once.
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString("three\n")
+			f.Close()
+
+			settings := r.GetRuntimeConfig()
+			settings.HTTPEnabled = false
+			if err := r.Reconfigure(settings); err != nil {
+				t.Fatalf("Reconfigure() error = %v", err)
+			}
+
+			var bodies []string
+			for _, l := range r.GetLogs()[before:] {
+				bodies = append(bodies, fmt.Sprint(l.Body))
+			}
+			if !reflect.DeepEqual(bodies, []string{"three"}) {
+				t.Errorf("lines read by the restart = %q, want only \"three\"", bodies)
+			}
+		})
+	}
+}
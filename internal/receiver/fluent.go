@@ -0,0 +1,497 @@
+package receiver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/peer"
+)
+
+// ForwardConfig configures the Fluent Forward protocol listener.
+type ForwardConfig struct {
+	ListenAddress string `yaml:"listen_address"` // "host:port" or "unix:///path" such as ":24224"; disabled when empty
+}
+
+const (
+	// maxForwardChunk bounds a PackedForward chunk, before and after decompression.
+	maxForwardChunk = 16 * 1024 * 1024
+
+	// fluentEventTimeExt is the msgpack extension type of a Forward EventTime.
+	fluentEventTimeExt = 0
+
+	// maxFluentDepth bounds map and array nesting in records and options so
+	// hostile payloads cannot exhaust the stack.
+	maxFluentDepth = 64
+)
+
+var errFluentTooDeep = errors.New("values nested too deeply")
+
+// Record keys written by the Docker fluentd logging driver.
+const (
+	fluentKeyContainerID   = "container_id"
+	fluentKeyContainerName = "container_name"
+	fluentKeySource        = "source"
+	fluentKeyLog           = "log"
+)
+
+// Resource and log attribute keys for Forward entries.
+const (
+	attrFluentTag     = "fluent.tag"
+	attrContainerName = "container.name"
+	attrLogIOStream   = "log.iostream"
+)
+
+// fluentMessageKeys are checked in order for the log line of a record.
+var fluentMessageKeys = []string{fluentKeyLog, "message", "msg"}
+
+// fluentLevelKeys are checked in order for a record's severity.
+var fluentLevelKeys = []string{"level", "severity", "log.level"}
+
+// fluentEntry is one event of a Forward message.
+type fluentEntry struct {
+	time   time.Time
+	record map[string]interface{}
+}
+
+// fluentMessage is a decoded Forward message in any of its modes.
+type fluentMessage struct {
+	tag     string
+	entries []fluentEntry
+	chunk   string // acknowledgement ID requested by the sender
+}
+
+// decodeFluentMessage reads one Message, Forward, PackedForward or
+// CompressedPackedForward mode message.
+func decodeFluentMessage(dec *msgpack.Decoder) (fluentMessage, error) {
+	var msg fluentMessage
+
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return msg, err
+	}
+	if n < 2 || n > 4 {
+		return msg, fmt.Errorf("forward message has %d elements, want 2-4", n)
+	}
+	if msg.tag, err = dec.DecodeString(); err != nil {
+		return msg, fmt.Errorf("failed to decode tag: %w", err)
+	}
+
+	code, err := dec.PeekCode()
+	if err != nil {
+		return msg, err
+	}
+	var packed []byte
+	consumed := 2
+	switch {
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		// Forward mode: [tag, [[time, record], ...], option]
+		count, err := dec.DecodeArrayLen()
+		if err != nil {
+			return msg, err
+		}
+		for i := 0; i < count; i++ {
+			entry, err := decodeFluentEntry(dec)
+			if err != nil {
+				return msg, err
+			}
+			msg.entries = append(msg.entries, entry)
+		}
+	case msgpcode.IsBin(code) || msgpcode.IsString(code):
+		// PackedForward mode: [tag, <msgpack stream of entries>, option]
+		size, err := dec.DecodeBytesLen()
+		if err != nil {
+			return msg, err
+		}
+		if size < 0 || size > maxForwardChunk {
+			return msg, fmt.Errorf("packed entries of %d bytes exceed %d", size, maxForwardChunk)
+		}
+		packed = make([]byte, size)
+		if err := dec.ReadFull(packed); err != nil {
+			return msg, err
+		}
+	default:
+		// Message mode: [tag, time, record, option]
+		entry, err := decodeFluentEntryFields(dec)
+		if err != nil {
+			return msg, err
+		}
+		msg.entries = append(msg.entries, entry)
+		consumed = 3
+	}
+
+	var compressed string
+	if n > consumed {
+		option, err := decodeFluentMap(dec, 1)
+		if err != nil {
+			return msg, fmt.Errorf("failed to decode option: %w", err)
+		}
+		msg.chunk, _ = option["chunk"].(string)
+		compressed, _ = option["compressed"].(string)
+	}
+
+	if packed != nil {
+		if msg.entries, err = decodePackedEntries(packed, compressed); err != nil {
+			return msg, err
+		}
+	}
+	return msg, nil
+}
+
+// decodePackedEntries decodes the entry stream of a PackedForward message,
+// decompressing it first for CompressedPackedForward.
+func decodePackedEntries(packed []byte, compressed string) ([]fluentEntry, error) {
+	var reader io.Reader = bytes.NewReader(packed)
+	switch compressed {
+	case "", "text":
+	case "gzip":
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress entries: %w", err)
+		}
+		defer gz.Close()
+		reader = io.LimitReader(gz, maxForwardChunk)
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compressed)
+	}
+
+	dec := msgpack.NewDecoder(bufio.NewReader(reader))
+	var entries []fluentEntry
+	for {
+		if _, err := dec.PeekCode(); errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		entry, err := decodeFluentEntry(dec)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// decodeFluentEntry decodes a [time, record] entry.
+func decodeFluentEntry(dec *msgpack.Decoder) (fluentEntry, error) {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return fluentEntry{}, err
+	}
+	if n != 2 {
+		return fluentEntry{}, fmt.Errorf("forward entry has %d elements, want 2", n)
+	}
+	return decodeFluentEntryFields(dec)
+}
+
+// decodeFluentEntryFields decodes an event time followed by a record map.
+func decodeFluentEntryFields(dec *msgpack.Decoder) (fluentEntry, error) {
+	var entry fluentEntry
+
+	code, err := dec.PeekCode()
+	if err != nil {
+		return entry, err
+	}
+	switch {
+	case msgpcode.IsExt(code):
+		id, size, err := dec.DecodeExtHeader()
+		if err != nil {
+			return entry, err
+		}
+		if id != fluentEventTimeExt || size != 8 {
+			return entry, fmt.Errorf("unsupported time extension %d of %d bytes", id, size)
+		}
+		var b [8]byte
+		if err := dec.ReadFull(b[:]); err != nil {
+			return entry, err
+		}
+		entry.time = time.Unix(int64(binary.BigEndian.Uint32(b[:4])), int64(binary.BigEndian.Uint32(b[4:])))
+	case code == msgpcode.Float || code == msgpcode.Double:
+		seconds, err := dec.DecodeFloat64()
+		if err != nil {
+			return entry, err
+		}
+		entry.time = time.Unix(0, int64(seconds*1e9))
+	default:
+		seconds, err := dec.DecodeInt64()
+		if err != nil {
+			return entry, fmt.Errorf("failed to decode time: %w", err)
+		}
+		entry.time = time.Unix(seconds, 0)
+	}
+
+	if entry.record, err = decodeFluentMap(dec, 1); err != nil {
+		return entry, fmt.Errorf("failed to decode record: %w", err)
+	}
+	return entry, nil
+}
+
+// decodeFluentMap decodes a map with string keys at the given nesting depth,
+// like msgpack's DecodeMap but refusing values nested past maxFluentDepth.
+func decodeFluentMap(dec *msgpack.Decoder, depth int) (map[string]interface{}, error) {
+	if depth > maxFluentDepth {
+		return nil, errFluentTooDeep
+	}
+	n, err := dec.DecodeMapLen()
+	if err != nil || n == -1 {
+		return nil, err
+	}
+	// Sizes come from the sender; let the map grow as entries arrive.
+	m := make(map[string]interface{}, min(n, 64))
+	for i := 0; i < n; i++ {
+		key, err := dec.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+		if m[key], err = decodeFluentValue(dec, depth); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// decodeFluentValue decodes a value inside a map or array at depth, walking
+// nested maps and arrays itself and leaving scalars to msgpack.
+func decodeFluentValue(dec *msgpack.Decoder, depth int) (interface{}, error) {
+	code, err := dec.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msgpcode.IsFixedMap(code) || code == msgpcode.Map16 || code == msgpcode.Map32:
+		return decodeFluentMap(dec, depth+1)
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		if depth+1 > maxFluentDepth {
+			return nil, errFluentTooDeep
+		}
+		n, err := dec.DecodeArrayLen()
+		if err != nil || n == -1 {
+			return nil, err
+		}
+		values := make([]interface{}, 0, min(n, 64))
+		for i := 0; i < n; i++ {
+			v, err := decodeFluentValue(dec, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	default:
+		return dec.DecodeInterface()
+	}
+}
+
+// fluentToOTLP converts Forward entries into an OTLP logs request. Entries
+// are grouped into resources by tag and Docker container.
+func fluentToOTLP(msg fluentMessage, observed time.Time) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	scopes := make(map[[3]string]*logspb.ScopeLogs)
+
+	for _, entry := range msg.entries {
+		containerID, _ := entry.record[fluentKeyContainerID].(string)
+		containerName, _ := entry.record[fluentKeyContainerName].(string)
+		containerName = strings.TrimPrefix(containerName, "/")
+
+		key := [3]string{msg.tag, containerID, containerName}
+		sl, ok := scopes[key]
+		if !ok {
+			serviceName := containerName
+			if serviceName == "" {
+				serviceName = msg.tag
+			}
+			attrs := []*commonpb.KeyValue{stringKV(attrServiceName, serviceName), stringKV(attrFluentTag, msg.tag)}
+			if containerID != "" {
+				attrs = append(attrs, stringKV(attrContainerID, containerID))
+			}
+			if containerName != "" {
+				attrs = append(attrs, stringKV(attrContainerName, containerName))
+			}
+			sl = &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: "fluent.forward"}}
+			req.ResourceLogs = append(req.ResourceLogs, &logspb.ResourceLogs{
+				Resource:  &resourcepb.Resource{Attributes: attrs},
+				ScopeLogs: []*logspb.ScopeLogs{sl},
+			})
+			scopes[key] = sl
+		}
+		sl.LogRecords = append(sl.LogRecords, fluentLogRecord(entry, observed))
+	}
+	return req
+}
+
+// fluentLogRecord maps a record's message field to the body and its
+// remaining fields to attributes.
+func fluentLogRecord(entry fluentEntry, observed time.Time) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(entry.time.UnixNano()),
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+	}
+
+	consumed := map[string]bool{fluentKeyContainerID: true, fluentKeyContainerName: true}
+	for _, key := range fluentMessageKeys {
+		if s, ok := entry.record[key].(string); ok {
+			record.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: strings.TrimRight(s, "\r\n")}}
+			consumed[key] = true
+			break
+		}
+	}
+	for _, key := range fluentLevelKeys {
+		if s, ok := entry.record[key].(string); ok {
+			record.SeverityText = s
+			record.SeverityNumber = severityFromText(s)
+			consumed[key] = true
+			break
+		}
+	}
+	if source, ok := entry.record[fluentKeySource].(string); ok {
+		record.Attributes = append(record.Attributes, stringKV(attrLogIOStream, source))
+		consumed[fluentKeySource] = true
+	}
+
+	keys := make([]string, 0, len(entry.record))
+	for k := range entry.record {
+		if !consumed[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	if record.Body == nil {
+		// Without a message field the whole record is the body.
+		body := &commonpb.KeyValueList{}
+		for _, k := range keys {
+			body.Values = append(body.Values, &commonpb.KeyValue{Key: k, Value: anyValue(entry.record[k])})
+		}
+		record.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: body}}
+		return record
+	}
+	for _, k := range keys {
+		record.Attributes = append(record.Attributes, &commonpb.KeyValue{Key: k, Value: anyValue(entry.record[k])})
+	}
+	return record
+}
+
+// forwardServer accepts Fluent Forward connections.
+type forwardServer struct {
+	receiver *OTLPReceiver
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+
+	wg sync.WaitGroup
+}
+
+// newForwardServer binds the configured listener.
+func newForwardServer(r *OTLPReceiver, config ForwardConfig) (*forwardServer, error) {
+	l, err := listenOn(config.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &forwardServer{receiver: r, listener: l, conns: make(map[net.Conn]struct{})}, nil
+}
+
+// start launches the accept loop.
+func (s *forwardServer) start() {
+	log.Printf("[Phosphor] Fluent Forward receiver listening on %s", listenerAddress(s.listener))
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.acceptLoop()
+	}()
+}
+
+// stop closes the listener and open connections and waits for readers to exit.
+func (s *forwardServer) stop() {
+	closeListeners([]net.Listener{s.listener})
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *forwardServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.receiver.recordError("Fluent Forward accept error: %v", err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+			s.receiver.clients.disconnect(transportFluent, peerAddress(conn.RemoteAddr()))
+		}()
+	}
+}
+
+// serveConn decodes messages until the connection closes, acknowledging
+// chunks once their entries have been stored.
+func (s *forwardServer) serveConn(conn net.Conn) {
+	dec := msgpack.NewDecoder(bufio.NewReader(conn))
+	enc := msgpack.NewEncoder(conn)
+	for {
+		msg, err := decodeFluentMessage(dec)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				s.receiver.recordRejection(models.SignalTypeLog, "", fmt.Errorf("fluent forward: %w", err))
+				s.receiver.recordError("Fluent Forward connection from %s closed: %v", peerAddress(conn.RemoteAddr()), err)
+			}
+			return
+		}
+
+		ctx := withTransport(context.Background(), transportFluent)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: conn.RemoteAddr()})
+		if _, err := s.receiver.logsService.Export(ctx, fluentToOTLP(msg, time.Now())); err != nil {
+			// Leave the chunk unacknowledged so the sender retries it.
+			s.receiver.recordError("Failed to export Fluent Forward entries from %s: %v", peerAddress(conn.RemoteAddr()), err)
+			continue
+		}
+		if msg.chunk != "" {
+			if err := enc.Encode(map[string]string{"ack": msg.chunk}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ForwardAddress returns the address the Fluent Forward listener is bound to,
+// or an empty string when it is disabled.
+func (r *OTLPReceiver) ForwardAddress() string {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.forward == nil {
+		return ""
+	}
+	return listenerAddress(r.forward.listener)
+}
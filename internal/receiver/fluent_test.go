@@ -0,0 +1,213 @@
+package receiver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// fluentEventTime encodes t as a Forward EventTime extension.
+func fluentEventTime(t time.Time) msgpack.RawMessage {
+	b := []byte{0xd7, fluentEventTimeExt, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[2:6], uint32(t.Unix()))
+	binary.BigEndian.PutUint32(b[6:], uint32(t.Nanosecond()))
+	return b
+}
+
+func encodeMsgpack(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := msgpack.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDecodeFluentMessage(t *testing.T) {
+	ts := time.Unix(1700000000, 123456789)
+	record := map[string]interface{}{"log": "hello\n", "source": "stdout"}
+	entry := []interface{}{fluentEventTime(ts), record}
+
+	var packed []byte
+	for i := 0; i < 2; i++ {
+		packed = append(packed, encodeMsgpack(t, entry)...)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(packed)
+	gz.Close()
+
+	tests := []struct {
+		name        string
+		message     []interface{}
+		wantEntries int
+		wantChunk   string
+	}{
+		{"message", []interface{}{"app", fluentEventTime(ts), record}, 1, ""},
+		{"message with integer time", []interface{}{"app", ts.Unix(), record, map[string]interface{}{"chunk": "c1"}}, 1, "c1"},
+		{"forward", []interface{}{"app", []interface{}{entry, entry, entry}}, 3, ""},
+		{"packed forward", []interface{}{"app", packed, map[string]interface{}{"size": 2}}, 2, ""},
+		{"compressed packed forward", []interface{}{"app", compressed.Bytes(), map[string]interface{}{"compressed": "gzip", "chunk": "c2"}}, 2, "c2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := msgpack.NewDecoder(bytes.NewReader(encodeMsgpack(t, tt.message)))
+			msg, err := decodeFluentMessage(dec)
+			if err != nil {
+				t.Fatalf("decodeFluentMessage() error = %v", err)
+			}
+			if msg.tag != "app" || len(msg.entries) != tt.wantEntries || msg.chunk != tt.wantChunk {
+				t.Fatalf("message = %q with %d entries, chunk %q; want %d entries, chunk %q",
+					msg.tag, len(msg.entries), msg.chunk, tt.wantEntries, tt.wantChunk)
+			}
+			if msg.entries[0].record["log"] != "hello\n" {
+				t.Errorf("record = %v", msg.entries[0].record)
+			}
+		})
+	}
+
+	dec := msgpack.NewDecoder(bytes.NewReader(encodeMsgpack(t, []interface{}{"app", fluentEventTime(ts), record})))
+	msg, _ := decodeFluentMessage(dec)
+	if !msg.entries[0].time.Equal(ts) {
+		t.Errorf("EventTime = %v, want %v", msg.entries[0].time, ts)
+	}
+
+	for _, bad := range [][]interface{}{
+		{"app"},
+		{"app", "not msgpack \xc1", map[string]interface{}{}},
+		{"app", packed, map[string]interface{}{"compressed": "zstd"}},
+	} {
+		dec := msgpack.NewDecoder(bytes.NewReader(encodeMsgpack(t, bad)))
+		if _, err := decodeFluentMessage(dec); err == nil {
+			t.Errorf("decodeFluentMessage(%v) error = nil, want error", bad[1:])
+		}
+	}
+}
+
+func TestForwardDockerLogs(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(dir, "otlp.sock")}
+	config.HTTPListenAddresses = []string{"unix://" + filepath.Join(dir, "http.sock")}
+	sock := filepath.Join(dir, "fluent.sock")
+	config.Forward = ForwardConfig{ListenAddress: "unix://" + sock}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	if got := r.ForwardAddress(); got != "unix://"+sock {
+		t.Errorf("ForwardAddress() = %q, want %q", got, "unix://"+sock)
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The shape written by `docker run --log-driver=fluentd` with ack enabled.
+	ts := time.Unix(1700000000, 0)
+	conn.Write(encodeMsgpack(t, []interface{}{
+		"docker.3f4e5a6b7c8d",
+		fluentEventTime(ts),
+		map[string]interface{}{
+			"container_id":   "3f4e5a6b7c8d9e0f",
+			"container_name": "/checkout",
+			"source":         "stderr",
+			"log":            "payment failed\n",
+			"level":          "error",
+			"attempt":        int64(3),
+		},
+		map[string]interface{}{"chunk": "abc"},
+	}))
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var ack map[string]interface{}
+	if err := msgpack.NewDecoder(bufio.NewReader(conn)).Decode(&ack); err != nil {
+		t.Fatalf("reading ack: %v", err)
+	}
+	if ack["ack"] != "abc" {
+		t.Errorf("ack = %v, want chunk id", ack)
+	}
+
+	logs := r.GetLogs()
+	if len(logs) != 1 {
+		t.Fatalf("GetLogs() returned %d records, want 1", len(logs))
+	}
+	l := logs[0]
+	if l.Body != "payment failed" {
+		t.Errorf("Body = %v, want log field without newline", l.Body)
+	}
+	if l.Resource.ServiceName != "checkout" {
+		t.Errorf("ServiceName = %q, want container name", l.Resource.ServiceName)
+	}
+	if got := attributeValue(l.Resource.Attributes, attrContainerID); got != "3f4e5a6b7c8d9e0f" {
+		t.Errorf("container.id = %v", got)
+	}
+	if got := attributeValue(l.Resource.Attributes, attrFluentTag); got != "docker.3f4e5a6b7c8d" {
+		t.Errorf("fluent.tag = %v", got)
+	}
+	if l.Severity != models.SeverityError || l.SeverityText != "error" {
+		t.Errorf("severity = %q/%q, want error", l.Severity, l.SeverityText)
+	}
+	if got := attributeValue(l.Attributes, attrLogIOStream); got != "stderr" {
+		t.Errorf("log.iostream = %v, want stderr", got)
+	}
+	if got := attributeValue(l.Attributes, "attempt"); got != int64(3) {
+		t.Errorf("attempt = %v, want 3", got)
+	}
+	if !l.Timestamp.Equal(ts) {
+		t.Errorf("Timestamp = %v, want %v", l.Timestamp, ts)
+	}
+}
+
+// nestedFluentRecord encodes {"a": [[...[nil]...]]} with arrays nested depth deep.
+func nestedFluentRecord(depth int) []byte {
+	b := []byte{0x81, 0xa1, 'a'}
+	b = append(b, bytes.Repeat([]byte{0x91}, depth)...)
+	return append(b, 0xc0)
+}
+
+func TestDecodeFluentNesting(t *testing.T) {
+	message := func(record []byte) []byte {
+		return append([]byte{0x93, 0xa3, 'a', 'p', 'p', 0x01}, record...)
+	}
+	compressed := func(record []byte) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(append([]byte{0x92, 0x01}, record...))
+		gz.Close()
+		return encodeMsgpack(t, []interface{}{"app", buf.Bytes(), map[string]interface{}{"compressed": "gzip"}})
+	}
+
+	tests := []struct {
+		name    string
+		body    []byte
+		wantErr error
+	}{
+		{"at the limit", message(nestedFluentRecord(maxFluentDepth - 1)), nil},
+		{"past the limit", message(nestedFluentRecord(maxFluentDepth)), errFluentTooDeep},
+		{"hostile depth", message(nestedFluentRecord(20_000_000)), errFluentTooDeep},
+		{"hostile depth compressed", compressed(nestedFluentRecord(20_000_000)), errFluentTooDeep},
+		{"nested option", append([]byte{0x93, 0xa3, 'a', 'p', 'p', 0x90}, nestedFluentRecord(maxFluentDepth)...), errFluentTooDeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := msgpack.NewDecoder(bytes.NewReader(tt.body))
+			if _, err := decodeFluentMessage(dec); !errors.Is(err, tt.wantErr) {
+				t.Errorf("decodeFluentMessage() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,20 +1,29 @@
-// Package receiver implements the OTLP gRPC receiver for traces, metrics, and logs.
+// Package receiver implements the OTLP gRPC and HTTP receivers for traces, metrics, logs, and profiles.
 package receiver
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"sync"
+	"time"
 
+	"github.com/jaegertracing/jaeger-idl/proto-gen/api_v2"
 	"github.com/phosphor-project/phosphor/pkg/buffer"
+	"github.com/phosphor-project/phosphor/pkg/eventbus"
 	"github.com/phosphor-project/phosphor/pkg/models"
 	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
 	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
 )
 
 // EventCallback is called when new telemetry data is received.
@@ -22,43 +31,185 @@ type EventCallback func(event models.TelemetryEvent)
 
 // Config holds the configuration for the OTLP receiver.
 type Config struct {
-	Port           int // Port to listen on (default: 4317)
-	TraceCapacity  int // Ring buffer capacity for traces (default: 1000)
-	MetricCapacity int // Ring buffer capacity for metrics (default: 1000)
-	LogCapacity    int // Ring buffer capacity for logs (default: 1000)
+	Port     int // Port to listen on (default: 4317)
+	HTTPPort int // Port for OTLP/HTTP (default: 4318)
+
+	// Explicit listen addresses ("host:port", "tcp://host:port" or "unix:///path").
+	// When empty, the receivers listen on all interfaces at Port and HTTPPort.
+	ListenAddresses     []string
+	HTTPListenAddresses []string
+
+	DisableHTTP bool // Serve only gRPC; also turns off the Zipkin, Jaeger and remote write endpoints
+
+	TraceCapacity   int // Ring buffer capacity for traces (default: 1000)
+	MetricCapacity  int // Ring buffer capacity for metrics (default: 1000)
+	LogCapacity     int // Ring buffer capacity for logs (default: 1000)
+	ProfileCapacity int // Ring buffer capacity for profiles (default: 100)
+
+	TLS  TLSConfig  // Transport security for the gRPC and HTTP listeners
+	Auth AuthConfig // Optional static credentials required from exporters
+
+	Admission AdmissionConfig // Backpressure limits for ingestion
+
+	Scrape ScrapeConfig // Prometheus endpoints to pull metrics from
+	StatsD StatsDConfig // StatsD/DogStatsD UDP listener
+	Syslog SyslogConfig // RFC 5424/3164 syslog listeners
+
+	Forward ForwardConfig // Fluent Forward (fluentd/Fluent Bit/Docker) listener
+	Tail    TailConfig    // Local log files to follow
+
+	Faults FaultConfig // Failures injected into Export handlers (disabled by default)
+
+	SelfTelemetry SelfTelemetryConfig // Metrics about Phosphor's own pipeline
+
+	QueryAddress    string // Serve stored telemetry as JSON on this address, e.g. "127.0.0.1:4319"; disabled when empty
+	QueryAllowClear bool   // Let DELETE /api/telemetry on the query API clear every buffer
+
+	Upstream UpstreamConfig // OTLP endpoints that receive a copy of every export
+
+	EventQueueSize int // Per-subscriber event queue length (default: 4096)
+
+	PortFallback bool          // Listen on a free port when Port or HTTPPort is taken
+	DrainTimeout time.Duration // How long Stop waits for in-flight exports (default: 5s)
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		Port:           4317,
-		TraceCapacity:  1000,
-		MetricCapacity: 1000,
-		LogCapacity:    1000,
+		Port:            4317,
+		HTTPPort:        4318,
+		TraceCapacity:   1000,
+		MetricCapacity:  1000,
+		LogCapacity:     1000,
+		ProfileCapacity: 100,
+		EventQueueSize:  eventbus.DefaultQueueSize,
+		PortFallback:    true,
+		DrainTimeout:    5 * time.Second,
 	}
 }
 
+// Validate reports the first setting that would keep the receiver from
+// starting or that start would otherwise silently ignore.
+func (c Config) Validate() error {
+	if err := runtimeConfig(c).validate(); err != nil {
+		return err
+	}
+	if c.TLS.Enabled && !c.TLS.SelfSigned && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		return fmt.Errorf("TLS needs a certificate and key file, or self-signed enabled")
+	}
+	if err := c.Faults.validate(); err != nil {
+		return fmt.Errorf("faults: %w", err)
+	}
+	for _, target := range c.Scrape.Targets {
+		if target.URL == "" {
+			return fmt.Errorf("scrape target without a URL")
+		}
+	}
+	for _, file := range c.Tail.Files {
+		if file.Path == "" {
+			return fmt.Errorf("tailed file without a path")
+		}
+		switch file.Format {
+		case LogFormatAuto, LogFormatJSON, LogFormatLogfmt, LogFormatText:
+		default:
+			return fmt.Errorf("tailed file %s: unknown format %q", file.Path, file.Format)
+		}
+	}
+	for _, endpoint := range c.Upstream.Endpoints {
+		if endpoint.Endpoint == "" {
+			return fmt.Errorf("upstream without an endpoint")
+		}
+		switch endpoint.Protocol {
+		case "", UpstreamGRPC, UpstreamHTTP:
+		default:
+			return fmt.Errorf("upstream %s: unsupported protocol %q", endpoint.Endpoint, endpoint.Protocol)
+		}
+	}
+	return nil
+}
+
 // OTLPReceiver manages the OTLP gRPC server for all signal types.
 type OTLPReceiver struct {
 	config Config
 
 	// Ring buffers for storing telemetry
-	traces  *buffer.RingBuffer[models.Span]
-	metrics *buffer.RingBuffer[models.Metric]
-	logs    *buffer.RingBuffer[models.LogRecord]
+	traces   *buffer.RingBuffer[models.Span]
+	metrics  *buffer.RingBuffer[models.Metric]
+	logs     *buffer.RingBuffer[models.LogRecord]
+	profiles *buffer.RingBuffer[models.Profile]
 
-	// Event callbacks for real-time streaming
-	callbacks   []EventCallback
-	callbacksMu sync.RWMutex
+	// Recently rejected items and why
+	rejections *buffer.RingBuffer[models.Rejection]
+
+	// Event bus for real-time streaming
+	events *eventbus.Bus[models.TelemetryEvent]
 
 	// gRPC server components
-	server   *grpc.Server
-	listener net.Listener
+	server    *grpc.Server
+	listeners []net.Listener
+
+	// OTLP/HTTP server
+	httpServer    *http.Server
+	httpListeners []net.Listener
+
+	// TLS settings shared by both listeners (nil when plaintext)
+	tlsConfig *tls.Config
 
 	// Service handlers
-	traceService   *traceServiceHandler
-	metricsService *metricsServiceHandler
-	logsService    *logsServiceHandler
+	traceService    *traceServiceHandler
+	metricsService  *metricsServiceHandler
+	logsService     *logsServiceHandler
+	profilesService *profilesServiceHandler
+
+	// Backpressure for ingestion
+	admission *admissionController
+
+	// Per-client export activity
+	clients *clientTracker
+
+	// Prometheus scrape loops (nil when no targets are configured)
+	scraper *scraper
+
+	// StatsD listener (nil when disabled)
+	statsd *statsdServer
+
+	// Syslog listeners (nil when disabled)
+	syslog *syslogServer
+
+	// Fluent Forward listener (nil when disabled)
+	forward *forwardServer
+
+	// Log file followers (nil when no files are configured), and the last
+	// stopped ones, whose positions the next start resumes from
+	tailer        *fileTailer
+	stoppedTailer *fileTailer
+
+	// Upstream forwarding (nil when no endpoints are configured)
+	upstreams *forwarder
+
+	// Internal instruments, and their reporting (nil when disabled)
+	telemetry *selfTelemetry
+	self      *selfReporter
+
+	// JSON query API (nil when disabled)
+	query *queryServer
+
+	// Guards the servers, listeners and components above, which start and
+	// Stop replace while getters and in-flight exports read them
+	runMu sync.RWMutex
+
+	// Serializes Start, Stop and Reconfigure and guards the settings they change
+	configMu sync.Mutex
+
+	// Lifecycle state, guarded by stateMu, and its change notifications
+	status    ReceiverStatus
+	reasons   []string
+	stateMu   sync.Mutex
+	lifecycle *eventbus.Bus[ReceiverStatus]
+
+	// Fault injection and the connections it can reset
+	faults *faultInjector
+	conns  *connRegistry
 
 	// Statistics
 	stats   ReceiverStats
@@ -67,10 +218,21 @@ type OTLPReceiver struct {
 
 // ReceiverStats tracks telemetry reception statistics.
 type ReceiverStats struct {
-	TracesReceived  uint64 `json:"tracesReceived"`
-	MetricsReceived uint64 `json:"metricsReceived"`
-	LogsReceived    uint64 `json:"logsReceived"`
-	Errors          uint64 `json:"errors"`
+	TracesReceived   uint64 `json:"tracesReceived"`
+	MetricsReceived  uint64 `json:"metricsReceived"`
+	LogsReceived     uint64 `json:"logsReceived"`
+	ProfilesReceived uint64 `json:"profilesReceived"`
+	Errors           uint64 `json:"errors"`
+	AuthFailures     uint64 `json:"authFailures"`
+	TracesRejected   uint64 `json:"tracesRejected"`
+	MetricsRejected  uint64 `json:"metricsRejected"` // Data points
+	LogsRejected     uint64 `json:"logsRejected"`
+	ProfilesRejected uint64 `json:"profilesRejected"`
+	Throttled        uint64 `json:"throttled"`      // Exports refused by admission control
+	FaultsInjected   uint64 `json:"faultsInjected"` // Faults applied by fault injection
+	InFlightItems    int    `json:"inFlightItems"`  // Items currently being processed
+
+	Subscribers []eventbus.SubscriberStats `json:"subscribers"` // Event delivery per subscriber
 }
 
 // traceServiceHandler implements the OTLP TraceService.
@@ -91,11 +253,20 @@ type logsServiceHandler struct {
 	receiver *OTLPReceiver
 }
 
+// profilesServiceHandler implements the development OTLP ProfilesService.
+type profilesServiceHandler struct {
+	colprofilespb.UnimplementedProfilesServiceServer
+	receiver *OTLPReceiver
+}
+
 // NewOTLPReceiver creates a new OTLP receiver with the given configuration.
 func NewOTLPReceiver(config Config) *OTLPReceiver {
 	if config.Port == 0 {
 		config.Port = 4317
 	}
+	if config.HTTPPort == 0 {
+		config.HTTPPort = 4318
+	}
 	if config.TraceCapacity == 0 {
 		config.TraceCapacity = 1000
 	}
@@ -105,102 +276,364 @@ func NewOTLPReceiver(config Config) *OTLPReceiver {
 	if config.LogCapacity == 0 {
 		config.LogCapacity = 1000
 	}
+	if config.ProfileCapacity == 0 {
+		config.ProfileCapacity = 100
+	}
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = 5 * time.Second
+	}
 
 	r := &OTLPReceiver{
-		config:    config,
-		traces:    buffer.NewRingBuffer[models.Span](config.TraceCapacity),
-		metrics:   buffer.NewRingBuffer[models.Metric](config.MetricCapacity),
-		logs:      buffer.NewRingBuffer[models.LogRecord](config.LogCapacity),
-		callbacks: make([]EventCallback, 0),
+		config:     config,
+		traces:     buffer.NewRingBuffer[models.Span](config.TraceCapacity),
+		metrics:    buffer.NewRingBuffer[models.Metric](config.MetricCapacity),
+		logs:       buffer.NewRingBuffer[models.LogRecord](config.LogCapacity),
+		profiles:   buffer.NewRingBuffer[models.Profile](config.ProfileCapacity),
+		rejections: buffer.NewRingBuffer[models.Rejection](rejectionCapacity),
+		events:     eventbus.New[models.TelemetryEvent](config.EventQueueSize),
+		admission:  newAdmissionController(config.Admission),
+		clients:    newClientTracker(),
+		faults:     &faultInjector{config: config.Faults},
+		conns:      newConnRegistry(),
+		telemetry:  newSelfTelemetry(),
+		lifecycle:  eventbus.New[ReceiverStatus](16),
+		status:     ReceiverStatus{State: StateStopped, Since: time.Now()},
 	}
 
 	// Initialize service handlers
 	r.traceService = &traceServiceHandler{receiver: r}
 	r.metricsService = &metricsServiceHandler{receiver: r}
 	r.logsService = &logsServiceHandler{receiver: r}
+	r.profilesService = &profilesServiceHandler{receiver: r}
 
 	return r
 }
 
-// OnEvent registers a callback to be called when telemetry is received.
-func (r *OTLPReceiver) OnEvent(callback EventCallback) {
-	r.callbacksMu.Lock()
-	defer r.callbacksMu.Unlock()
-	r.callbacks = append(r.callbacks, callback)
+// OnEvent registers a named callback to be called when telemetry is received.
+// Events are delivered in order from a dedicated goroutine; if the callback
+// falls behind by more than Config.EventQueueSize events, newer events are dropped.
+// The returned function unsubscribes the callback.
+func (r *OTLPReceiver) OnEvent(name string, callback EventCallback) func() {
+	sub := r.events.Subscribe(name, callback)
+	return sub.Unsubscribe
 }
 
-// emitEvent sends an event to all registered callbacks.
+// emitEvent publishes an event to all subscribers without blocking.
 func (r *OTLPReceiver) emitEvent(event models.TelemetryEvent) {
-	r.callbacksMu.RLock()
-	callbacks := r.callbacks
-	r.callbacksMu.RUnlock()
+	r.events.Publish(event)
+}
 
-	for _, cb := range callbacks {
-		// Run callbacks in goroutines to avoid blocking
-		go cb(event)
+// start opens the listeners and launches every configured subsystem. Only
+// invalid configuration and the gRPC listeners are fatal; any other component
+// that cannot start degrades the receiver instead.
+func (r *OTLPReceiver) start() error {
+	// Components are launched only once runMu is released: launching can
+	// export, as the file tailer's first poll does, and exports read r.upstreams.
+	r.runMu.Lock()
+	launch, err := r.build()
+	r.runMu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, f := range launch {
+		f()
 	}
+	return nil
 }
 
-// Start begins listening for OTLP data on the configured port.
-func (r *OTLPReceiver) Start() error {
-	addr := fmt.Sprintf(":%d", r.config.Port)
-	listener, err := net.Listen("tcp", addr)
+// build opens the listeners and creates the configured subsystems, returning
+// the functions that launch them in order. It is called with runMu held.
+func (r *OTLPReceiver) build() ([]func(), error) {
+	tlsConfig, err := buildTLSConfig(r.config.TLS)
 	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+	r.tlsConfig = tlsConfig
+
+	if err := r.config.Faults.validate(); err != nil {
+		return nil, fmt.Errorf("invalid fault configuration: %w", err)
+	}
+
+	var scraper *scraper
+	if len(r.config.Scrape.Targets) > 0 {
+		if scraper, err = newScraper(r, r.config.Scrape); err != nil {
+			return nil, fmt.Errorf("invalid scrape configuration: %w", err)
+		}
 	}
-	r.listener = listener
 
-	r.server = grpc.NewServer(
+	var upstreams *forwarder
+	if len(r.config.Upstream.Endpoints) > 0 {
+		if upstreams, err = newForwarder(r.config.Upstream); err != nil {
+			return nil, fmt.Errorf("invalid upstream configuration: %w", err)
+		}
+	}
+
+	var launch []func()
+	var listeners []net.Listener
+	if len(r.config.ListenAddresses) > 0 {
+		listeners, err = listenAll(r.config.ListenAddresses)
+	} else {
+		listeners, err = r.listenPort("gRPC", r.config.Port)
+	}
+	if err != nil {
+		if upstreams != nil {
+			upstreams.close()
+		}
+		return nil, err
+	}
+	listeners = r.conns.track(listeners)
+	r.listeners = listeners
+
+	// Forwarding starts before any listener serves so no export is missed.
+	if upstreams != nil {
+		r.upstreams = upstreams
+		upstreams.start()
+	}
+
+	opts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(16 * 1024 * 1024), // 16MB max message size
-	)
+		grpc.ChainUnaryInterceptor(r.authUnaryInterceptor),
+		grpc.StatsHandler(&connStatsHandler{receiver: r}),
+		grpc.ForceServerCodecV2(serverCodec{}),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	r.server = grpc.NewServer(opts...)
 
 	// Register all OTLP services
 	coltracepb.RegisterTraceServiceServer(r.server, r.traceService)
 	colmetricspb.RegisterMetricsServiceServer(r.server, r.metricsService)
 	collogspb.RegisterLogsServiceServer(r.server, r.logsService)
+	colprofilespb.RegisterProfilesServiceServer(r.server, r.profilesService)
+
+	// Jaeger clients export to the same server via the api_v2 CollectorService
+	api_v2.RegisterCollectorServiceServer(r.server, &jaegerCollector{receiver: r})
 
 	// Enable reflection for debugging
 	reflection.Register(r.server)
 
-	log.Printf("[Phosphor] OTLP receiver listening on %s", addr)
+	for _, listener := range listeners {
+		log.Printf("[Phosphor] OTLP receiver listening on %s (tls=%t)", listenerAddress(listener), tlsConfig != nil)
+
+		go func(server *grpc.Server, l net.Listener) {
+			if err := server.Serve(l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				r.degrade("gRPC server error on %s: %v", listenerAddress(l), err)
+			}
+		}(r.server, listener)
+	}
+
+	if !r.config.DisableHTTP {
+		if err := r.startHTTP(); err != nil {
+			r.degrade("OTLP/HTTP disabled: %v", err)
+		}
+	}
 
-	go func() {
-		if err := r.server.Serve(listener); err != nil {
-			log.Printf("[Phosphor] gRPC server error: %v", err)
+	if r.config.StatsD.ListenAddress != "" {
+		statsd, err := newStatsDServer(r, r.config.StatsD)
+		if err != nil {
+			r.degrade("StatsD disabled: %v", err)
+		} else {
+			r.statsd = statsd
+			launch = append(launch, statsd.start)
 		}
-	}()
+	}
 
-	return nil
+	if r.config.Syslog.TCPAddress != "" || r.config.Syslog.UDPAddress != "" {
+		syslog, err := newSyslogServer(r, r.config.Syslog)
+		if err != nil {
+			r.degrade("Syslog disabled: %v", err)
+		} else {
+			r.syslog = syslog
+			launch = append(launch, syslog.start)
+		}
+	}
+
+	if r.config.Forward.ListenAddress != "" {
+		forward, err := newForwardServer(r, r.config.Forward)
+		if err != nil {
+			r.degrade("Fluent Forward disabled: %v", err)
+		} else {
+			r.forward = forward
+			launch = append(launch, forward.start)
+		}
+	}
+
+	if len(r.config.Tail.Files) > 0 {
+		r.tailer = newFileTailer(r, r.config.Tail)
+		r.tailer.resume(r.stoppedTailer)
+		launch = append(launch, r.tailer.start)
+	}
+	r.stoppedTailer = nil
+
+	if r.config.SelfTelemetry.enabled() {
+		self, err := newSelfReporter(r, r.config.SelfTelemetry)
+		if err != nil {
+			r.degrade("Internal metrics disabled: %v", err)
+		} else {
+			r.self = self
+			launch = append(launch, self.start)
+		}
+	}
+
+	if r.config.QueryAddress != "" {
+		query, err := newQueryServer(r, r.config.QueryAddress)
+		if err != nil {
+			r.degrade("Query API disabled: %v", err)
+		} else {
+			r.query = query
+			launch = append(launch, query.start)
+		}
+	}
+
+	if scraper != nil {
+		r.scraper = scraper
+		launch = append(launch, scraper.start)
+	}
+
+	return launch, nil
+}
+
+// Addresses returns the addresses the gRPC receiver is bound to.
+func (r *OTLPReceiver) Addresses() []string {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	addrs := make([]string, 0, len(r.listeners))
+	for _, l := range r.listeners {
+		addrs = append(addrs, listenerAddress(l))
+	}
+	return addrs
+}
+
+// HTTPAddresses returns the addresses the OTLP/HTTP receiver is bound to.
+func (r *OTLPReceiver) HTTPAddresses() []string {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	addrs := make([]string, 0, len(r.httpListeners))
+	for _, l := range r.httpListeners {
+		addrs = append(addrs, listenerAddress(l))
+	}
+	return addrs
 }
 
 // Stop gracefully shuts down the receiver.
 func (r *OTLPReceiver) Stop() {
-	if r.server != nil {
-		r.server.GracefulStop()
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+	r.stopLocked()
+}
+
+// stopLocked is Stop for callers that hold configMu. Components are detached
+// under runMu and stopped outside it, so exports that are still draining can
+// reach the upstreams, which are stopped last.
+func (r *OTLPReceiver) stopLocked() {
+	r.runMu.Lock()
+	query, self, scraper := r.query, r.self, r.scraper
+	statsd, syslog, forward, tailer := r.statsd, r.syslog, r.forward, r.tailer
+	server, listeners := r.server, r.listeners
+	httpServer, httpListeners := r.httpServer, r.httpListeners
+	r.query, r.self, r.scraper = nil, nil, nil
+	r.statsd, r.syslog, r.forward, r.tailer = nil, nil, nil, nil
+	if tailer != nil {
+		r.stoppedTailer = tailer
+	}
+	r.server, r.listeners = nil, nil
+	r.httpServer, r.httpListeners = nil, nil
+	r.runMu.Unlock()
+
+	if query != nil {
+		query.stop()
 	}
-	if r.listener != nil {
-		r.listener.Close()
+	if self != nil {
+		self.stop()
 	}
+	if scraper != nil {
+		scraper.stop()
+	}
+	if statsd != nil {
+		statsd.stop()
+	}
+	if syslog != nil {
+		syslog.stop()
+	}
+	if forward != nil {
+		forward.stop()
+	}
+	if tailer != nil {
+		tailer.stop()
+	}
+
+	// In-flight exports get DrainTimeout to finish across both servers.
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.DrainTimeout)
+	defer cancel()
+	stopHTTP(ctx, httpServer, httpListeners)
+	if server != nil {
+		drainGRPC(ctx, server)
+	}
+	closeListeners(listeners)
+	r.stopUpstreams()
+	r.setStopped()
 	log.Println("[Phosphor] OTLP receiver stopped")
 }
 
+// stopUpstreams flushes and closes forwarding once nothing can export anymore.
+func (r *OTLPReceiver) stopUpstreams() {
+	r.runMu.Lock()
+	upstreams := r.upstreams
+	r.upstreams = nil
+	r.runMu.Unlock()
+
+	if upstreams != nil {
+		upstreams.stop()
+	}
+}
+
 // Export implements the TraceService Export method.
 func (h *traceServiceHandler) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
 	if req == nil {
 		return &coltracepb.ExportTraceServiceResponse{}, nil
 	}
 
+	size := proto.Size(req)
+	defer h.receiver.telemetry.observeExport(models.SignalTypeTrace, size, time.Now())
+
+	faults, err := h.receiver.injectFaults(ctx, models.SignalTypeTrace)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := h.receiver.admit(countSpans(req))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	h.receiver.forwardUpstream(req)
+
 	var spanCount int
+	var rejected rejectionTracker
 	r := h.receiver
+	source := r.clients.identify(ctx)
+	services := make(map[string]struct{})
 
 	for _, resourceSpans := range req.ResourceSpans {
 		resource := models.ConvertResource(resourceSpans.Resource)
+		services[resource.ServiceName] = struct{}{}
 
 		for _, scopeSpans := range resourceSpans.ScopeSpans {
 			scope := models.ConvertInstrumentationScope(scopeSpans.Scope)
 
 			for _, span := range scopeSpans.Spans {
-				converted := models.ConvertSpan(span, resource, scope)
+				if faults.reject() {
+					rejected.add(1, injectedRejection)
+					continue
+				}
+				converted, err := models.ConvertSpan(span, resource, scope)
+				if err != nil {
+					rejected.add(1, err.Error())
+					r.recordRejection(models.SignalTypeTrace, resource.ServiceName, err)
+					continue
+				}
+				converted.Source = source
 				r.traces.Push(converted)
 				spanCount++
 
@@ -214,13 +647,24 @@ func (h *traceServiceHandler) Export(ctx context.Context, req *coltracepb.Export
 		}
 	}
 
+	r.finishFaults(ctx, models.SignalTypeTrace, faults)
+	r.clients.recordExport(source, models.SignalTypeTrace, spanCount, size, services)
+
 	r.statsMu.Lock()
 	r.stats.TracesReceived += uint64(spanCount)
+	r.stats.TracesRejected += uint64(rejected.count)
 	r.statsMu.Unlock()
 
-	log.Printf("[Phosphor] Received %d spans", spanCount)
+	log.Printf("[Phosphor] Received %d spans (%d rejected)", spanCount, rejected.count)
 
-	return &coltracepb.ExportTraceServiceResponse{}, nil
+	resp := &coltracepb.ExportTraceServiceResponse{}
+	if !rejected.empty() {
+		resp.PartialSuccess = &coltracepb.ExportTracePartialSuccess{
+			RejectedSpans: rejected.count,
+			ErrorMessage:  rejected.message(),
+		}
+	}
+	return resp, nil
 }
 
 // Export implements the MetricsService Export method.
@@ -229,17 +673,46 @@ func (h *metricsServiceHandler) Export(ctx context.Context, req *colmetricspb.Ex
 		return &colmetricspb.ExportMetricsServiceResponse{}, nil
 	}
 
+	size := proto.Size(req)
+	defer h.receiver.telemetry.observeExport(models.SignalTypeMetric, size, time.Now())
+
+	faults, err := h.receiver.injectFaults(ctx, models.SignalTypeMetric)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := h.receiver.admit(countMetrics(req))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	h.receiver.forwardUpstream(req)
+
 	var metricCount int
+	var rejected rejectionTracker
 	r := h.receiver
+	source := r.clients.identify(ctx)
+	services := make(map[string]struct{})
 
 	for _, resourceMetrics := range req.ResourceMetrics {
 		resource := models.ConvertResource(resourceMetrics.Resource)
+		services[resource.ServiceName] = struct{}{}
 
 		for _, scopeMetrics := range resourceMetrics.ScopeMetrics {
 			scope := models.ConvertInstrumentationScope(scopeMetrics.Scope)
 
 			for _, metric := range scopeMetrics.Metrics {
-				converted := models.ConvertMetric(metric, resource, scope)
+				if faults.reject() {
+					rejected.add(int64(models.DataPointCount(metric)), injectedRejection)
+					continue
+				}
+				converted, err := models.ConvertMetric(metric, resource, scope)
+				if err != nil {
+					rejected.add(int64(models.DataPointCount(metric)), err.Error())
+					r.recordRejection(models.SignalTypeMetric, resource.ServiceName, err)
+					continue
+				}
+				converted.Source = source
 				r.metrics.Push(converted)
 				metricCount++
 
@@ -253,13 +726,24 @@ func (h *metricsServiceHandler) Export(ctx context.Context, req *colmetricspb.Ex
 		}
 	}
 
+	r.finishFaults(ctx, models.SignalTypeMetric, faults)
+	r.clients.recordExport(source, models.SignalTypeMetric, metricCount, size, services)
+
 	r.statsMu.Lock()
 	r.stats.MetricsReceived += uint64(metricCount)
+	r.stats.MetricsRejected += uint64(rejected.count)
 	r.statsMu.Unlock()
 
-	log.Printf("[Phosphor] Received %d metrics", metricCount)
+	log.Printf("[Phosphor] Received %d metrics (%d data points rejected)", metricCount, rejected.count)
 
-	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+	resp := &colmetricspb.ExportMetricsServiceResponse{}
+	if !rejected.empty() {
+		resp.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected.count,
+			ErrorMessage:       rejected.message(),
+		}
+	}
+	return resp, nil
 }
 
 // Export implements the LogsService Export method.
@@ -268,17 +752,46 @@ func (h *logsServiceHandler) Export(ctx context.Context, req *collogspb.ExportLo
 		return &collogspb.ExportLogsServiceResponse{}, nil
 	}
 
+	size := proto.Size(req)
+	defer h.receiver.telemetry.observeExport(models.SignalTypeLog, size, time.Now())
+
+	faults, err := h.receiver.injectFaults(ctx, models.SignalTypeLog)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := h.receiver.admit(countLogs(req))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	h.receiver.forwardUpstream(req)
+
 	var logCount int
+	var rejected rejectionTracker
 	r := h.receiver
+	source := r.clients.identify(ctx)
+	services := make(map[string]struct{})
 
 	for _, resourceLogs := range req.ResourceLogs {
 		resource := models.ConvertResource(resourceLogs.Resource)
+		services[resource.ServiceName] = struct{}{}
 
 		for _, scopeLogs := range resourceLogs.ScopeLogs {
 			scope := models.ConvertInstrumentationScope(scopeLogs.Scope)
 
 			for _, logRecord := range scopeLogs.LogRecords {
-				converted := models.ConvertLogRecord(logRecord, resource, scope)
+				if faults.reject() {
+					rejected.add(1, injectedRejection)
+					continue
+				}
+				converted, err := models.ConvertLogRecord(logRecord, resource, scope)
+				if err != nil {
+					rejected.add(1, err.Error())
+					r.recordRejection(models.SignalTypeLog, resource.ServiceName, err)
+					continue
+				}
+				converted.Source = source
 				r.logs.Push(converted)
 				logCount++
 
@@ -292,13 +805,103 @@ func (h *logsServiceHandler) Export(ctx context.Context, req *collogspb.ExportLo
 		}
 	}
 
+	r.finishFaults(ctx, models.SignalTypeLog, faults)
+	r.clients.recordExport(source, models.SignalTypeLog, logCount, size, services)
+
 	r.statsMu.Lock()
 	r.stats.LogsReceived += uint64(logCount)
+	r.stats.LogsRejected += uint64(rejected.count)
+	r.statsMu.Unlock()
+
+	log.Printf("[Phosphor] Received %d logs (%d rejected)", logCount, rejected.count)
+
+	resp := &collogspb.ExportLogsServiceResponse{}
+	if !rejected.empty() {
+		resp.PartialSuccess = &collogspb.ExportLogsPartialSuccess{
+			RejectedLogRecords: rejected.count,
+			ErrorMessage:       rejected.message(),
+		}
+	}
+	return resp, nil
+}
+
+// Export implements the ProfilesService Export method.
+func (h *profilesServiceHandler) Export(ctx context.Context, req *colprofilespb.ExportProfilesServiceRequest) (*colprofilespb.ExportProfilesServiceResponse, error) {
+	if req == nil {
+		return &colprofilespb.ExportProfilesServiceResponse{}, nil
+	}
+
+	size := proto.Size(req)
+	defer h.receiver.telemetry.observeExport(models.SignalTypeProfile, size, time.Now())
+
+	faults, err := h.receiver.injectFaults(ctx, models.SignalTypeProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := h.receiver.admit(countProfiles(req))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	h.receiver.forwardUpstream(req)
+
+	var profileCount int
+	var rejected rejectionTracker
+	r := h.receiver
+	source := r.clients.identify(ctx)
+	services := make(map[string]struct{})
+
+	for _, resourceProfiles := range req.ResourceProfiles {
+		resource := models.ConvertResource(resourceProfiles.Resource)
+		services[resource.ServiceName] = struct{}{}
+
+		for _, scopeProfiles := range resourceProfiles.ScopeProfiles {
+			scope := models.ConvertInstrumentationScope(scopeProfiles.Scope)
+
+			for _, profile := range scopeProfiles.Profiles {
+				if faults.reject() {
+					rejected.add(1, injectedRejection)
+					continue
+				}
+				converted, err := models.ConvertProfile(profile, req.Dictionary, resource, scope)
+				if err != nil {
+					rejected.add(1, err.Error())
+					r.recordRejection(models.SignalTypeProfile, resource.ServiceName, err)
+					continue
+				}
+				converted.Source = source
+				r.profiles.Push(converted)
+				profileCount++
+
+				// Emit real-time event
+				r.emitEvent(models.TelemetryEvent{
+					Type:      models.SignalTypeProfile,
+					Profile:   &converted,
+					Timestamp: converted.ReceivedAt,
+				})
+			}
+		}
+	}
+
+	r.finishFaults(ctx, models.SignalTypeProfile, faults)
+	r.clients.recordExport(source, models.SignalTypeProfile, profileCount, size, services)
+
+	r.statsMu.Lock()
+	r.stats.ProfilesReceived += uint64(profileCount)
+	r.stats.ProfilesRejected += uint64(rejected.count)
 	r.statsMu.Unlock()
 
-	log.Printf("[Phosphor] Received %d logs", logCount)
+	log.Printf("[Phosphor] Received %d profiles (%d rejected)", profileCount, rejected.count)
 
-	return &collogspb.ExportLogsServiceResponse{}, nil
+	resp := &colprofilespb.ExportProfilesServiceResponse{}
+	if !rejected.empty() {
+		resp.PartialSuccess = &colprofilespb.ExportProfilesPartialSuccess{
+			RejectedProfiles: rejected.count,
+			ErrorMessage:     rejected.message(),
+		}
+	}
+	return resp, nil
 }
 
 // GetTraces returns all stored traces.
@@ -331,6 +934,30 @@ func (r *OTLPReceiver) GetRecentLogs(n int) []models.LogRecord {
 	return r.logs.GetLast(n)
 }
 
+// GetProfiles returns all stored profiles.
+func (r *OTLPReceiver) GetProfiles() []models.Profile {
+	return r.profiles.GetAll()
+}
+
+// GetRecentProfiles returns the last n profiles.
+func (r *OTLPReceiver) GetRecentProfiles(n int) []models.Profile {
+	return r.profiles.GetLast(n)
+}
+
+// GetProfilesForSpan returns the stored profiles that sampled the given span,
+// each narrowed to the samples linked to it.
+func (r *OTLPReceiver) GetProfilesForSpan(traceID, spanID string) []models.Profile {
+	result := []models.Profile{}
+	r.profiles.ForEach(func(p models.Profile) bool {
+		if samples := p.SamplesForSpan(traceID, spanID); len(samples) > 0 {
+			p.Samples = samples
+			result = append(result, p)
+		}
+		return true
+	})
+	return result
+}
+
 // GetStats returns the current telemetry statistics.
 func (r *OTLPReceiver) GetStats() models.TelemetryStats {
 	r.statsMu.RLock()
@@ -339,25 +966,43 @@ func (r *OTLPReceiver) GetStats() models.TelemetryStats {
 	traceStats := r.traces.Stats()
 	metricStats := r.metrics.Stats()
 	logStats := r.logs.Stats()
+	profileStats := r.profiles.Stats()
 
 	return models.TelemetryStats{
-		TraceCount:     traceStats.Count,
-		MetricCount:    metricStats.Count,
-		LogCount:       logStats.Count,
-		TraceCapacity:  traceStats.Capacity,
-		MetricCapacity: metricStats.Capacity,
-		LogCapacity:    logStats.Capacity,
-		TraceUsage:     traceStats.Usage,
-		MetricUsage:    metricStats.Usage,
-		LogUsage:       logStats.Usage,
+		TraceCount:      traceStats.Count,
+		MetricCount:     metricStats.Count,
+		LogCount:        logStats.Count,
+		ProfileCount:    profileStats.Count,
+		TraceCapacity:   traceStats.Capacity,
+		MetricCapacity:  metricStats.Capacity,
+		LogCapacity:     logStats.Capacity,
+		ProfileCapacity: profileStats.Capacity,
+		TraceUsage:      traceStats.Usage,
+		MetricUsage:     metricStats.Usage,
+		LogUsage:        logStats.Usage,
+		ProfileUsage:    profileStats.Usage,
 	}
 }
 
+// GetReceiverStats returns the ingestion counters.
+func (r *OTLPReceiver) GetReceiverStats() ReceiverStats {
+	r.statsMu.RLock()
+	stats := r.stats
+	r.statsMu.RUnlock()
+
+	_, stats.InFlightItems = r.admission.inFlight()
+	stats.Subscribers = r.events.Stats()
+	return stats
+}
+
 // ClearAll clears all stored telemetry data.
 func (r *OTLPReceiver) ClearAll() {
 	r.traces.Clear()
 	r.metrics.Clear()
 	r.logs.Clear()
+	r.profiles.Clear()
+	r.rejections.Clear()
+	r.clients.clear()
 
 	r.statsMu.Lock()
 	r.stats = ReceiverStats{}
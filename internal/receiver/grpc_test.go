@@ -0,0 +1,258 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	profilespb "go.opentelemetry.io/proto/otlp/profiles/v1development"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestTraceExportPartialSuccess(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	req := testTraceRequest()
+	spans := req.ResourceSpans[0].ScopeSpans[0]
+	spans.Spans = append(spans.Spans,
+		&tracepb.Span{Name: "no-trace-id", SpanId: bytes.Repeat([]byte{1}, 8)},
+		&tracepb.Span{Name: "short-span-id", TraceId: bytes.Repeat([]byte{1}, 16), SpanId: []byte{1}},
+	)
+
+	resp, err := r.traceService.Export(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if resp.PartialSuccess == nil {
+		t.Fatal("PartialSuccess = nil, want rejected spans reported")
+	}
+	if resp.PartialSuccess.RejectedSpans != 2 {
+		t.Errorf("RejectedSpans = %d, want 2", resp.PartialSuccess.RejectedSpans)
+	}
+	if !strings.Contains(resp.PartialSuccess.ErrorMessage, "no-trace-id") {
+		t.Errorf("ErrorMessage = %q, want mention of rejected span", resp.PartialSuccess.ErrorMessage)
+	}
+
+	if got := len(r.GetTraces()); got != 1 {
+		t.Errorf("GetTraces() returned %d spans, want 1", got)
+	}
+	rejections := r.GetRejections()
+	if len(rejections) != 2 {
+		t.Fatalf("GetRejections() returned %d, want 2", len(rejections))
+	}
+	if rejections[0].ServiceName != "checkout" {
+		t.Errorf("Rejection.ServiceName = %q, want 'checkout'", rejections[0].ServiceName)
+	}
+	if got := r.GetReceiverStats().TracesRejected; got != 2 {
+		t.Errorf("TracesRejected = %d, want 2", got)
+	}
+}
+
+func TestLogExportRejectsInvalidSeverity(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	req := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			ScopeLogs: []*logspb.ScopeLogs{{
+				LogRecords: []*logspb.LogRecord{
+					{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO},
+					{SeverityNumber: logspb.SeverityNumber(99)},
+				},
+			}},
+		}},
+	}
+
+	resp, err := r.logsService.Export(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if resp.PartialSuccess.GetRejectedLogRecords() != 1 {
+		t.Errorf("RejectedLogRecords = %d, want 1", resp.PartialSuccess.GetRejectedLogRecords())
+	}
+	if got := len(r.GetLogs()); got != 1 {
+		t.Errorf("GetLogs() returned %d records, want 1", got)
+	}
+}
+
+func TestMetricExportWarnsOnEmptyMetric(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	req := &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{{Name: "no.data"}},
+			}},
+		}},
+	}
+
+	resp, err := r.metricsService.Export(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	// The metric has no data points to count, so it is reported as a warning.
+	if resp.PartialSuccess == nil {
+		t.Fatal("PartialSuccess = nil, want a warning for the dropped metric")
+	}
+	if resp.PartialSuccess.RejectedDataPoints != 0 {
+		t.Errorf("RejectedDataPoints = %d, want 0", resp.PartialSuccess.RejectedDataPoints)
+	}
+	if !strings.Contains(resp.PartialSuccess.ErrorMessage, "no.data") {
+		t.Errorf("ErrorMessage = %q, want mention of the dropped metric", resp.PartialSuccess.ErrorMessage)
+	}
+}
+
+func TestExportAllValidOmitsPartialSuccess(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	resp, err := r.traceService.Export(context.Background(), testTraceRequest())
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if resp.PartialSuccess != nil {
+		t.Errorf("PartialSuccess = %v, want nil for fully accepted request", resp.PartialSuccess)
+	}
+}
+
+// testProfileRequest builds a CPU profile whose dictionary is shared with a
+// second, smaller profile. The first sample of the CPU profile is linked to
+// the span from testTraceRequest. Every dictionary table starts with the
+// empty entry that index 0 refers to.
+func testProfileRequest() *colprofilespb.ExportProfilesServiceRequest {
+	return &colprofilespb.ExportProfilesServiceRequest{
+		Dictionary: &profilespb.ProfilesDictionary{
+			StringTable:  []string{"", "cpu", "nanoseconds", "main", "handleCart", "cart.go", "/app/server", "samples", "count"},
+			MappingTable: []*profilespb.Mapping{{}, {FilenameStrindex: 6}},
+			FunctionTable: []*profilespb.Function{
+				{},
+				{NameStrindex: 3, FilenameStrindex: 5},
+				{NameStrindex: 4, FilenameStrindex: 5, StartLine: 40},
+			},
+			LocationTable: []*profilespb.Location{
+				{},
+				{MappingIndex: 1, Address: 0x4a2f10, Lines: []*profilespb.Line{{FunctionIndex: 1, Line: 12}}},
+				{MappingIndex: 1, Address: 0x4a3000, Lines: []*profilespb.Line{{FunctionIndex: 2, Line: 42}}},
+			},
+			StackTable: []*profilespb.Stack{
+				{},
+				{LocationIndices: []int32{2, 1}},
+				{LocationIndices: []int32{1}},
+				{LocationIndices: []int32{2}},
+			},
+			LinkTable: []*profilespb.Link{{}, {
+				TraceId: bytes.Repeat([]byte{0xab}, 16),
+				SpanId:  bytes.Repeat([]byte{0xcd}, 8),
+			}},
+		},
+		ResourceProfiles: []*profilespb.ResourceProfiles{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{{
+					Key:   "service.name",
+					Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "checkout"}},
+				}},
+			},
+			ScopeProfiles: []*profilespb.ScopeProfiles{{
+				Profiles: []*profilespb.Profile{
+					{
+						ProfileId:    bytes.Repeat([]byte{0x01}, 16),
+						SampleType:   &profilespb.ValueType{TypeStrindex: 1, UnitStrindex: 2},
+						PeriodType:   &profilespb.ValueType{TypeStrindex: 1, UnitStrindex: 2},
+						Period:       10_000_000,
+						DurationNano: 1_000_000_000,
+						Samples: []*profilespb.Sample{
+							{StackIndex: 1, Values: []int64{30_000_000}, LinkIndex: 1},
+							{StackIndex: 2, Values: []int64{10_000_000}},
+						},
+					},
+					{
+						SampleType: &profilespb.ValueType{TypeStrindex: 7, UnitStrindex: 8},
+						Samples:    []*profilespb.Sample{{StackIndex: 3, Values: []int64{1}}},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func TestProfileExportLinksSpans(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	resp, err := r.profilesService.Export(context.Background(), testProfileRequest())
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if resp.PartialSuccess != nil {
+		t.Errorf("PartialSuccess = %v, want nil", resp.PartialSuccess)
+	}
+
+	profiles := r.GetProfiles()
+	if len(profiles) != 2 {
+		t.Fatalf("GetProfiles() returned %d profiles, want 2", len(profiles))
+	}
+	p := profiles[0]
+	if p.Resource.ServiceName != "checkout" || p.ProfileID != strings.Repeat("01", 16) {
+		t.Errorf("profile = %q/%q, want checkout with hex ID", p.Resource.ServiceName, p.ProfileID)
+	}
+	if p.SampleType.Type != "cpu" || p.SampleType.Unit != "nanoseconds" {
+		t.Errorf("SampleType = %v, want cpu/nanoseconds", p.SampleType)
+	}
+	if len(p.Locations) != 2 || len(p.Functions) != 2 {
+		t.Fatalf("profile has %d locations, %d functions; want 2, 2", len(p.Locations), len(p.Functions))
+	}
+	leaf := p.Locations[p.Samples[0].LocationIndices[0]]
+	if fn := p.Functions[leaf.Lines[0].FunctionIndex]; fn.Name != "handleCart" || leaf.Lines[0].Line != 42 {
+		t.Errorf("leaf frame = %s:%d, want handleCart:42", fn.Name, leaf.Lines[0].Line)
+	}
+	if leaf.Address != "0x4a3000" || leaf.Mapping != "/app/server" {
+		t.Errorf("leaf location = %s in %q", leaf.Address, leaf.Mapping)
+	}
+
+	// The second profile only references one dictionary location.
+	if got := profiles[1]; len(got.Locations) != 1 || len(got.Functions) != 1 {
+		t.Errorf("second profile has %d locations, %d functions; want 1, 1", len(got.Locations), len(got.Functions))
+	}
+
+	linked := r.GetProfilesForSpan(strings.Repeat("ab", 16), strings.Repeat("cd", 8))
+	if len(linked) != 1 || len(linked[0].Samples) != 1 || linked[0].Samples[0].Values[0] != 30_000_000 {
+		t.Fatalf("GetProfilesForSpan() = %v, want the one linked sample", linked)
+	}
+	if got := r.GetProfilesForSpan(strings.Repeat("ab", 16), strings.Repeat("ef", 8)); len(got) != 0 {
+		t.Errorf("GetProfilesForSpan(other span) returned %d profiles, want 0", len(got))
+	}
+	if got := r.GetStats().ProfileCount; got != 2 {
+		t.Errorf("ProfileCount = %d, want 2", got)
+	}
+}
+
+func TestProfileExportRejectsDanglingReferences(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	req := testProfileRequest()
+	req.Dictionary.StackTable = append(req.Dictionary.StackTable, &profilespb.Stack{LocationIndices: []int32{7}})
+	profiles := req.ResourceProfiles[0].ScopeProfiles[0].Profiles
+	profiles[1].Samples[0].StackIndex = int32(len(req.Dictionary.StackTable) - 1)
+
+	resp, err := r.profilesService.Export(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if resp.PartialSuccess.GetRejectedProfiles() != 1 {
+		t.Errorf("RejectedProfiles = %d, want 1", resp.PartialSuccess.GetRejectedProfiles())
+	}
+	if !strings.Contains(resp.PartialSuccess.GetErrorMessage(), "location 7") {
+		t.Errorf("ErrorMessage = %q, want mention of the missing location", resp.PartialSuccess.GetErrorMessage())
+	}
+	if got := len(r.GetProfiles()); got != 1 {
+		t.Errorf("GetProfiles() returned %d profiles, want 1", got)
+	}
+	if got := r.GetReceiverStats().ProfilesRejected; got != 1 {
+		t.Errorf("ProfilesRejected = %d, want 1", got)
+	}
+}
@@ -0,0 +1,390 @@
+package receiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLP/HTTP content types as defined by the OTLP specification.
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// profilesPath is the OTLP/HTTP path of the development profiles signal.
+const profilesPath = "/v1development/profiles"
+
+// maxHTTPBodySize limits decoded request bodies to match the gRPC max message size.
+const maxHTTPBodySize = 16 * 1024 * 1024
+
+// otlpIDFields lists the JSON fields that OTLP/JSON encodes as hex strings
+// rather than the base64 that protojson expects for bytes fields.
+var otlpIDFields = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+	"profileId":    true,
+}
+
+// httpHandler serves the OTLP/HTTP endpoints and delegates to the gRPC handlers
+// so both transports share the same conversion, buffering and event path.
+type httpHandler struct {
+	receiver *OTLPReceiver
+}
+
+// newHTTPHandler builds the OTLP/HTTP request multiplexer.
+func newHTTPHandler(r *OTLPReceiver) http.Handler {
+	h := &httpHandler{receiver: r}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/traces", h.handleTraces)
+	mux.HandleFunc("/v1/metrics", h.handleMetrics)
+	mux.HandleFunc("/v1/logs", h.handleLogs)
+	mux.HandleFunc(profilesPath, h.handleProfiles)
+	mux.HandleFunc(zipkinSpansPath, h.handleZipkinSpans)
+	mux.HandleFunc(jaegerTracesPath, h.handleJaegerTraces)
+	mux.HandleFunc(remoteWritePath, h.handleRemoteWrite)
+	return mux
+}
+
+// startHTTP begins serving OTLP/HTTP on the configured HTTP addresses.
+func (r *OTLPReceiver) startHTTP() error {
+	var (
+		listeners []net.Listener
+		err       error
+	)
+	if len(r.config.HTTPListenAddresses) > 0 {
+		listeners, err = listenAll(r.config.HTTPListenAddresses)
+	} else {
+		listeners, err = r.listenPort("OTLP/HTTP", r.config.HTTPPort)
+	}
+	if err != nil {
+		return err
+	}
+	listeners = r.conns.track(listeners)
+	r.httpListeners = listeners
+
+	r.httpServer = &http.Server{
+		Handler:           r.errorMiddleware(r.authMiddleware(newHTTPHandler(r))),
+		ReadHeaderTimeout: 10 * time.Second,
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			if state == http.StateClosed || state == http.StateHijacked {
+				r.clients.disconnect(transportHTTP, peerAddress(conn.RemoteAddr()))
+			}
+		},
+	}
+
+	for _, listener := range listeners {
+		log.Printf("[Phosphor] OTLP/HTTP receiver listening on %s (tls=%t)", listenerAddress(listener), r.tlsConfig != nil)
+
+		served := listener
+		if r.tlsConfig != nil {
+			served = tls.NewListener(listener, r.tlsConfig)
+		}
+		go func(server *http.Server, l net.Listener) {
+			if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				r.degrade("HTTP server error on %s: %v", listenerAddress(l), err)
+			}
+		}(r.httpServer, served)
+	}
+
+	return nil
+}
+
+// stopHTTP shuts down the OTLP/HTTP server, waiting for in-flight requests
+// until ctx expires.
+func stopHTTP(ctx context.Context, server *http.Server, listeners []net.Listener) {
+	if server == nil {
+		return
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("[Phosphor] OTLP/HTTP drain incomplete (%v), closing remaining connections", err)
+		server.Close()
+	}
+	closeListeners(listeners)
+}
+
+// handleTraces serves POST /v1/traces.
+func (h *httpHandler) handleTraces(w http.ResponseWriter, req *http.Request) {
+	contentType, ok := h.readRequest(w, req)
+	if !ok {
+		return
+	}
+	body, err := readBody(req)
+	if err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	exportReq := &coltracepb.ExportTraceServiceRequest{}
+	if err := decodeMessage(body, contentType, exportReq); err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	resp, err := h.receiver.traceService.Export(exportContext(req), exportReq)
+	writeResponse(w, contentType, resp, err)
+}
+
+// handleMetrics serves POST /v1/metrics.
+func (h *httpHandler) handleMetrics(w http.ResponseWriter, req *http.Request) {
+	contentType, ok := h.readRequest(w, req)
+	if !ok {
+		return
+	}
+	body, err := readBody(req)
+	if err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	exportReq := &colmetricspb.ExportMetricsServiceRequest{}
+	if err := decodeMessage(body, contentType, exportReq); err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	resp, err := h.receiver.metricsService.Export(exportContext(req), exportReq)
+	writeResponse(w, contentType, resp, err)
+}
+
+// handleLogs serves POST /v1/logs.
+func (h *httpHandler) handleLogs(w http.ResponseWriter, req *http.Request) {
+	contentType, ok := h.readRequest(w, req)
+	if !ok {
+		return
+	}
+	body, err := readBody(req)
+	if err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	exportReq := &collogspb.ExportLogsServiceRequest{}
+	if err := decodeMessage(body, contentType, exportReq); err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	resp, err := h.receiver.logsService.Export(exportContext(req), exportReq)
+	writeResponse(w, contentType, resp, err)
+}
+
+// handleProfiles serves POST /v1development/profiles.
+func (h *httpHandler) handleProfiles(w http.ResponseWriter, req *http.Request) {
+	contentType, ok := h.readRequest(w, req)
+	if !ok {
+		return
+	}
+	body, err := readBody(req)
+	if err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	exportReq := &colprofilespb.ExportProfilesServiceRequest{}
+	if err := decodeMessage(body, contentType, exportReq); err != nil {
+		writeStatus(w, contentType, http.StatusBadRequest, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	resp, err := h.receiver.profilesService.Export(exportContext(req), exportReq)
+	writeResponse(w, contentType, resp, err)
+}
+
+// exportContext derives the context passed to the shared Export handlers,
+// carrying the same peer and user-agent information a gRPC call would.
+func exportContext(req *http.Request) context.Context {
+	ctx := withTransport(req.Context(), transportHTTP)
+	var addr net.Addr = &net.UnixAddr{Name: req.RemoteAddr, Net: "unix"}
+	if tcpAddr, err := net.ResolveTCPAddr("tcp", req.RemoteAddr); err == nil {
+		addr = tcpAddr
+	}
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("user-agent", req.UserAgent()))
+}
+
+// readRequest validates the method and content type of an export request.
+// It writes the error response itself and returns false if the request is rejected.
+func (h *httpHandler) readRequest(w http.ResponseWriter, req *http.Request) (string, bool) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return "", false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || (mediaType != contentTypeProtobuf && mediaType != contentTypeJSON) {
+		http.Error(w, fmt.Sprintf("unsupported content type %q", req.Header.Get("Content-Type")), http.StatusUnsupportedMediaType)
+		return "", false
+	}
+	return mediaType, true
+}
+
+// readBody reads the request body, transparently decompressing gzip payloads.
+func readBody(req *http.Request) ([]byte, error) {
+	var reader io.Reader = req.Body
+	switch req.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", req.Header.Get("Content-Encoding"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxHTTPBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(body) > maxHTTPBodySize {
+		return nil, fmt.Errorf("body exceeds %d bytes", maxHTTPBodySize)
+	}
+	return body, nil
+}
+
+// decodeMessage unmarshals an OTLP request in either protobuf or JSON encoding.
+func decodeMessage(body []byte, contentType string, msg proto.Message) error {
+	if contentType == contentTypeProtobuf {
+		return proto.Unmarshal(body, msg)
+	}
+
+	normalized, err := normalizeOTLPJSON(body)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(normalized, msg)
+}
+
+// normalizeOTLPJSON rewrites hex-encoded trace and span IDs into the base64
+// form expected by protojson. Values that are not valid hex are passed through
+// unchanged for compatibility with exporters that send base64.
+func normalizeOTLPJSON(body []byte) ([]byte, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	rewriteIDs(doc)
+	return json.Marshal(doc)
+}
+
+// rewriteIDs walks a decoded JSON document converting hex ID fields in place.
+func rewriteIDs(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if s, ok := val.(string); ok && otlpIDFields[key] {
+				if raw, err := hex.DecodeString(s); err == nil {
+					v[key] = base64.StdEncoding.EncodeToString(raw)
+				}
+				continue
+			}
+			rewriteIDs(val)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			rewriteIDs(elem)
+		}
+	}
+}
+
+// writeResponse encodes an export response, or maps a handler error to the
+// equivalent OTLP/HTTP status.
+func writeResponse(w http.ResponseWriter, contentType string, resp proto.Message, err error) {
+	if err != nil {
+		st := status.Convert(err)
+		setRetryAfter(w, st)
+		writeStatus(w, contentType, httpStatusFromCode(st.Code()), st)
+		return
+	}
+	writeMessage(w, contentType, http.StatusOK, resp)
+}
+
+// setRetryAfter advertises the server's retry hint, rounded up to whole seconds.
+func setRetryAfter(w http.ResponseWriter, st *status.Status) {
+	if delay, ok := retryDelay(st); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int((delay+time.Second-1)/time.Second)))
+	}
+}
+
+// writeStatus writes a google.rpc.Status body as required by OTLP/HTTP for failures.
+func writeStatus(w http.ResponseWriter, contentType string, code int, st *status.Status) {
+	writeMessage(w, contentType, code, st.Proto())
+}
+
+// writeMessage encodes msg using the same encoding as the request.
+func writeMessage(w http.ResponseWriter, contentType string, code int, msg proto.Message) {
+	var (
+		data []byte
+		err  error
+	)
+	if contentType == contentTypeJSON {
+		data, err = protojson.Marshal(msg)
+	} else {
+		contentType = contentTypeProtobuf
+		data, err = proto.Marshal(msg)
+	}
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// httpStatusFromCode maps gRPC status codes to the HTTP status codes the OTLP
+// specification uses to signal retryable and non-retryable failures.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,201 @@
+package receiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func testTraceRequest() *coltracepb.ExportTraceServiceRequest {
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{{
+					Key:   "service.name",
+					Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "checkout"}},
+				}},
+			},
+			ScopeSpans: []*tracepb.ScopeSpans{{
+				Spans: []*tracepb.Span{{
+					TraceId: bytes.Repeat([]byte{0xab}, 16),
+					SpanId:  bytes.Repeat([]byte{0xcd}, 8),
+					Name:    "GET /cart",
+				}},
+			}},
+		}},
+	}
+}
+
+func TestHTTPTracesProtobuf(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	handler := newHTTPHandler(r)
+
+	body, err := proto.Marshal(testTraceRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/traces", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentTypeProtobuf)
+	req.Header.Set("User-Agent", "OTel-OTLP-Exporter-Go/1.0")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != contentTypeProtobuf {
+		t.Errorf("Content-Type = %q, want %q", got, contentTypeProtobuf)
+	}
+
+	traces := r.GetTraces()
+	if len(traces) != 1 {
+		t.Fatalf("GetTraces() returned %d spans, want 1", len(traces))
+	}
+	if traces[0].Resource.ServiceName != "checkout" {
+		t.Errorf("ServiceName = %q, want 'checkout'", traces[0].Resource.ServiceName)
+	}
+
+	clients := r.GetClients()
+	if len(clients) != 1 {
+		t.Fatalf("GetClients() returned %d clients, want 1", len(clients))
+	}
+	client := clients[0]
+	if traces[0].Source != client.ID {
+		t.Errorf("Source = %q, want client ID %q", traces[0].Source, client.ID)
+	}
+	if client.Transport != transportHTTP || client.UserAgent != "OTel-OTLP-Exporter-Go/1.0" {
+		t.Errorf("client = %+v, want http transport with exporter user-agent", client)
+	}
+	if client.Spans != 1 || client.Exports != 1 || client.Bytes == 0 {
+		t.Errorf("client counters = %+v, want 1 span in 1 export", client)
+	}
+	if len(client.ServiceNames) != 1 || client.ServiceNames[0] != "checkout" {
+		t.Errorf("ServiceNames = %v, want [checkout]", client.ServiceNames)
+	}
+}
+
+func TestHTTPTracesJSONHexIDs(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	handler := newHTTPHandler(r)
+
+	body := `{"resourceSpans":[{"scopeSpans":[{"spans":[{
+		"traceId":"5b8efff798038103d269b633813fc60c",
+		"spanId":"eee19b7ec3c1b174",
+		"name":"json-span",
+		"kind":2,
+		"startTimeUnixNano":"1544712660000000000",
+		"endTimeUnixNano":"1544712661000000000"
+	}]}]}]}`
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(body))
+	gz.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/traces", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != contentTypeJSON {
+		t.Errorf("Content-Type = %q, want %q", got, contentTypeJSON)
+	}
+
+	traces := r.GetTraces()
+	if len(traces) != 1 {
+		t.Fatalf("GetTraces() returned %d spans, want 1", len(traces))
+	}
+	if traces[0].TraceID != "5b8efff798038103d269b633813fc60c" {
+		t.Errorf("TraceID = %q, want hex ID preserved", traces[0].TraceID)
+	}
+	if traces[0].SpanID != "eee19b7ec3c1b174" {
+		t.Errorf("SpanID = %q, want hex ID preserved", traces[0].SpanID)
+	}
+	if traces[0].DurationMs != 1000 {
+		t.Errorf("DurationMs = %f, want 1000", traces[0].DurationMs)
+	}
+}
+
+func TestHTTPProfilesJSON(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	handler := newHTTPHandler(r)
+
+	body := `{
+		"dictionary":{
+			"stringTable":["","cpu","nanoseconds","main"],
+			"functionTable":[{},{"nameStrindex":3}],
+			"locationTable":[{},{"lines":[{"functionIndex":1,"line":"7"}]}],
+			"stackTable":[{},{"locationIndices":[1]}],
+			"linkTable":[{},{"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174"}]
+		},
+		"resourceProfiles":[{"scopeProfiles":[{"profiles":[{
+			"profileId":"0102030405060708090a0b0c0d0e0f10",
+			"sampleType":{"typeStrindex":1,"unitStrindex":2},
+			"samples":[{"stackIndex":1,"values":["5000000"],"linkIndex":1}]
+		}]}]}]
+	}`
+
+	req := httptest.NewRequest(http.MethodPost, profilesPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentTypeJSON)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	profiles := r.GetProfilesForSpan("5b8efff798038103d269b633813fc60c", "eee19b7ec3c1b174")
+	if len(profiles) != 1 {
+		t.Fatalf("GetProfilesForSpan() returned %d profiles, want 1", len(profiles))
+	}
+	if profiles[0].ProfileID != "0102030405060708090a0b0c0d0e0f10" {
+		t.Errorf("ProfileID = %q, want hex ID preserved", profiles[0].ProfileID)
+	}
+	if got := profiles[0].Functions[0].Name; got != "main" {
+		t.Errorf("function = %q, want 'main'", got)
+	}
+}
+
+func TestHTTPRejectsBadRequests(t *testing.T) {
+	handler := newHTTPHandler(NewOTLPReceiver(DefaultConfig()))
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"wrong method", http.MethodGet, contentTypeProtobuf, "", http.StatusMethodNotAllowed},
+		{"unsupported content type", http.MethodPost, "text/plain", "hello", http.StatusUnsupportedMediaType},
+		{"malformed protobuf", http.MethodPost, contentTypeProtobuf, "\xff\xff\xff", http.StatusBadRequest},
+		{"malformed json", http.MethodPost, contentTypeJSON, "{", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/logs", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
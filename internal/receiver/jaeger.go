@@ -0,0 +1,462 @@
+package receiver
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	jaegermodel "github.com/jaegertracing/jaeger-idl/model/v1"
+	"github.com/jaegertracing/jaeger-idl/proto-gen/api_v2"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/status"
+)
+
+// jaegerTracesPath is the Jaeger collector's Thrift-over-HTTP endpoint.
+const jaegerTracesPath = "/api/traces"
+
+// Content types accepted for Thrift-encoded Jaeger batches.
+const (
+	contentTypeThrift       = "application/x-thrift"
+	contentTypeThriftBinary = "application/vnd.apache.thrift.binary"
+)
+
+// Jaeger tags that carry OpenTelemetry span fields rather than attributes.
+const (
+	jaegerTagSpanKind = "span.kind"
+	jaegerFieldEvent  = "event"
+)
+
+// jaegerCollector implements Jaeger's api_v2 CollectorService on the gRPC server.
+type jaegerCollector struct {
+	receiver *OTLPReceiver
+}
+
+// PostSpans handles a batch from a Jaeger client or agent.
+func (c *jaegerCollector) PostSpans(ctx context.Context, req *api_v2.PostSpansRequest) (*api_v2.PostSpansResponse, error) {
+	if _, err := c.receiver.traceService.Export(ctx, jaegerToOTLP(&req.Batch)); err != nil {
+		return nil, err
+	}
+	return &api_v2.PostSpansResponse{}, nil
+}
+
+// handleJaegerTraces serves POST /api/traces with a Thrift binary-encoded
+// Jaeger Batch. Spans are translated to OTLP and passed to the trace handler.
+func (h *httpHandler) handleJaegerTraces(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || (mediaType != contentTypeThrift && mediaType != contentTypeThriftBinary) {
+		http.Error(w, fmt.Sprintf("unsupported content type %q", req.Header.Get("Content-Type")), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	batch, err := decodeJaegerThrift(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid jaeger thrift batch: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.receiver.traceService.Export(exportContext(req), jaegerToOTLP(batch)); err != nil {
+		st := status.Convert(err)
+		setRetryAfter(w, st)
+		http.Error(w, st.Message(), httpStatusFromCode(st.Code()))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// jaegerToOTLP translates a Jaeger batch into an OTLP export request. Process
+// tags become resource attributes; spans carrying their own process are
+// grouped under a separate resource.
+func jaegerToOTLP(batch *jaegermodel.Batch) *coltracepb.ExportTraceServiceRequest {
+	req := &coltracepb.ExportTraceServiceRequest{}
+	resources := make(map[*jaegermodel.Process]*tracepb.ResourceSpans)
+	scopes := make(map[*tracepb.ResourceSpans]map[[2]string]*tracepb.ScopeSpans)
+
+	for _, js := range batch.Spans {
+		if js == nil {
+			continue
+		}
+
+		process := batch.Process
+		if js.Process != nil {
+			process = js.Process
+		}
+		rs, ok := resources[process]
+		if !ok {
+			rs = &tracepb.ResourceSpans{Resource: jaegerResource(process)}
+			resources[process] = rs
+			scopes[rs] = make(map[[2]string]*tracepb.ScopeSpans)
+			req.ResourceSpans = append(req.ResourceSpans, rs)
+		}
+
+		scope := otelScope(func(key string) string {
+			if kv, ok := jaegermodel.KeyValues(js.Tags).FindByKey(key); ok {
+				return kv.AsString()
+			}
+			return ""
+		})
+		ss, ok := scopes[rs][scope]
+		if !ok {
+			ss = &tracepb.ScopeSpans{Scope: &commonpb.InstrumentationScope{Name: scope[0], Version: scope[1]}}
+			scopes[rs][scope] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+		ss.Spans = append(ss.Spans, jaegerSpanToOTLP(js))
+	}
+	return req
+}
+
+// jaegerResource maps a Jaeger process to a resource.
+func jaegerResource(process *jaegermodel.Process) *resourcepb.Resource {
+	serviceName := "unknown"
+	if process != nil && process.ServiceName != "" {
+		serviceName = process.ServiceName
+	}
+	attrs := []*commonpb.KeyValue{stringKV(attrServiceName, serviceName)}
+	if process != nil {
+		for _, tag := range process.Tags {
+			if tag.Key == attrServiceName {
+				continue
+			}
+			attrs = append(attrs, jaegerKV(tag))
+		}
+	}
+	return &resourcepb.Resource{Attributes: attrs}
+}
+
+// jaegerSpanToOTLP converts a single Jaeger span. span.kind and status tags
+// become span fields and logs become span events.
+func jaegerSpanToOTLP(js *jaegermodel.Span) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId: traceIDBytes(js.TraceID.High, js.TraceID.Low),
+		SpanId:  spanIDBytes(uint64(js.SpanID)),
+		Name:    js.OperationName,
+		Kind:    tracepb.Span_SPAN_KIND_INTERNAL,
+	}
+	if !js.StartTime.IsZero() {
+		span.StartTimeUnixNano = uint64(js.StartTime.UnixNano())
+		span.EndTimeUnixNano = uint64(js.StartTime.Add(js.Duration).UnixNano())
+	}
+
+	parent := js.ParentSpanID()
+	if parent != 0 {
+		span.ParentSpanId = spanIDBytes(uint64(parent))
+	}
+
+	st := &tracepb.Status{}
+	for _, tag := range js.Tags {
+		switch tag.Key {
+		case jaegerTagSpanKind:
+			span.Kind = jaegerKind(tag.AsString())
+		case tagStatusCode:
+			st.Code = statusCodeFromTag(tag.AsString())
+		case tagStatusMessage:
+			st.Message = tag.AsString()
+		case tagScopeName, tagScopeVersion, tagLibraryName, tagLibraryVersion:
+		case tagError:
+			// Jaeger clients mark failed spans with error=true.
+			if tag.VType != jaegermodel.ValueType_BOOL || tag.Bool() {
+				st.Code = tracepb.Status_STATUS_CODE_ERROR
+			}
+			span.Attributes = append(span.Attributes, jaegerKV(tag))
+		default:
+			span.Attributes = append(span.Attributes, jaegerKV(tag))
+		}
+	}
+	span.Status = st
+
+	for _, ref := range js.References {
+		if ref.RefType == jaegermodel.SpanRefType_CHILD_OF && ref.TraceID == js.TraceID && ref.SpanID == parent {
+			continue
+		}
+		span.Links = append(span.Links, &tracepb.Span_Link{
+			TraceId: traceIDBytes(ref.TraceID.High, ref.TraceID.Low),
+			SpanId:  spanIDBytes(uint64(ref.SpanID)),
+		})
+	}
+
+	for _, l := range js.Logs {
+		event := &tracepb.Span_Event{TimeUnixNano: uint64(l.Timestamp.UnixNano())}
+		for _, field := range l.Fields {
+			if field.Key == jaegerFieldEvent && event.Name == "" {
+				event.Name = field.AsString()
+				continue
+			}
+			event.Attributes = append(event.Attributes, jaegerKV(field))
+		}
+		if event.Name == "" {
+			event.Name = "log"
+		}
+		span.Events = append(span.Events, event)
+	}
+	return span
+}
+
+// jaegerKind maps a span.kind tag value to an OTLP span kind.
+func jaegerKind(kind string) tracepb.Span_SpanKind {
+	switch strings.ToLower(kind) {
+	case "client":
+		return tracepb.Span_SPAN_KIND_CLIENT
+	case "server":
+		return tracepb.Span_SPAN_KIND_SERVER
+	case "producer":
+		return tracepb.Span_SPAN_KIND_PRODUCER
+	case "consumer":
+		return tracepb.Span_SPAN_KIND_CONSUMER
+	default:
+		return tracepb.Span_SPAN_KIND_INTERNAL
+	}
+}
+
+// jaegerKV converts a typed Jaeger tag to an OTLP attribute.
+func jaegerKV(kv jaegermodel.KeyValue) *commonpb.KeyValue {
+	value := &commonpb.AnyValue{}
+	switch kv.VType {
+	case jaegermodel.ValueType_BOOL:
+		value.Value = &commonpb.AnyValue_BoolValue{BoolValue: kv.VBool}
+	case jaegermodel.ValueType_INT64:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: kv.VInt64}
+	case jaegermodel.ValueType_FLOAT64:
+		value.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: kv.VFloat64}
+	case jaegermodel.ValueType_BINARY:
+		value.Value = &commonpb.AnyValue_BytesValue{BytesValue: kv.VBinary}
+	default:
+		value.Value = &commonpb.AnyValue_StringValue{StringValue: kv.VStr}
+	}
+	return &commonpb.KeyValue{Key: kv.Key, Value: value}
+}
+
+// decodeJaegerThrift decodes a Thrift binary-encoded jaeger.thrift Batch into
+// the api_v2 model so both Jaeger transports share one translation.
+func decodeJaegerThrift(body []byte) (*jaegermodel.Batch, error) {
+	r := &thriftReader{buf: body}
+	batch := &jaegermodel.Batch{}
+	err := r.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			process, err := readThriftProcess(r)
+			batch.Process = process
+			return err
+		case id == 2 && typ == thriftList:
+			return r.readList(thriftStruct, func() error {
+				span, err := readThriftSpan(r)
+				if err == nil {
+					batch.Spans = append(batch.Spans, span)
+				}
+				return err
+			})
+		default:
+			return r.skip(typ)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(r.buf) {
+		return nil, fmt.Errorf("%d trailing bytes after batch", len(r.buf)-r.pos)
+	}
+	return batch, nil
+}
+
+func readThriftProcess(r *thriftReader) (*jaegermodel.Process, error) {
+	process := &jaegermodel.Process{}
+	err := r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftString:
+			process.ServiceName, err = r.readString()
+		case id == 2 && typ == thriftList:
+			process.Tags, err = readThriftTags(r)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	return process, err
+}
+
+func readThriftSpan(r *thriftReader) (*jaegermodel.Span, error) {
+	span := &jaegermodel.Span{}
+	var traceLow, traceHigh, spanID, parentID, start, duration int64
+	err := r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI64:
+			traceLow, err = r.readI64()
+		case id == 2 && typ == thriftI64:
+			traceHigh, err = r.readI64()
+		case id == 3 && typ == thriftI64:
+			spanID, err = r.readI64()
+		case id == 4 && typ == thriftI64:
+			parentID, err = r.readI64()
+		case id == 5 && typ == thriftString:
+			span.OperationName, err = r.readString()
+		case id == 6 && typ == thriftList:
+			err = r.readList(thriftStruct, func() error {
+				ref, err := readThriftSpanRef(r)
+				span.References = append(span.References, ref)
+				return err
+			})
+		case id == 7 && typ == thriftI32:
+			var flags int32
+			flags, err = r.readI32()
+			span.Flags = jaegermodel.Flags(flags)
+		case id == 8 && typ == thriftI64:
+			start, err = r.readI64()
+		case id == 9 && typ == thriftI64:
+			duration, err = r.readI64()
+		case id == 10 && typ == thriftList:
+			span.Tags, err = readThriftTags(r)
+		case id == 11 && typ == thriftList:
+			err = r.readList(thriftStruct, func() error {
+				l, err := readThriftLog(r)
+				span.Logs = append(span.Logs, l)
+				return err
+			})
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	span.TraceID = jaegermodel.NewTraceID(uint64(traceHigh), uint64(traceLow))
+	span.SpanID = jaegermodel.NewSpanID(uint64(spanID))
+	span.StartTime = time.UnixMicro(start)
+	span.Duration = time.Duration(duration) * time.Microsecond
+
+	// Thrift carries the parent separately; the api_v2 model expects a CHILD_OF reference.
+	if parentID != 0 && span.ParentSpanID() == 0 {
+		span.References = append([]jaegermodel.SpanRef{{
+			TraceID: span.TraceID,
+			SpanID:  jaegermodel.NewSpanID(uint64(parentID)),
+			RefType: jaegermodel.SpanRefType_CHILD_OF,
+		}}, span.References...)
+	}
+	return span, nil
+}
+
+func readThriftSpanRef(r *thriftReader) (jaegermodel.SpanRef, error) {
+	var ref jaegermodel.SpanRef
+	var refType int32
+	var traceLow, traceHigh, spanID int64
+	err := r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			refType, err = r.readI32()
+		case id == 2 && typ == thriftI64:
+			traceLow, err = r.readI64()
+		case id == 3 && typ == thriftI64:
+			traceHigh, err = r.readI64()
+		case id == 4 && typ == thriftI64:
+			spanID, err = r.readI64()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	ref.RefType = jaegermodel.SpanRefType(refType)
+	ref.TraceID = jaegermodel.NewTraceID(uint64(traceHigh), uint64(traceLow))
+	ref.SpanID = jaegermodel.NewSpanID(uint64(spanID))
+	return ref, err
+}
+
+func readThriftLog(r *thriftReader) (jaegermodel.Log, error) {
+	var l jaegermodel.Log
+	err := r.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 1 && typ == thriftI64:
+			ts, err := r.readI64()
+			l.Timestamp = time.UnixMicro(ts)
+			return err
+		case id == 2 && typ == thriftList:
+			fields, err := readThriftTags(r)
+			l.Fields = fields
+			return err
+		default:
+			return r.skip(typ)
+		}
+	})
+	return l, err
+}
+
+func readThriftTags(r *thriftReader) ([]jaegermodel.KeyValue, error) {
+	var tags []jaegermodel.KeyValue
+	err := r.readList(thriftStruct, func() error {
+		tag, err := readThriftTag(r)
+		tags = append(tags, tag)
+		return err
+	})
+	return tags, err
+}
+
+// Thrift TagType values, which are numbered differently from api_v2's ValueType.
+const (
+	thriftTagString int32 = iota
+	thriftTagDouble
+	thriftTagBool
+	thriftTagLong
+	thriftTagBinary
+)
+
+func readThriftTag(r *thriftReader) (jaegermodel.KeyValue, error) {
+	var kv jaegermodel.KeyValue
+	err := r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftString:
+			kv.Key, err = r.readString()
+		case id == 2 && typ == thriftI32:
+			var vType int32
+			vType, err = r.readI32()
+			switch vType {
+			case thriftTagDouble:
+				kv.VType = jaegermodel.ValueType_FLOAT64
+			case thriftTagBool:
+				kv.VType = jaegermodel.ValueType_BOOL
+			case thriftTagLong:
+				kv.VType = jaegermodel.ValueType_INT64
+			case thriftTagBinary:
+				kv.VType = jaegermodel.ValueType_BINARY
+			default:
+				kv.VType = jaegermodel.ValueType_STRING
+			}
+		case id == 3 && typ == thriftString:
+			kv.VStr, err = r.readString()
+		case id == 4 && typ == thriftDouble:
+			kv.VFloat64, err = r.readDouble()
+		case id == 5 && typ == thriftBool:
+			kv.VBool, err = r.readBool()
+		case id == 6 && typ == thriftI64:
+			kv.VInt64, err = r.readI64()
+		case id == 7 && typ == thriftString:
+			var b []byte
+			b, err = r.readBinary()
+			kv.VBinary = append([]byte(nil), b...)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	return kv, err
+}
@@ -0,0 +1,293 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jaegermodel "github.com/jaegertracing/jaeger-idl/model/v1"
+	"github.com/jaegertracing/jaeger-idl/proto-gen/api_v2"
+	"github.com/phosphor-project/phosphor/pkg/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// thriftWriter encodes the Thrift binary protocol for building test payloads.
+type thriftWriter struct {
+	bytes.Buffer
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, id)
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(thriftString, id)
+	binary.Write(w, binary.BigEndian, int32(len(v)))
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, elemType byte, n int) {
+	w.field(thriftList, id)
+	w.WriteByte(elemType)
+	binary.Write(w, binary.BigEndian, int32(n))
+}
+
+func (w *thriftWriter) stop() {
+	w.WriteByte(thriftStop)
+}
+
+// stringTag writes a jaeger.thrift Tag with a string value.
+func (w *thriftWriter) stringTag(key, value string) {
+	w.str(1, key)
+	w.i32(2, thriftTagString)
+	w.str(3, value)
+	w.stop()
+}
+
+func testJaegerThriftBatch() []byte {
+	w := &thriftWriter{}
+
+	// Batch.process
+	w.field(thriftStruct, 1)
+	w.str(1, "billing")
+	w.list(2, thriftStruct, 2)
+	w.stringTag("hostname", "billing-7f9c")
+	w.str(1, "jaeger.version")
+	w.i32(2, thriftTagLong)
+	w.i64(6, 1)
+	w.stop()
+	w.stop()
+
+	// Batch.spans
+	w.list(2, thriftStruct, 1)
+	w.i64(1, 0x463ac35c9f6413ad)
+	w.i64(2, 0)
+	w.i64(3, 0x0a)
+	w.i64(4, 0x05)
+	w.str(5, "charge")
+	w.i32(7, 1)
+	w.i64(8, 1556604172355737)
+	w.i64(9, 2500)
+	w.list(10, thriftStruct, 2)
+	w.stringTag("span.kind", "server")
+	w.str(1, "error")
+	w.i32(2, thriftTagBool)
+	w.field(thriftBool, 5)
+	w.WriteByte(1)
+	w.stop()
+	w.list(11, thriftStruct, 1)
+	w.i64(1, 1556604172355800)
+	w.list(2, thriftStruct, 2)
+	w.stringTag("event", "retry")
+	w.stringTag("attempt", "2")
+	w.stop()
+	w.stop()
+
+	w.stop()
+	return w.Bytes()
+}
+
+func TestJaegerThriftHTTP(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	handler := newHTTPHandler(r)
+
+	req := httptest.NewRequest(http.MethodPost, jaegerTracesPath, bytes.NewReader(testJaegerThriftBatch()))
+	req.Header.Set("Content-Type", contentTypeThrift)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+
+	traces := r.GetTraces()
+	if len(traces) != 1 {
+		t.Fatalf("GetTraces() returned %d spans, want 1", len(traces))
+	}
+	span := traces[0]
+
+	if span.TraceID != "0000000000000000463ac35c9f6413ad" || span.SpanID != "000000000000000a" {
+		t.Errorf("IDs = %q/%q", span.TraceID, span.SpanID)
+	}
+	if span.ParentSpanID != "0000000000000005" {
+		t.Errorf("ParentSpanID = %q, want parentSpanId field", span.ParentSpanID)
+	}
+	if span.Kind != models.SpanKindServer || span.StatusCode != models.StatusCodeError {
+		t.Errorf("kind/status = %q/%q, want server/error", span.Kind, span.StatusCode)
+	}
+	if span.DurationMs != 2.5 {
+		t.Errorf("DurationMs = %f, want 2.5", span.DurationMs)
+	}
+	if span.Resource.ServiceName != "billing" {
+		t.Errorf("ServiceName = %q, want 'billing'", span.Resource.ServiceName)
+	}
+	if got := attributeValue(span.Resource.Attributes, "hostname"); got != "billing-7f9c" {
+		t.Errorf("resource hostname = %v, want process tag", got)
+	}
+	if got := attributeValue(span.Resource.Attributes, "jaeger.version"); got != int64(1) {
+		t.Errorf("resource jaeger.version = %v, want int 1", got)
+	}
+	if got := attributeValue(span.Attributes, jaegerTagSpanKind); got != nil {
+		t.Errorf("span.kind tag kept as attribute: %v", got)
+	}
+
+	if len(span.Events) != 1 || span.Events[0].Name != "retry" {
+		t.Fatalf("Events = %+v, want one 'retry' log", span.Events)
+	}
+	if got := attributeValue(span.Events[0].Attributes, "attempt"); got != "2" {
+		t.Errorf("event attempt = %v, want '2'", got)
+	}
+}
+
+func TestJaegerThriftRejectsMalformed(t *testing.T) {
+	handler := newHTTPHandler(NewOTLPReceiver(DefaultConfig()))
+	valid := testJaegerThriftBatch()
+
+	// An unknown field holding lists nested a million deep.
+	nested := append([]byte{thriftList, 0, 99}, nestedThriftLists(1_000_000)...)
+	nested = append(nested, thriftStop)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wantStatus  int
+	}{
+		{"wrong content type", contentTypeJSON, valid, http.StatusUnsupportedMediaType},
+		{"truncated", contentTypeThrift, valid[:len(valid)/2], http.StatusBadRequest},
+		{"huge list", contentTypeThrift, []byte{thriftList, 0, 2, thriftStruct, 0x7f, 0xff, 0xff, 0xff}, http.StatusBadRequest},
+		{"deeply nested lists", contentTypeThrift, nested, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, jaegerTracesPath, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestJaegerGRPCCollector(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "otlp.sock")
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + sock}
+	config.HTTPListenAddresses = []string{"unix://" + filepath.Join(filepath.Dir(sock), "http.sock")}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	conn, err := grpc.NewClient("unix://"+sock,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodecV2(serverCodec{})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Unix(1556604172, 0)
+	_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(ctx, &api_v2.PostSpansRequest{
+		Batch: jaegermodel.Batch{
+			Process: &jaegermodel.Process{
+				ServiceName: "inventory",
+				Tags:        []jaegermodel.KeyValue{jaegermodel.String("ip", "10.0.0.7")},
+			},
+			Spans: []*jaegermodel.Span{{
+				TraceID:       jaegermodel.NewTraceID(1, 2),
+				SpanID:        jaegermodel.NewSpanID(3),
+				OperationName: "reserve",
+				StartTime:     start,
+				Duration:      time.Second,
+				Tags:          []jaegermodel.KeyValue{jaegermodel.String("span.kind", "client")},
+				References: []jaegermodel.SpanRef{
+					jaegermodel.NewChildOfRef(jaegermodel.NewTraceID(1, 2), jaegermodel.NewSpanID(1)),
+					jaegermodel.NewFollowsFromRef(jaegermodel.NewTraceID(9, 9), jaegermodel.NewSpanID(9)),
+				},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("PostSpans() error = %v", err)
+	}
+
+	traces := r.GetTraces()
+	if len(traces) != 1 {
+		t.Fatalf("GetTraces() returned %d spans, want 1", len(traces))
+	}
+	span := traces[0]
+	if span.TraceID != "00000000000000010000000000000002" || span.ParentSpanID != "0000000000000001" {
+		t.Errorf("IDs = %q parent %q", span.TraceID, span.ParentSpanID)
+	}
+	if span.Kind != models.SpanKindClient || span.Resource.ServiceName != "inventory" {
+		t.Errorf("span = %+v, want client span from inventory", span)
+	}
+	if len(span.Links) != 1 || span.Links[0].TraceID != "00000000000000090000000000000009" {
+		t.Errorf("Links = %+v, want the follows-from reference", span.Links)
+	}
+	if span.DurationMs != 1000 {
+		t.Errorf("DurationMs = %f, want 1000", span.DurationMs)
+	}
+}
+
+// nestedThriftLists encodes depth lists, each holding the next, around an
+// empty innermost list.
+func nestedThriftLists(depth int) []byte {
+	var b []byte
+	for i := 1; i < depth; i++ {
+		b = append(b, thriftList, 0, 0, 0, 1)
+	}
+	return append(b, thriftList, 0, 0, 0, 0)
+}
+
+func TestThriftNestingLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     byte
+		value   []byte
+		wantErr error
+	}{
+		{"lists at the limit", thriftList, nestedThriftLists(maxThriftDepth), nil},
+		{"lists past the limit", thriftList, nestedThriftLists(maxThriftDepth + 1), errThriftTooDeep},
+		{"map of lists past the limit", thriftMap, append([]byte{thriftByte, thriftList, 0, 0, 0, 1, 0}, nestedThriftLists(maxThriftDepth)...), errThriftTooDeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &thriftReader{buf: tt.value}
+			if err := r.skip(tt.typ); !errors.Is(err, tt.wantErr) {
+				t.Errorf("skip() error = %v, want %v", err, tt.wantErr)
+			}
+			if r.depth != 0 {
+				t.Errorf("depth = %d after skip, want 0", r.depth)
+			}
+		})
+	}
+}
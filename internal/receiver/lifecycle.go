@@ -0,0 +1,171 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ReceiverState is a stage in the receiver lifecycle.
+type ReceiverState string
+
+// Receiver lifecycle states.
+const (
+	StateStarting  ReceiverState = "starting"
+	StateListening ReceiverState = "listening" // All configured listeners are serving
+	StateDegraded  ReceiverState = "degraded"  // Serving gRPC, but a component failed or a fallback port is in use
+	StateFailed    ReceiverState = "failed"    // Start returned an error
+	StateStopped   ReceiverState = "stopped"
+)
+
+// ReceiverStatus describes the receiver's lifecycle state and where it listens.
+type ReceiverStatus struct {
+	State         ReceiverState `json:"state"`
+	Reason        string        `json:"reason,omitempty"` // Why the receiver is degraded or failed
+	GRPCAddresses []string      `json:"grpcAddresses"`
+	HTTPAddresses []string      `json:"httpAddresses"`
+	Since         time.Time     `json:"since"`
+}
+
+// Start begins listening for OTLP data on the configured addresses. It fails
+// only if the configuration is invalid or no gRPC listener can be opened;
+// problems with other listeners leave the receiver degraded, as reported
+// by GetStatus.
+func (r *OTLPReceiver) Start() error {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+	return r.startLocked()
+}
+
+// startLocked is Start for callers that hold configMu.
+func (r *OTLPReceiver) startLocked() error {
+	r.stateMu.Lock()
+	r.reasons = nil
+	r.stateMu.Unlock()
+	r.setState(StateStarting, "")
+
+	if err := r.start(); err != nil {
+		r.setState(StateFailed, err.Error())
+		return err
+	}
+
+	grpcAddresses, httpAddresses := r.Addresses(), r.HTTPAddresses()
+	r.stateMu.Lock()
+	r.status.GRPCAddresses = grpcAddresses
+	r.status.HTTPAddresses = httpAddresses
+	reasons := strings.Join(r.reasons, "; ")
+	r.stateMu.Unlock()
+
+	if reasons != "" {
+		r.setState(StateDegraded, reasons)
+	} else {
+		r.setState(StateListening, "")
+	}
+	return nil
+}
+
+// GetStatus returns the receiver's lifecycle state.
+func (r *OTLPReceiver) GetStatus() ReceiverStatus {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	return r.status
+}
+
+// OnStateChange registers a named callback for lifecycle state changes,
+// delivered in order from a dedicated goroutine. The returned function
+// unsubscribes the callback.
+func (r *OTLPReceiver) OnStateChange(name string, callback func(ReceiverStatus)) func() {
+	sub := r.lifecycle.Subscribe(name, callback)
+	return sub.Unsubscribe
+}
+
+// setState records a transition and notifies subscribers.
+func (r *OTLPReceiver) setState(state ReceiverState, reason string) {
+	r.stateMu.Lock()
+	if r.status.State != state {
+		r.status.Since = time.Now()
+	}
+	r.status.State = state
+	r.status.Reason = reason
+	status := r.status
+	r.stateMu.Unlock()
+
+	if reason != "" {
+		log.Printf("[Phosphor] Receiver %s: %s", state, reason)
+	} else {
+		log.Printf("[Phosphor] Receiver %s", state)
+	}
+	r.lifecycle.Publish(status)
+}
+
+// setStopped marks the receiver stopped and forgets its addresses.
+func (r *OTLPReceiver) setStopped() {
+	r.stateMu.Lock()
+	r.status.GRPCAddresses = nil
+	r.status.HTTPAddresses = nil
+	r.stateMu.Unlock()
+	r.setState(StateStopped, "")
+}
+
+// degrade records a component failure. Once the receiver is serving it
+// moves to the degraded state immediately; during Start the reasons are
+// collected and reported when Start completes.
+func (r *OTLPReceiver) degrade(format string, args ...any) {
+	reason := fmt.Sprintf(format, args...)
+	r.recordError("%s", reason)
+
+	r.stateMu.Lock()
+	r.reasons = append(r.reasons, reason)
+	serving := r.status.State == StateListening || r.status.State == StateDegraded
+	reasons := strings.Join(r.reasons, "; ")
+	r.stateMu.Unlock()
+
+	if serving {
+		r.setState(StateDegraded, reasons)
+	}
+}
+
+// listenPort listens on every interface at port. When the port is taken and
+// Config.PortFallback is set, it listens on a free port chosen by the OS
+// instead and degrades the receiver so the new address is reported.
+func (r *OTLPReceiver) listenPort(name string, port int) ([]net.Listener, error) {
+	l, err := listenOn(fmt.Sprintf(":%d", port))
+	if err == nil {
+		return []net.Listener{l}, nil
+	}
+	if !r.config.PortFallback || !errors.Is(err, syscall.EADDRINUSE) {
+		return nil, err
+	}
+
+	fallback, fallbackErr := listenOn(":0")
+	if fallbackErr != nil {
+		return nil, err
+	}
+	r.degrade("%s port %d is in use, listening on %s instead", name, port, listenerAddress(fallback))
+	return []net.Listener{fallback}, nil
+}
+
+// drainGRPC stops the gRPC server gracefully, closing whatever connections
+// remain once ctx expires.
+func drainGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Printf("[Phosphor] gRPC drain timed out, closing remaining connections")
+		server.Stop()
+		<-stopped
+	}
+}
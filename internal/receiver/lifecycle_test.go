@@ -0,0 +1,152 @@
+package receiver
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// takenPort occupies a port on every interface for the duration of the test.
+func takenPort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestPortFallback(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = takenPort(t)
+	config.HTTPPort = takenPort(t)
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	status := r.GetStatus()
+	if status.State != StateDegraded {
+		t.Errorf("State = %q, want %q", status.State, StateDegraded)
+	}
+	if !strings.Contains(status.Reason, "gRPC port") || !strings.Contains(status.Reason, "OTLP/HTTP port") {
+		t.Errorf("Reason = %q, want both fallbacks reported", status.Reason)
+	}
+	if len(status.GRPCAddresses) != 1 || strings.HasSuffix(status.GRPCAddresses[0], ":"+strconv.Itoa(config.Port)) {
+		t.Errorf("GRPCAddresses = %v, want a fallback port", status.GRPCAddresses)
+	}
+	if len(status.HTTPAddresses) != 1 {
+		t.Errorf("HTTPAddresses = %v, want the fallback address", status.HTTPAddresses)
+	}
+}
+
+func TestPortTakenWithoutFallback(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = takenPort(t)
+	config.PortFallback = false
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err == nil {
+		r.Stop()
+		t.Fatal("Start() error = nil, want the port conflict")
+	}
+	if status := r.GetStatus(); status.State != StateFailed || !strings.Contains(status.Reason, "address already in use") {
+		t.Errorf("status = %+v, want failed with the listen error", status)
+	}
+}
+
+func TestStateChanges(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(dir, "otlp.sock")}
+	config.HTTPListenAddresses = []string{"unix://" + filepath.Join(dir, "http.sock")}
+	config.StatsD = StatsDConfig{ListenAddress: "256.0.0.1:0"}
+	r := NewOTLPReceiver(config)
+
+	var mu sync.Mutex
+	var states []ReceiverState
+	unsubscribe := r.OnStateChange("test", func(status ReceiverStatus) {
+		mu.Lock()
+		states = append(states, status.State)
+		mu.Unlock()
+	})
+	defer unsubscribe()
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if status := r.GetStatus(); status.State != StateDegraded || !strings.HasPrefix(status.Reason, "StatsD disabled") {
+		t.Errorf("status = %+v, want degraded by the StatsD listener", status)
+	}
+	if got := r.GetReceiverStats().Errors; got != 1 {
+		t.Errorf("Errors = %d, want 1", got)
+	}
+	r.Stop()
+
+	want := []ReceiverState{StateStarting, StateDegraded, StateStopped}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(states)
+		mu.Unlock()
+		if n >= len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Errorf("states[%d] = %q, want %q", i, states[i], want[i])
+		}
+	}
+}
+
+func TestStopDrainTimeout(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "otlp.sock")
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + sock}
+	config.HTTPListenAddresses = []string{"unix://" + sock + ".http"}
+	config.DrainTimeout = 100 * time.Millisecond
+	config.Faults = FaultConfig{LatencyMs: 30_000}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	conn, err := grpc.NewClient("unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go coltracepb.NewTraceServiceClient(conn).Export(context.Background(), testTraceRequest())
+
+	// Wait until the export is stuck in the injected latency.
+	deadline := time.Now().Add(5 * time.Second)
+	for r.GetReceiverStats().FaultsInjected == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	start := time.Now()
+	r.Stop()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Stop() took %v, want it bounded by the drain timeout", elapsed)
+	}
+	if got := r.GetStatus().State; got != StateStopped {
+		t.Errorf("State = %q, want %q", got, StateStopped)
+	}
+}
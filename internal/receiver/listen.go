@@ -0,0 +1,122 @@
+package receiver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// Listen address schemes accepted in Config.ListenAddresses and Config.HTTPListenAddresses.
+const (
+	schemeUnix = "unix://"
+	schemeTCP  = "tcp://"
+)
+
+// parseListenAddress splits a listen address into a network and address.
+// Accepted forms are "host:port", "tcp://host:port" and "unix:///path/to.sock".
+func parseListenAddress(addr string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(addr, schemeUnix):
+		path := strings.TrimPrefix(addr, schemeUnix)
+		if path == "" {
+			return "", "", fmt.Errorf("listen address %q has no socket path", addr)
+		}
+		return "unix", path, nil
+	case strings.HasPrefix(addr, schemeTCP):
+		return "tcp", strings.TrimPrefix(addr, schemeTCP), nil
+	case strings.Contains(addr, "://"):
+		return "", "", fmt.Errorf("unsupported listen address scheme in %q", addr)
+	default:
+		return "tcp", addr, nil
+	}
+}
+
+// listenAll opens a listener for every address. If any fails, the listeners
+// opened so far are closed and the error is returned.
+func listenAll(addrs []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		l, err := listenOn(addr)
+		if err != nil {
+			closeListeners(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// listenOn opens a single listener, removing a stale socket file left behind
+// by a previous run for unix addresses.
+func listenOn(addr string) (net.Listener, error) {
+	network, address, err := parseListenAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" {
+		if err := removeStaleSocket(address); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return l, nil
+}
+
+// removeStaleSocket deletes path if it is a leftover socket file. Regular files
+// are never removed so a misconfigured path cannot destroy data.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat socket %s: %w", path, err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("refusing to replace non-socket file %s", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// closeListeners closes listeners and removes any unix socket files they own.
+func closeListeners(listeners []net.Listener) {
+	for _, l := range listeners {
+		l.Close()
+		if addr, ok := l.Addr().(*net.UnixAddr); ok {
+			// net.UnixListener unlinks on Close, but be explicit in case the
+			// listener was wrapped or unlinking was disabled.
+			os.Remove(addr.Name)
+		}
+	}
+}
+
+// listenerAddress formats a bound listener address in the Config form.
+func listenerAddress(l net.Listener) string {
+	if addr, ok := l.Addr().(*net.UnixAddr); ok {
+		return schemeUnix + addr.Name
+	}
+	return l.Addr().String()
+}
+
+// peerAddress formats a remote address for client identification. Unix socket
+// peers are usually unnamed, so they are grouped under "unix".
+func peerAddress(addr net.Addr) string {
+	if addr == nil {
+		return "unknown"
+	}
+	if s := addr.String(); s != "" && s != "@" {
+		return s
+	}
+	return addr.Network()
+}
@@ -0,0 +1,112 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseListenAddress(t *testing.T) {
+	tests := []struct {
+		addr        string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		{":4317", "tcp", ":4317", false},
+		{"tcp://127.0.0.1:4317", "tcp", "127.0.0.1:4317", false},
+		{"unix:///tmp/phosphor.sock", "unix", "/tmp/phosphor.sock", false},
+		{"unix://", "", "", true},
+		{"udp://:4317", "", "", true},
+	}
+
+	for _, tt := range tests {
+		network, address, err := parseListenAddress(tt.addr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseListenAddress(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			continue
+		}
+		if network != tt.wantNetwork || address != tt.wantAddress {
+			t.Errorf("parseListenAddress(%q) = %q, %q, want %q, %q", tt.addr, network, address, tt.wantNetwork, tt.wantAddress)
+		}
+	}
+}
+
+func TestListenOnRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(path, []byte("keep me"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := listenOn("unix://" + path); err == nil {
+		t.Fatal("listenOn() succeeded over a regular file")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("regular file was removed: %v", err)
+	}
+}
+
+func TestUnixSocketReceiver(t *testing.T) {
+	dir := t.TempDir()
+	grpcSock := filepath.Join(dir, "otlp-grpc.sock")
+	httpSock := filepath.Join(dir, "otlp-http.sock")
+
+	// A leftover socket from a previous run must not prevent startup.
+	stale, err := net.Listen("unix", grpcSock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + grpcSock}
+	config.HTTPListenAddresses = []string{"unix://" + httpSock}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if got := r.Addresses(); len(got) != 1 || got[0] != "unix://"+grpcSock {
+		t.Errorf("Addresses() = %v, want [unix://%s]", got, grpcSock)
+	}
+	if got := r.HTTPAddresses(); len(got) != 1 || got[0] != "unix://"+httpSock {
+		t.Errorf("HTTPAddresses() = %v, want [unix://%s]", got, httpSock)
+	}
+
+	body, err := proto.Marshal(testTraceRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", httpSock)
+		},
+	}}
+	resp, err := client.Post("http://phosphor/v1/traces", contentTypeProtobuf, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST over unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if traces := r.GetTraces(); len(traces) != 1 || traces[0].Source != "http://unix" {
+		t.Errorf("GetTraces() = %+v, want 1 span from http://unix", traces)
+	}
+
+	r.Stop()
+	for _, path := range []string{grpcSock, httpSock} {
+		if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("socket %s still exists after Stop(): %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package receiver
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// Structured log line formats.
+const (
+	LogFormatAuto   = ""       // Detect JSON or logfmt per line, falling back to plain text
+	LogFormatJSON   = "json"   // One JSON object per line
+	LogFormatLogfmt = "logfmt" // key=value pairs
+	LogFormatText   = "text"   // Unstructured; the whole line is the body
+)
+
+// LogKeyMapping names the fields of structured log lines that carry
+// well-known LogRecord fields. Each list is checked in order and the first key
+// present wins; empty lists use the defaults.
+type LogKeyMapping struct {
+	Timestamp []string `yaml:"timestamp"` // default: time, timestamp, ts, @timestamp
+	Severity  []string `yaml:"severity"`  // default: level, severity, lvl, log.level
+	Message   []string `yaml:"message"`   // default: msg, message, log
+	TraceID   []string `yaml:"trace_id"`  // default: trace_id, traceId, trace.id, traceid
+	SpanID    []string `yaml:"span_id"`   // default: span_id, spanId, span.id, spanid
+}
+
+// DefaultLogKeyMapping returns the field names used by common logging libraries.
+func DefaultLogKeyMapping() LogKeyMapping {
+	return LogKeyMapping{
+		Timestamp: []string{"time", "timestamp", "ts", "@timestamp"},
+		Severity:  []string{"level", "severity", "lvl", "log.level"},
+		Message:   []string{"msg", "message", "log"},
+		TraceID:   []string{"trace_id", "traceId", "trace.id", "traceid"},
+		SpanID:    []string{"span_id", "spanId", "span.id", "spanid"},
+	}
+}
+
+// withDefaults fills empty lists from DefaultLogKeyMapping.
+func (m LogKeyMapping) withDefaults() LogKeyMapping {
+	d := DefaultLogKeyMapping()
+	if len(m.Timestamp) == 0 {
+		m.Timestamp = d.Timestamp
+	}
+	if len(m.Severity) == 0 {
+		m.Severity = d.Severity
+	}
+	if len(m.Message) == 0 {
+		m.Message = d.Message
+	}
+	if len(m.TraceID) == 0 {
+		m.TraceID = d.TraceID
+	}
+	if len(m.SpanID) == 0 {
+		m.SpanID = d.SpanID
+	}
+	return m
+}
+
+// logTimestampLayouts are tried in order for string timestamps.
+var logTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05,999",
+}
+
+// parseLogLine parses a JSON or logfmt line into a map of fields. ok is false
+// when the line is not in the requested format, or in neither format when
+// format is LogFormatAuto.
+func parseLogLine(line, format string) (fields map[string]interface{}, ok bool) {
+	switch format {
+	case LogFormatJSON:
+		return parseJSONLine(line)
+	case LogFormatLogfmt:
+		return parseLogfmt(line)
+	case LogFormatText:
+		return nil, false
+	}
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		return parseJSONLine(line)
+	}
+	// Only treat free text as logfmt when it starts with a key=value pair,
+	// so prose such as "listening on port=8080" stays plain text.
+	if first, _, _ := strings.Cut(trimmed, " "); strings.Contains(first, "=") {
+		return parseLogfmt(line)
+	}
+	return nil, false
+}
+
+// parseJSONLine decodes a JSON object, keeping integers exact.
+func parseJSONLine(line string) (map[string]interface{}, bool) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil || fields == nil {
+		return nil, false
+	}
+	return normalizeJSON(fields).(map[string]interface{}), true
+}
+
+// normalizeJSON converts json.Number values to int64 or float64.
+func normalizeJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, elem := range v {
+			v[k] = normalizeJSON(elem)
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = normalizeJSON(elem)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// parseLogfmt parses `key=value key="quoted value" flag` pairs. A line is only
+// treated as logfmt if at least one key has a value.
+func parseLogfmt(line string) (map[string]interface{}, bool) {
+	fields := make(map[string]interface{})
+	hasValue := false
+	s := line
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			break
+		}
+
+		end := strings.IndexAny(s, "= \t")
+		if end == 0 {
+			return nil, false
+		}
+		if end < 0 || s[end] != '=' {
+			// A bare key is a boolean flag.
+			if end < 0 {
+				end = len(s)
+			}
+			fields[s[:end]] = true
+			s = s[end:]
+			continue
+		}
+		key := s[:end]
+		s = s[end+1:]
+		hasValue = true
+
+		if strings.HasPrefix(s, `"`) {
+			value, rest, err := unquoteLogfmt(s)
+			if err != nil {
+				return nil, false
+			}
+			fields[key] = value
+			s = rest
+			continue
+		}
+		end = strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		fields[key] = s[:end]
+		s = s[end:]
+	}
+	if !hasValue {
+		return nil, false
+	}
+	return fields, true
+}
+
+// unquoteLogfmt reads a double-quoted value and returns it with the rest of the line.
+func unquoteLogfmt(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[i])
+				}
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted value")
+}
+
+// structuredLogRecord maps parsed fields onto a log record using keys. Fields
+// that are not mapped become attributes; trace and span IDs that are not
+// valid hex of the right length are kept as attributes rather than dropped.
+func structuredLogRecord(fields map[string]interface{}, keys LogKeyMapping, record *logspb.LogRecord) {
+	take := func(names []string) (string, interface{}, bool) {
+		for _, name := range names {
+			if v, ok := fields[name]; ok {
+				return name, v, true
+			}
+		}
+		return "", nil, false
+	}
+	consumed := make(map[string]bool)
+
+	if name, v, ok := take(keys.Timestamp); ok {
+		if ts, ok := parseLogTimestamp(v); ok {
+			record.TimeUnixNano = uint64(ts.UnixNano())
+			consumed[name] = true
+		}
+	}
+	if name, v, ok := take(keys.Severity); ok {
+		switch v := v.(type) {
+		case string:
+			record.SeverityText = v
+			record.SeverityNumber = severityFromText(v)
+			consumed[name] = true
+		case int64:
+			if v >= 1 && v <= 24 {
+				record.SeverityNumber = logspb.SeverityNumber(v)
+				consumed[name] = true
+			}
+		}
+	}
+	if name, v, ok := take(keys.Message); ok {
+		if s, ok := v.(string); ok {
+			record.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+			consumed[name] = true
+		}
+	}
+	if name, v, ok := take(keys.TraceID); ok {
+		if id, ok := hexID(v, 16); ok {
+			record.TraceId = id
+			consumed[name] = true
+		}
+	}
+	if name, v, ok := take(keys.SpanID); ok {
+		if id, ok := hexID(v, 8); ok {
+			record.SpanId = id
+			consumed[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if !consumed[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		record.Attributes = append(record.Attributes, &commonpb.KeyValue{Key: name, Value: anyValue(fields[name])})
+	}
+}
+
+// hexID decodes a hex trace or span ID of exactly size bytes. All-zero IDs
+// are treated as absent.
+func hexID(v interface{}, size int) ([]byte, bool) {
+	s, ok := v.(string)
+	if !ok || len(s) != size*2 {
+		return nil, false
+	}
+	id, err := hex.DecodeString(s)
+	if err != nil || bytes.Equal(id, make([]byte, size)) {
+		return nil, false
+	}
+	return id, true
+}
+
+// parseLogTimestamp accepts RFC 3339 and similar strings, and Unix epoch
+// numbers in seconds, milliseconds, microseconds or nanoseconds.
+func parseLogTimestamp(v interface{}) (time.Time, bool) {
+	switch v := v.(type) {
+	case string:
+		for _, layout := range logTimestampLayouts {
+			if ts, err := time.Parse(layout, v); err == nil {
+				return ts, true
+			}
+		}
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return intEpochTime(i), true
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return floatEpochTime(f), true
+	case int64:
+		return intEpochTime(v), true
+	case float64:
+		return floatEpochTime(v), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// intEpochTime converts an integer epoch without going through float64,
+// which would round nanosecond timestamps.
+func intEpochTime(epoch int64) time.Time {
+	switch abs := math.Abs(float64(epoch)); {
+	case abs < 1e11:
+		return time.Unix(epoch, 0)
+	case abs < 1e14:
+		return time.UnixMilli(epoch)
+	case abs < 1e17:
+		return time.UnixMicro(epoch)
+	default:
+		return time.Unix(0, epoch)
+	}
+}
+
+// floatEpochTime converts a fractional epoch, inferring the unit from the
+// magnitude; 1e11 seconds is far in the future.
+func floatEpochTime(epoch float64) time.Time {
+	switch abs := math.Abs(epoch); {
+	case abs < 1e11:
+		sec, frac := math.Modf(epoch)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+	case abs < 1e14:
+		return time.Unix(0, int64(epoch*1e6))
+	case abs < 1e17:
+		return time.Unix(0, int64(epoch*1e3))
+	default:
+		return time.Unix(0, int64(epoch))
+	}
+}
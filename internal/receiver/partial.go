@@ -0,0 +1,76 @@
+package receiver
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+// Rejection tracking limits.
+const (
+	rejectionCapacity  = 500 // Stored rejection reasons
+	maxPartialMessages = 3   // Distinct reasons quoted in a partial-success message
+)
+
+// rejectionTracker accumulates rejected items for a single export request.
+type rejectionTracker struct {
+	count    int64
+	messages []string
+}
+
+// add counts n rejected items for the given reason.
+func (t *rejectionTracker) add(n int64, reason string) {
+	t.count += n
+	for _, m := range t.messages {
+		if m == reason {
+			return
+		}
+	}
+	t.messages = append(t.messages, reason)
+}
+
+// empty reports whether nothing was rejected. Export handlers return a
+// partial success whenever it is false, even if no items were counted: a
+// metric without data points is dropped with a count of zero, and OTLP treats
+// a zero count with an error message as a warning to the exporter.
+func (t *rejectionTracker) empty() bool {
+	return len(t.messages) == 0
+}
+
+// message summarizes the rejection reasons for the partial_success field.
+func (t *rejectionTracker) message() string {
+	if len(t.messages) == 0 {
+		return ""
+	}
+	if len(t.messages) <= maxPartialMessages {
+		return strings.Join(t.messages, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(t.messages[:maxPartialMessages], "; "), len(t.messages)-maxPartialMessages)
+}
+
+// recordRejection stores why an item was refused so it can be inspected later.
+func (r *OTLPReceiver) recordRejection(signal models.SignalType, serviceName string, err error) {
+	r.rejections.Push(models.Rejection{
+		Signal:      signal,
+		ServiceName: serviceName,
+		Reason:      err.Error(),
+		Timestamp:   time.Now(),
+	})
+}
+
+// recordError logs a failure in the ingestion pipeline and counts it in
+// ReceiverStats.Errors.
+func (r *OTLPReceiver) recordError(format string, args ...any) {
+	log.Printf("[Phosphor] "+format, args...)
+	r.statsMu.Lock()
+	r.stats.Errors++
+	r.statsMu.Unlock()
+}
+
+// GetRejections returns the most recent rejection reasons, oldest first.
+func (r *OTLPReceiver) GetRejections() []models.Rejection {
+	return r.rejections.GetAll()
+}
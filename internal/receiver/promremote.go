@@ -0,0 +1,593 @@
+package receiver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWritePath is the conventional Prometheus remote-write receiver endpoint.
+const remoteWritePath = "/api/v1/write"
+
+// Well-known Prometheus labels and series suffixes.
+const (
+	promLabelName     = "__name__"
+	promLabelJob      = "job"
+	promLabelInstance = "instance"
+	promLabelLe       = "le"
+	promLabelQuantile = "quantile"
+
+	promSuffixBucket = "_bucket"
+	promSuffixSum    = "_sum"
+	promSuffixCount  = "_count"
+	promSuffixTotal  = "_total"
+)
+
+// Resource attributes derived from the job and instance labels.
+const attrServiceInstanceID = "service.instance.id"
+
+// Metric types carried in remote-write v1 metadata (prometheus.MetricMetadata.MetricType).
+const (
+	promTypeUnknown   int32 = 0
+	promTypeCounter   int32 = 1
+	promTypeGauge     int32 = 2
+	promTypeHistogram int32 = 3
+	promTypeSummary   int32 = 5
+)
+
+// promStaleNaN is the NaN bit pattern Prometheus writes to mark a series stale.
+const promStaleNaN uint64 = 0x7ff0000000000002
+
+// promSeries is a decoded remote-write TimeSeries.
+type promSeries struct {
+	labels  map[string]string
+	samples []promSample
+}
+
+// promSample is a single (timestamp, value) pair.
+type promSample struct {
+	value       float64
+	timestampMs int64
+}
+
+// promMetadata is the type and description sent for a metric family.
+type promMetadata struct {
+	typ  int32
+	help string
+	unit string
+}
+
+// handleRemoteWrite serves POST /api/v1/write with a snappy-compressed
+// Prometheus remote-write v1 WriteRequest. Series are converted to OTLP and
+// passed to the metrics handler.
+func (h *httpHandler) handleRemoteWrite(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		mediaType, params, err := mime.ParseMediaType(ct)
+		if err != nil || mediaType != contentTypeProtobuf || (params["proto"] != "" && params["proto"] != "prometheus.WriteRequest") {
+			http.Error(w, fmt.Sprintf("unsupported content type %q, only remote-write v1 is accepted", ct), http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+	if enc := req.Header.Get("Content-Encoding"); enc != "" && enc != "snappy" {
+		http.Error(w, fmt.Sprintf("unsupported content encoding %q", enc), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := readSnappyBody(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	series, metadata, err := decodeWriteRequest(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid remote-write request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	exportReq := remoteWriteToOTLP(series, metadata)
+	if len(exportReq.ResourceMetrics) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if _, err := h.receiver.metricsService.Export(exportContext(req), exportReq); err != nil {
+		st := status.Convert(err)
+		setRetryAfter(w, st)
+		http.Error(w, st.Message(), httpStatusFromCode(st.Code()))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readSnappyBody reads and decompresses a snappy block-encoded body, bounding
+// both the compressed and decoded sizes.
+func readSnappyBody(req *http.Request) ([]byte, error) {
+	compressed, err := io.ReadAll(io.LimitReader(req.Body, maxHTTPBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(compressed) > maxHTTPBodySize {
+		return nil, fmt.Errorf("body exceeds %d bytes", maxHTTPBodySize)
+	}
+
+	n, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy body: %w", err)
+	}
+	if n > maxHTTPBodySize {
+		return nil, fmt.Errorf("decoded body exceeds %d bytes", maxHTTPBodySize)
+	}
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy body: %w", err)
+	}
+	return body, nil
+}
+
+// errMalformedProto reports a wire-format error while decoding a WriteRequest.
+var errMalformedProto = errors.New("malformed protobuf")
+
+// walkProto calls field for each field in a protobuf message. Length-delimited
+// values are passed as data; varint and fixed64 values as v.
+func walkProto(b []byte, field func(num protowire.Number, typ protowire.Type, data []byte, v uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errMalformedProto
+		}
+		b = b[n:]
+
+		var (
+			data []byte
+			v    uint64
+		)
+		switch typ {
+		case protowire.BytesType:
+			data, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			v, n = protowire.ConsumeFixed64(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return errMalformedProto
+		}
+		b = b[n:]
+
+		if err := field(num, typ, data, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeWriteRequest decodes a prometheus.WriteRequest into its series and
+// per-family metadata.
+func decodeWriteRequest(b []byte) ([]promSeries, map[string]promMetadata, error) {
+	var series []promSeries
+	metadata := make(map[string]promMetadata)
+
+	err := walkProto(b, func(num protowire.Number, typ protowire.Type, data []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1: // timeseries
+			s, err := decodeTimeSeries(data)
+			if err != nil {
+				return err
+			}
+			series = append(series, s)
+		case 3: // metadata
+			family, md, err := decodeMetadata(data)
+			if err != nil {
+				return err
+			}
+			metadata[family] = md
+		}
+		return nil
+	})
+	return series, metadata, err
+}
+
+func decodeTimeSeries(b []byte) (promSeries, error) {
+	s := promSeries{labels: make(map[string]string)}
+	err := walkProto(b, func(num protowire.Number, typ protowire.Type, data []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1: // labels
+			var name, value string
+			err := walkProto(data, func(num protowire.Number, typ protowire.Type, data []byte, _ uint64) error {
+				if typ == protowire.BytesType {
+					switch num {
+					case 1:
+						name = string(data)
+					case 2:
+						value = string(data)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			s.labels[name] = value
+		case 2: // samples
+			var sample promSample
+			err := walkProto(data, func(num protowire.Number, typ protowire.Type, _ []byte, v uint64) error {
+				switch {
+				case num == 1 && typ == protowire.Fixed64Type:
+					sample.value = math.Float64frombits(v)
+				case num == 2 && typ == protowire.VarintType:
+					sample.timestampMs = int64(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			s.samples = append(s.samples, sample)
+		}
+		return nil
+	})
+	if err == nil && s.labels[promLabelName] == "" {
+		err = errors.New("time series without __name__ label")
+	}
+	return s, err
+}
+
+func decodeMetadata(b []byte) (string, promMetadata, error) {
+	var (
+		family string
+		md     promMetadata
+	)
+	err := walkProto(b, func(num protowire.Number, typ protowire.Type, data []byte, v uint64) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			md.typ = int32(v)
+		case num == 2 && typ == protowire.BytesType:
+			family = string(data)
+		case num == 4 && typ == protowire.BytesType:
+			md.help = string(data)
+		case num == 5 && typ == protowire.BytesType:
+			md.unit = string(data)
+		}
+		return nil
+	})
+	return family, md, err
+}
+
+// promFamily identifies the metric family a series belongs to and its type,
+// using metadata when the sender provides it and naming conventions otherwise.
+// distributions maps histogram and summary families seen in the request to their type.
+func promFamily(name string, labels map[string]string, metadata map[string]promMetadata, distributions map[string]int32) (string, int32) {
+	if md, ok := metadata[name]; ok && md.typ != promTypeUnknown {
+		return name, md.typ
+	}
+
+	for _, suffix := range []string{promSuffixBucket, promSuffixSum, promSuffixCount} {
+		base, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		if md, ok := metadata[base]; ok && (md.typ == promTypeHistogram || md.typ == promTypeSummary) {
+			return base, md.typ
+		}
+		if typ, ok := distributions[base]; ok {
+			return base, typ
+		}
+	}
+	if typ, ok := distributions[name]; ok {
+		return name, typ
+	}
+	if base, ok := strings.CutSuffix(name, promSuffixTotal); ok {
+		// OpenMetrics names the counter family without the _total suffix.
+		if md, ok := metadata[base]; !ok || md.typ == promTypeCounter || md.typ == promTypeUnknown {
+			return name, promTypeCounter
+		}
+	}
+	return name, promTypeGauge
+}
+
+// remoteWriteToOTLP converts remote-write series into an OTLP export request.
+// The job and instance labels identify the resource; all other labels become
+// data point attributes.
+func remoteWriteToOTLP(series []promSeries, metadata map[string]promMetadata) *colmetricspb.ExportMetricsServiceRequest {
+	// Without metadata, _bucket series with an le label mark a histogram and
+	// series with a quantile label mark a summary.
+	distributions := make(map[string]int32)
+	for _, s := range series {
+		name := s.labels[promLabelName]
+		if _, ok := s.labels[promLabelQuantile]; ok {
+			distributions[name] = promTypeSummary
+		} else if base, ok := strings.CutSuffix(name, promSuffixBucket); ok {
+			if _, ok := s.labels[promLabelLe]; ok {
+				distributions[base] = promTypeHistogram
+			}
+		}
+	}
+
+	b := newPromBuilder(metadata)
+	for _, s := range series {
+		name := s.labels[promLabelName]
+		family, typ := promFamily(name, s.labels, metadata, distributions)
+		for _, sample := range s.samples {
+			if math.Float64bits(sample.value) == promStaleNaN {
+				continue
+			}
+			switch typ {
+			case promTypeHistogram, promTypeSummary:
+				b.addDistribution(family, typ, strings.TrimPrefix(name, family), s.labels, sample)
+			case promTypeCounter:
+				b.addNumber(family, true, s.labels, sample)
+			default:
+				b.addNumber(family, false, s.labels, sample)
+			}
+		}
+	}
+	return b.request()
+}
+
+// promBuilder accumulates converted data points grouped by resource and family.
+type promBuilder struct {
+	metadata  map[string]promMetadata
+	req       *colmetricspb.ExportMetricsServiceRequest
+	resources map[[2]string]*metricspb.ScopeMetrics
+	metrics   map[*metricspb.ScopeMetrics]map[string]*metricspb.Metric
+
+	// Histogram and summary points are assembled from several series that
+	// share a label set and timestamp.
+	histogramPoints map[string]*promDistribution
+	distributions   []*promDistribution
+}
+
+// promDistribution collects the bucket/quantile, _sum and _count series for
+// one histogram or summary data point.
+type promDistribution struct {
+	metric      *metricspb.Metric
+	typ         int32
+	attrs       []*commonpb.KeyValue
+	timestampMs int64
+	bounds      map[float64]float64 // le or quantile -> value
+	sum         float64
+	count       float64
+	hasCount    bool
+}
+
+func newPromBuilder(metadata map[string]promMetadata) *promBuilder {
+	return &promBuilder{
+		metadata:        metadata,
+		req:             &colmetricspb.ExportMetricsServiceRequest{},
+		resources:       make(map[[2]string]*metricspb.ScopeMetrics),
+		metrics:         make(map[*metricspb.ScopeMetrics]map[string]*metricspb.Metric),
+		histogramPoints: make(map[string]*promDistribution),
+	}
+}
+
+// scope returns the scope metrics for a series' job and instance.
+func (b *promBuilder) scope(labels map[string]string) *metricspb.ScopeMetrics {
+	key := [2]string{labels[promLabelJob], labels[promLabelInstance]}
+	if sm, ok := b.resources[key]; ok {
+		return sm
+	}
+
+	serviceName := key[0]
+	if serviceName == "" {
+		serviceName = "prometheus"
+	}
+	attrs := []*commonpb.KeyValue{stringKV(attrServiceName, serviceName)}
+	if key[1] != "" {
+		attrs = append(attrs, stringKV(attrServiceInstanceID, key[1]))
+	}
+	sm := &metricspb.ScopeMetrics{Scope: &commonpb.InstrumentationScope{Name: "prometheus.remote_write"}}
+	b.req.ResourceMetrics = append(b.req.ResourceMetrics, &metricspb.ResourceMetrics{
+		Resource:     &resourcepb.Resource{Attributes: attrs},
+		ScopeMetrics: []*metricspb.ScopeMetrics{sm},
+	})
+	b.resources[key] = sm
+	b.metrics[sm] = make(map[string]*metricspb.Metric)
+	return sm
+}
+
+// metric returns the metric for family within the series' resource, creating
+// it with newData on first use.
+func (b *promBuilder) metric(family string, labels map[string]string, newData func(m *metricspb.Metric)) *metricspb.Metric {
+	sm := b.scope(labels)
+	if m, ok := b.metrics[sm][family]; ok {
+		return m
+	}
+	md, ok := b.metadata[family]
+	if !ok {
+		md = b.metadata[strings.TrimSuffix(family, promSuffixTotal)]
+	}
+	m := &metricspb.Metric{Name: family, Description: md.help, Unit: md.unit}
+	newData(m)
+	sm.Metrics = append(sm.Metrics, m)
+	b.metrics[sm][family] = m
+	return m
+}
+
+// addNumber records a gauge sample, or a cumulative monotonic sum for counters.
+func (b *promBuilder) addNumber(family string, counter bool, labels map[string]string, sample promSample) {
+	m := b.metric(family, labels, func(m *metricspb.Metric) {
+		if counter {
+			m.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}}
+		} else {
+			m.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+		}
+	})
+
+	dp := &metricspb.NumberDataPoint{
+		Attributes:   promAttributes(labels),
+		TimeUnixNano: uint64(sample.timestampMs) * 1e6,
+		Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: sample.value},
+	}
+	if sum := m.GetSum(); sum != nil {
+		sum.DataPoints = append(sum.DataPoints, dp)
+	} else {
+		m.GetGauge().DataPoints = append(m.GetGauge().DataPoints, dp)
+	}
+}
+
+// addDistribution merges one bucket, quantile, _sum or _count sample into its
+// histogram or summary data point.
+func (b *promBuilder) addDistribution(family string, typ int32, suffix string, labels map[string]string, sample promSample) {
+	boundLabel := promLabelLe
+	if typ == promTypeSummary {
+		boundLabel = promLabelQuantile
+	}
+
+	// Identify the data point by family, labels (minus the bound) and timestamp.
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != promLabelName && k != boundLabel {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var id strings.Builder
+	id.WriteString(family)
+	for _, k := range keys {
+		id.WriteString("\xff" + k + "=" + labels[k])
+	}
+	id.WriteString("\xff" + strconv.FormatInt(sample.timestampMs, 10))
+
+	d, ok := b.histogramPoints[id.String()]
+	if !ok {
+		m := b.metric(family, labels, func(m *metricspb.Metric) {
+			if typ == promTypeSummary {
+				m.Data = &metricspb.Metric_Summary{Summary: &metricspb.Summary{}}
+			} else {
+				m.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				}}
+			}
+		})
+		d = &promDistribution{
+			metric:      m,
+			typ:         typ,
+			attrs:       promAttributes(labels, boundLabel),
+			timestampMs: sample.timestampMs,
+			bounds:      make(map[float64]float64),
+		}
+		b.histogramPoints[id.String()] = d
+		b.distributions = append(b.distributions, d)
+	}
+
+	switch suffix {
+	case promSuffixSum:
+		d.sum = sample.value
+	case promSuffixCount:
+		d.count = sample.value
+		d.hasCount = true
+	default:
+		if bound, err := strconv.ParseFloat(labels[boundLabel], 64); err == nil {
+			d.bounds[bound] = sample.value
+		}
+	}
+}
+
+// request finalizes pending histogram and summary points and returns the request.
+func (b *promBuilder) request() *colmetricspb.ExportMetricsServiceRequest {
+	for _, d := range b.distributions {
+		bounds := make([]float64, 0, len(d.bounds))
+		for bound := range d.bounds {
+			bounds = append(bounds, bound)
+		}
+		sort.Float64s(bounds)
+		ts := uint64(d.timestampMs) * 1e6
+
+		if d.typ == promTypeSummary {
+			dp := &metricspb.SummaryDataPoint{
+				Attributes:   d.attrs,
+				TimeUnixNano: ts,
+				Count:        uint64(d.count),
+				Sum:          d.sum,
+			}
+			for _, q := range bounds {
+				dp.QuantileValues = append(dp.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{Quantile: q, Value: d.bounds[q]})
+			}
+			d.metric.GetSummary().DataPoints = append(d.metric.GetSummary().DataPoints, dp)
+			continue
+		}
+
+		// Prometheus buckets are cumulative; OTLP bucket counts are per bucket
+		// with the +Inf bucket implied by the final count.
+		dp := &metricspb.HistogramDataPoint{
+			Attributes:   d.attrs,
+			TimeUnixNano: ts,
+			Sum:          &d.sum,
+		}
+		var prev float64
+		for _, bound := range bounds {
+			cumulative := d.bounds[bound]
+			if !math.IsInf(bound, +1) {
+				dp.ExplicitBounds = append(dp.ExplicitBounds, bound)
+			}
+			dp.BucketCounts = append(dp.BucketCounts, uint64(math.Max(cumulative-prev, 0)))
+			prev = cumulative
+		}
+		count := prev
+		if d.hasCount {
+			count = d.count
+		}
+		if len(bounds) == 0 || !math.IsInf(bounds[len(bounds)-1], +1) {
+			dp.BucketCounts = append(dp.BucketCounts, uint64(math.Max(count-prev, 0)))
+		}
+		dp.Count = uint64(count)
+		d.metric.GetHistogram().DataPoints = append(d.metric.GetHistogram().DataPoints, dp)
+	}
+	return b.req
+}
+
+// promAttributes converts series labels to data point attributes, omitting
+// the metric name, the labels already mapped to the resource and any extra
+// labels listed in omit.
+func promAttributes(labels map[string]string, omit ...string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		switch k {
+		case promLabelName, promLabelJob, promLabelInstance:
+			continue
+		}
+		if slices.Contains(omit, k) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, stringKV(k, labels[k]))
+	}
+	return attrs
+}
@@ -0,0 +1,201 @@
+package receiver
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/phosphor-project/phosphor/pkg/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// promTestSeries describes a series to encode into a test WriteRequest.
+type promTestSeries struct {
+	labels []string // name, value pairs
+	value  float64
+}
+
+func encodeWriteRequest(series []promTestSeries, metadata map[string]int32) []byte {
+	const timestampMs = 1700000000000
+
+	var req []byte
+	for _, s := range series {
+		var ts []byte
+		for i := 0; i < len(s.labels); i += 2 {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, s.labels[i])
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, s.labels[i+1])
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, timestampMs)
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	for family, typ := range metadata {
+		var md []byte
+		md = protowire.AppendTag(md, 1, protowire.VarintType)
+		md = protowire.AppendVarint(md, uint64(typ))
+		md = protowire.AppendTag(md, 2, protowire.BytesType)
+		md = protowire.AppendString(md, family)
+		md = protowire.AppendTag(md, 4, protowire.BytesType)
+		md = protowire.AppendString(md, "help for "+family)
+		req = protowire.AppendTag(req, 3, protowire.BytesType)
+		req = protowire.AppendBytes(req, md)
+	}
+	return snappy.Encode(nil, req)
+}
+
+func postRemoteWrite(t *testing.T, r *OTLPReceiver, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, remoteWritePath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentTypeProtobuf)
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	rec := httptest.NewRecorder()
+	newHTTPHandler(r).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRemoteWriteTypes(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	body := encodeWriteRequest([]promTestSeries{
+		{[]string{"__name__", "node_memory_free_bytes", "job", "node", "instance", "host:9100"}, 1024},
+		{[]string{"__name__", "http_requests_total", "job", "api", "method", "GET"}, 42},
+		{[]string{"__name__", "process_cpu_seconds", "job", "api"}, 7},
+		{[]string{"__name__", "latency_seconds_bucket", "job", "api", "le", "0.1"}, 3},
+		{[]string{"__name__", "latency_seconds_bucket", "job", "api", "le", "1"}, 5},
+		{[]string{"__name__", "latency_seconds_bucket", "job", "api", "le", "+Inf"}, 6},
+		{[]string{"__name__", "latency_seconds_sum", "job", "api"}, 2.5},
+		{[]string{"__name__", "latency_seconds_count", "job", "api"}, 6},
+		{[]string{"__name__", "stale_gauge", "job", "api"}, math.Float64frombits(promStaleNaN)},
+	}, map[string]int32{"process_cpu_seconds": promTypeCounter})
+
+	rec := postRemoteWrite(t, r, body)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204: %s", rec.Code, rec.Body.String())
+	}
+
+	metrics := make(map[string]models.Metric)
+	for _, m := range r.GetMetrics() {
+		metrics[m.Name] = m
+	}
+	if len(metrics) != 4 {
+		t.Fatalf("GetMetrics() returned %v, want 4 metrics", metrics)
+	}
+
+	tests := []struct {
+		name     string
+		wantType models.MetricType
+	}{
+		{"node_memory_free_bytes", models.MetricTypeGauge},
+		{"http_requests_total", models.MetricTypeSum},
+		{"process_cpu_seconds", models.MetricTypeSum},
+		{"latency_seconds", models.MetricTypeHistogram},
+	}
+	for _, tt := range tests {
+		m, ok := metrics[tt.name]
+		if !ok {
+			t.Errorf("metric %s missing", tt.name)
+			continue
+		}
+		if m.Type != tt.wantType {
+			t.Errorf("%s type = %q, want %q", tt.name, m.Type, tt.wantType)
+		}
+	}
+
+	gauge := metrics["node_memory_free_bytes"]
+	if gauge.Resource.ServiceName != "node" {
+		t.Errorf("ServiceName = %q, want job label", gauge.Resource.ServiceName)
+	}
+	if got := attributeValue(gauge.Resource.Attributes, attrServiceInstanceID); got != "host:9100" {
+		t.Errorf("service.instance.id = %v, want instance label", got)
+	}
+
+	counter := metrics["http_requests_total"]
+	if counter.AggregationTemporality != "cumulative" {
+		t.Errorf("AggregationTemporality = %q, want cumulative", counter.AggregationTemporality)
+	}
+	dp := counter.DataPoints[0]
+	if got := attributeValue(dp.Attributes, "method"); got != "GET" {
+		t.Errorf("method attribute = %v, want label value", got)
+	}
+	if len(dp.Attributes) != 1 {
+		t.Errorf("Attributes = %+v, want only non-resource labels", dp.Attributes)
+	}
+	if dp.ValueDouble == nil || *dp.ValueDouble != 42 {
+		t.Errorf("value = %v, want 42", dp.ValueDouble)
+	}
+	if dp.TimeUnixNano != 1700000000000*1e6 {
+		t.Errorf("TimeUnixNano = %d, want sample timestamp", dp.TimeUnixNano)
+	}
+	if metrics["process_cpu_seconds"].Description != "help for process_cpu_seconds" {
+		t.Errorf("Description = %q, want metadata help", metrics["process_cpu_seconds"].Description)
+	}
+
+	hist := metrics["latency_seconds"].DataPoints
+	if len(hist) != 1 {
+		t.Fatalf("histogram data points = %d, want 1", len(hist))
+	}
+	h := hist[0]
+	if *h.Count != 6 || *h.Sum != 2.5 {
+		t.Errorf("count/sum = %d/%f, want 6/2.5", *h.Count, *h.Sum)
+	}
+	wantBounds := []float64{0.1, 1}
+	wantCounts := []uint64{3, 2, 1}
+	if len(h.ExplicitBounds) != len(wantBounds) || len(h.BucketCounts) != len(wantCounts) {
+		t.Fatalf("bounds/counts = %v/%v, want %v/%v", h.ExplicitBounds, h.BucketCounts, wantBounds, wantCounts)
+	}
+	for i := range wantCounts {
+		if h.BucketCounts[i] != wantCounts[i] {
+			t.Errorf("BucketCounts = %v, want %v", h.BucketCounts, wantCounts)
+			break
+		}
+	}
+	if len(h.Attributes) != 0 {
+		t.Errorf("histogram Attributes = %+v, want le removed", h.Attributes)
+	}
+}
+
+func TestRemoteWriteRejectsBadRequests(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wantStatus  int
+	}{
+		{"not snappy", contentTypeProtobuf, []byte("\xff\xff\xff\xff\xff"), http.StatusBadRequest},
+		{"malformed protobuf", contentTypeProtobuf, snappy.Encode(nil, []byte{0x0a, 0xff}), http.StatusBadRequest},
+		{"missing name", contentTypeProtobuf, encodeWriteRequest([]promTestSeries{{[]string{"job", "x"}, 1}}, nil), http.StatusBadRequest},
+		{"remote-write v2", "application/x-protobuf;proto=io.prometheus.write.v2.Request", nil, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, remoteWritePath, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("Content-Encoding", "snappy")
+			rec := httptest.NewRecorder()
+			newHTTPHandler(r).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
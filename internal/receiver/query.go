@@ -0,0 +1,156 @@
+package receiver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// queryServer serves stored telemetry and receiver state as JSON, for
+// headless use where no window calls the query methods directly.
+type queryServer struct {
+	receiver *OTLPReceiver
+	listener net.Listener
+	server   *http.Server
+}
+
+// newQueryServer opens the query API listener. The API takes the same
+// credentials and TLS settings as OTLP/HTTP, except /healthz, which only
+// reports state and addresses and stays open to probes.
+func newQueryServer(r *OTLPReceiver, address string) (*queryServer, error) {
+	l, err := listenOn(address)
+	if err != nil {
+		return nil, err
+	}
+	return &queryServer{
+		receiver: r,
+		listener: l,
+		server:   &http.Server{Handler: newQueryHandler(r), ReadHeaderTimeout: 10 * time.Second},
+	}, nil
+}
+
+func (q *queryServer) start() {
+	log.Printf("[Phosphor] Serving the query API on %s/api (tls=%t)", listenerAddress(q.listener), q.receiver.tlsConfig != nil)
+	served := q.listener
+	if q.receiver.tlsConfig != nil {
+		served = tls.NewListener(q.listener, q.receiver.tlsConfig)
+	}
+	go func() {
+		if err := q.server.Serve(served); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			q.receiver.degrade("Query API server error: %v", err)
+		}
+	}()
+}
+
+func (q *queryServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	q.server.Shutdown(ctx)
+	closeListeners([]net.Listener{q.listener})
+}
+
+// newQueryHandler builds the query API. Signal endpoints return every stored
+// item, oldest first, or only the newest with ?limit=n. Clearing the buffers
+// is only served when Config.QueryAllowClear is set. Everything but /healthz
+// goes through the receiver's authentication.
+func newQueryHandler(r *OTLPReceiver) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/traces", recent(r.GetTraces, r.GetRecentTraces))
+	mux.HandleFunc("GET /api/metrics", recent(r.GetMetrics, r.GetRecentMetrics))
+	mux.HandleFunc("GET /api/logs", recent(r.GetLogs, r.GetRecentLogs))
+	mux.HandleFunc("GET /api/profiles", func(w http.ResponseWriter, req *http.Request) {
+		// Narrow to the samples of one span, as the desktop span view does
+		traceID, spanID := req.URL.Query().Get("traceId"), req.URL.Query().Get("spanId")
+		if traceID != "" && spanID != "" {
+			writeQueryJSON(w, r.GetProfilesForSpan(traceID, spanID))
+			return
+		}
+		recent(r.GetProfiles, r.GetRecentProfiles)(w, req)
+	})
+	if r.config.QueryAllowClear {
+		mux.HandleFunc("DELETE /api/telemetry", func(w http.ResponseWriter, req *http.Request) {
+			r.ClearAll()
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	mux.HandleFunc("GET /api/stats", func(w http.ResponseWriter, req *http.Request) {
+		writeQueryJSON(w, r.GetStats())
+	})
+	mux.HandleFunc("GET /api/receiver-stats", func(w http.ResponseWriter, req *http.Request) {
+		writeQueryJSON(w, r.GetReceiverStats())
+	})
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, req *http.Request) {
+		writeQueryJSON(w, r.GetStatus())
+	})
+	mux.HandleFunc("GET /api/clients", func(w http.ResponseWriter, req *http.Request) {
+		writeQueryJSON(w, r.GetClients())
+	})
+	mux.HandleFunc("GET /api/rejections", func(w http.ResponseWriter, req *http.Request) {
+		writeQueryJSON(w, r.GetRejections())
+	})
+	mux.HandleFunc("GET /api/scrape-targets", func(w http.ResponseWriter, req *http.Request) {
+		writeQueryJSON(w, r.GetScrapeTargets())
+	})
+	mux.HandleFunc("GET /api/upstreams", func(w http.ResponseWriter, req *http.Request) {
+		writeQueryJSON(w, r.GetUpstreams())
+	})
+
+	// Readiness for sidecars: 200 while OTLP is being served, 503 otherwise
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, req *http.Request) {
+		status := r.GetStatus()
+		if status.State != StateListening && status.State != StateDegraded {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(status)
+			return
+		}
+		writeQueryJSON(w, status)
+	})
+
+	root := http.NewServeMux()
+	root.Handle("GET /healthz", mux)
+	root.Handle("/", r.authMiddleware(mux))
+	return root
+}
+
+// recent serves all items from a buffer, or the newest ?limit=n of them.
+func recent[T any](all func() []T, last func(int) []T) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		value := req.URL.Query().Get("limit")
+		if value == "" {
+			writeQueryJSON(w, all())
+			return
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		writeQueryJSON(w, last(n))
+	}
+}
+
+func writeQueryJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[Phosphor] Failed to write query response: %v", err)
+	}
+}
+
+// QueryAddress returns the address the query API is served on, or "" when
+// it is disabled.
+func (r *OTLPReceiver) QueryAddress() string {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.query == nil {
+		return ""
+	}
+	return listenerAddress(r.query.listener)
+}
@@ -0,0 +1,134 @@
+package receiver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+func TestQueryAPI(t *testing.T) {
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(t.TempDir(), "otlp.sock")}
+	config.DisableHTTP = true
+	config.QueryAddress = "127.0.0.1:0"
+	config.QueryAllowClear = true
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.traceService.Export(context.Background(), testTraceRequest()); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+	base := "http://" + r.QueryAddress()
+
+	get := func(path string, v any) int {
+		t.Helper()
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if v != nil && resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("GET %s: %v", path, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	var spans []models.Span
+	if code := get("/api/traces", &spans); code != http.StatusOK || len(spans) != 3 {
+		t.Errorf("GET /api/traces = %d with %d spans, want 200 with 3", code, len(spans))
+	}
+	if code := get("/api/traces?limit=2", &spans); code != http.StatusOK || len(spans) != 2 {
+		t.Errorf("GET /api/traces?limit=2 = %d with %d spans, want 200 with 2", code, len(spans))
+	}
+	if code := get("/api/traces?limit=x", nil); code != http.StatusBadRequest {
+		t.Errorf("GET /api/traces?limit=x = %d, want 400", code)
+	}
+
+	var stats models.TelemetryStats
+	if get("/api/stats", &stats); stats.TraceCount != 3 {
+		t.Errorf("TraceCount = %d, want 3", stats.TraceCount)
+	}
+	var status ReceiverStatus
+	if code := get("/healthz", &status); code != http.StatusOK || status.State != StateListening {
+		t.Errorf("GET /healthz = %d (%q), want 200 while listening", code, status.State)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, base+"/api/telemetry", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || len(r.GetTraces()) != 0 {
+		t.Errorf("DELETE /api/telemetry = %d leaving %d spans, want 204 and none", resp.StatusCode, len(r.GetTraces()))
+	}
+}
+
+func TestQueryAPIAuth(t *testing.T) {
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(t.TempDir(), "otlp.sock")}
+	config.DisableHTTP = true
+	config.QueryAddress = "127.0.0.1:0"
+	config.Auth = AuthConfig{BearerToken: "s3cret"}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	if _, err := r.traceService.Export(context.Background(), testTraceRequest()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	base := "http://" + r.QueryAddress()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		wantCode int
+	}{
+		{"no token", http.MethodGet, "/api/traces", "", http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, "/api/traces", "nope", http.StatusUnauthorized},
+		{"no token on healthz", http.MethodGet, "/healthz", "", http.StatusOK},
+		{"valid token", http.MethodGet, "/api/traces", "s3cret", http.StatusOK},
+		{"clear without opt-in", http.MethodDelete, "/api/telemetry", "s3cret", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, base+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+
+	if got := len(r.GetTraces()); got != 1 {
+		t.Errorf("GetTraces() returned %d spans, want the buffer left alone", got)
+	}
+	if got := r.GetReceiverStats().AuthFailures; got != 2 {
+		t.Errorf("AuthFailures = %d, want 2", got)
+	}
+}
@@ -0,0 +1,140 @@
+package receiver
+
+import (
+	"fmt"
+	"log"
+)
+
+// maxCapacity bounds ring buffer sizes, which are allocated up front.
+const maxCapacity = 1_000_000
+
+// RuntimeConfig is the part of Config that can change while the receiver
+// runs. A protocol whose address is empty is disabled.
+type RuntimeConfig struct {
+	Port             int    `json:"port"`
+	HTTPPort         int    `json:"httpPort"`
+	HTTPEnabled      bool   `json:"httpEnabled"`
+	StatsDAddress    string `json:"statsdAddress"`
+	SyslogTCPAddress string `json:"syslogTcpAddress"`
+	SyslogUDPAddress string `json:"syslogUdpAddress"`
+	ForwardAddress   string `json:"forwardAddress"`
+
+	TraceCapacity   int `json:"traceCapacity"`
+	MetricCapacity  int `json:"metricCapacity"`
+	LogCapacity     int `json:"logCapacity"`
+	ProfileCapacity int `json:"profileCapacity"`
+}
+
+func (c RuntimeConfig) validate() error {
+	for _, port := range []int{c.Port, c.HTTPPort} {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("port %d out of range 1-65535", port)
+		}
+	}
+	if c.HTTPEnabled && c.Port == c.HTTPPort {
+		return fmt.Errorf("gRPC and OTLP/HTTP cannot share port %d", c.Port)
+	}
+	capacities := []struct {
+		signal   string
+		capacity int
+	}{
+		{"trace", c.TraceCapacity},
+		{"metric", c.MetricCapacity},
+		{"log", c.LogCapacity},
+		{"profile", c.ProfileCapacity},
+	}
+	for _, sc := range capacities {
+		if sc.capacity < 1 || sc.capacity > maxCapacity {
+			return fmt.Errorf("%s capacity %d out of range 1-%d", sc.signal, sc.capacity, maxCapacity)
+		}
+	}
+	return nil
+}
+
+// listeners returns c without its capacities, so two configs compare equal
+// when they need the same listeners.
+func (c RuntimeConfig) listeners() RuntimeConfig {
+	c.TraceCapacity, c.MetricCapacity, c.LogCapacity, c.ProfileCapacity = 0, 0, 0, 0
+	return c
+}
+
+// runtimeConfig extracts the runtime settings from config.
+func runtimeConfig(config Config) RuntimeConfig {
+	return RuntimeConfig{
+		Port:             config.Port,
+		HTTPPort:         config.HTTPPort,
+		HTTPEnabled:      !config.DisableHTTP,
+		StatsDAddress:    config.StatsD.ListenAddress,
+		SyslogTCPAddress: config.Syslog.TCPAddress,
+		SyslogUDPAddress: config.Syslog.UDPAddress,
+		ForwardAddress:   config.Forward.ListenAddress,
+		TraceCapacity:    config.TraceCapacity,
+		MetricCapacity:   config.MetricCapacity,
+		LogCapacity:      config.LogCapacity,
+		ProfileCapacity:  config.ProfileCapacity,
+	}
+}
+
+// applyTo writes the runtime settings into config field by field, leaving
+// settings that requests may be reading, such as Auth, untouched.
+func (c RuntimeConfig) applyTo(config *Config) {
+	config.Port = c.Port
+	config.HTTPPort = c.HTTPPort
+	config.DisableHTTP = !c.HTTPEnabled
+	config.StatsD.ListenAddress = c.StatsDAddress
+	config.Syslog.TCPAddress = c.SyslogTCPAddress
+	config.Syslog.UDPAddress = c.SyslogUDPAddress
+	config.Forward.ListenAddress = c.ForwardAddress
+	config.TraceCapacity = c.TraceCapacity
+	config.MetricCapacity = c.MetricCapacity
+	config.LogCapacity = c.LogCapacity
+	config.ProfileCapacity = c.ProfileCapacity
+}
+
+// GetRuntimeConfig returns the receiver's current runtime settings.
+func (r *OTLPReceiver) GetRuntimeConfig() RuntimeConfig {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+	return runtimeConfig(r.config)
+}
+
+// Reconfigure applies new runtime settings. When ports or protocols change
+// on a running receiver, it is stopped, draining in-flight exports, and
+// started again; if the new settings fail to start, the previous ones are
+// restored and the error is returned. Buffers are resized in place and keep
+// their newest items, so stored telemetry survives either change.
+func (r *OTLPReceiver) Reconfigure(config RuntimeConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	previous := runtimeConfig(r.config)
+	restart := config.listeners() != previous.listeners() && r.GetStatus().State != StateStopped
+	if restart {
+		log.Println("[Phosphor] Restarting receiver to apply new listener settings")
+		r.stopLocked()
+	}
+
+	config.applyTo(&r.config)
+	if restart {
+		if err := r.startLocked(); err != nil {
+			previous.applyTo(&r.config)
+			if restoreErr := r.startLocked(); restoreErr != nil {
+				log.Printf("[Phosphor] Failed to restore previous receiver settings: %v", restoreErr)
+			}
+			return fmt.Errorf("could not apply receiver settings: %w", err)
+		}
+	}
+
+	dropped := r.traces.Resize(config.TraceCapacity) +
+		r.metrics.Resize(config.MetricCapacity) +
+		r.logs.Resize(config.LogCapacity) +
+		r.profiles.Resize(config.ProfileCapacity)
+	if dropped > 0 {
+		log.Printf("[Phosphor] Buffers shrunk, evicted %d oldest items", dropped)
+	}
+	return nil
+}
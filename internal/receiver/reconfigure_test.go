@@ -0,0 +1,202 @@
+package receiver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRuntimeConfigValidate(t *testing.T) {
+	valid := runtimeConfig(DefaultConfig())
+	tests := []struct {
+		name    string
+		modify  func(*RuntimeConfig)
+		wantErr string
+	}{
+		{"defaults", func(*RuntimeConfig) {}, ""},
+		{"port out of range", func(c *RuntimeConfig) { c.HTTPPort = 70000 }, "port 70000"},
+		{"shared port", func(c *RuntimeConfig) { c.HTTPPort = c.Port }, "cannot share port"},
+		{"shared port with HTTP off", func(c *RuntimeConfig) { c.HTTPPort, c.HTTPEnabled = c.Port, false }, ""},
+		{"zero capacity", func(c *RuntimeConfig) { c.LogCapacity = 0 }, "log capacity 0"},
+		{"huge capacity", func(c *RuntimeConfig) { c.TraceCapacity = maxCapacity + 1 }, "trace capacity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			err := config.validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// freePort returns a port that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestReconfigure(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = freePort(t)
+	config.HTTPPort = freePort(t)
+	config.TraceCapacity = 4
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.traceService.Export(context.Background(), testTraceRequest()); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	// Turning off OTLP/HTTP restarts the listeners and keeps the data.
+	settings := r.GetRuntimeConfig()
+	settings.HTTPEnabled = false
+	settings.TraceCapacity = 10
+	if err := r.Reconfigure(settings); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	status := r.GetStatus()
+	if status.State != StateListening || len(status.GRPCAddresses) != 1 || len(status.HTTPAddresses) != 0 {
+		t.Errorf("status = %+v, want listening on gRPC only", status)
+	}
+	if got := r.GetStats(); got.TraceCount != 3 || got.TraceCapacity != 10 {
+		t.Errorf("traces = %d/%d, want 3/10", got.TraceCount, got.TraceCapacity)
+	}
+
+	// Shrinking keeps the newest items.
+	settings.TraceCapacity = 2
+	if err := r.Reconfigure(settings); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if got := r.GetStats(); got.TraceCount != 2 || got.TraceCapacity != 2 {
+		t.Errorf("traces = %d/%d, want 2/2", got.TraceCount, got.TraceCapacity)
+	}
+	if got := r.GetRuntimeConfig(); got != settings {
+		t.Errorf("GetRuntimeConfig() = %+v, want %+v", got, settings)
+	}
+}
+
+func TestReconfigureRestoresOnFailure(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = freePort(t)
+	config.HTTPPort = freePort(t)
+	config.PortFallback = false
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	previous := r.GetRuntimeConfig()
+	settings := previous
+	settings.Port = takenPort(t)
+	if err := r.Reconfigure(settings); err == nil {
+		t.Fatal("Reconfigure() error = nil, want the port conflict")
+	}
+	if got := r.GetRuntimeConfig(); got != previous {
+		t.Errorf("GetRuntimeConfig() = %+v, want the previous settings %+v", got, previous)
+	}
+	if status := r.GetStatus(); status.State != StateListening {
+		t.Errorf("State = %q, want the receiver running again", status.State)
+	}
+}
+
+func TestReconfigureWhileReading(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = freePort(t)
+	config.HTTPPort = freePort(t)
+	config.StatsD.ListenAddress = "127.0.0.1:0"
+	config.Syslog.UDPAddress = "127.0.0.1:0"
+	config.Forward.ListenAddress = "127.0.0.1:0"
+	config.QueryAddress = "127.0.0.1:0"
+	config.DrainTimeout = time.Second
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	// Run under -race: the getters must not race with the restarts.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			r.Addresses()
+			r.HTTPAddresses()
+			r.GetUpstreams()
+			r.GetScrapeTargets()
+			r.StatsDAddress()
+			r.SyslogAddresses()
+			r.ForwardAddress()
+			r.QueryAddress()
+			r.MetricsAddress()
+			r.forwardUpstream(testTraceRequest())
+		}
+	}()
+
+	settings := r.GetRuntimeConfig()
+	for i := 0; i < 3; i++ {
+		settings.HTTPEnabled = !settings.HTTPEnabled
+		if err := r.Reconfigure(settings); err != nil {
+			t.Fatalf("Reconfigure() error = %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if status := r.GetStatus(); status.State != StateListening || len(status.HTTPAddresses) != 0 {
+		t.Errorf("status = %+v, want listening on gRPC only", status)
+	}
+}
+
+func TestStopWaitsForReconfigure(t *testing.T) {
+	config := DefaultConfig()
+	config.Port = freePort(t)
+	config.HTTPPort = freePort(t)
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Stop racing a restart must leave the receiver stopped, not restarted.
+	settings := r.GetRuntimeConfig()
+	settings.HTTPEnabled = false
+	errs := make(chan error, 1)
+	go func() { errs <- r.Reconfigure(settings) }()
+	r.Stop()
+	if err := <-errs; err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+
+	if status := r.GetStatus(); status.State != StateStopped {
+		t.Errorf("State = %q, want stopped", status.State)
+	}
+	if got := r.Addresses(); len(got) != 0 {
+		t.Errorf("Addresses() = %v, want none after Stop", got)
+	}
+}
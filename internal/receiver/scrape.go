@@ -0,0 +1,256 @@
+package receiver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/peer"
+)
+
+// scrapeAccept prefers OpenMetrics and falls back to the classic text format.
+const scrapeAccept = "application/openmetrics-text;version=1.0.0;q=0.75,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+
+// ScrapeConfig configures pulling Prometheus /metrics pages from local targets.
+type ScrapeConfig struct {
+	Targets  []ScrapeTarget `yaml:"targets"`  // Endpoints to scrape; scraping is disabled when empty
+	Interval time.Duration  `yaml:"interval"` // Default time between scrapes (default: 15s)
+	Timeout  time.Duration  `yaml:"timeout"`  // Per-scrape deadline (default: 10s, capped at the interval)
+}
+
+// ScrapeTarget is a single endpoint exposing Prometheus metrics.
+type ScrapeTarget struct {
+	URL      string        `yaml:"url"`      // Full URL of the metrics page, e.g. http://localhost:8080/metrics
+	Job      string        `yaml:"job"`      // Job label and service name (default: the target host)
+	Interval time.Duration `yaml:"interval"` // Overrides ScrapeConfig.Interval when non-zero
+}
+
+// ScrapeTargetStats reports the health of a scrape target.
+type ScrapeTargetStats struct {
+	URL            string    `json:"url"`
+	Job            string    `json:"job"`
+	Health         string    `json:"health"` // up, down, unknown
+	LastScrape     time.Time `json:"lastScrape"`
+	LastDurationMs float64   `json:"lastDurationMs"`
+	LastError      string    `json:"lastError,omitempty"`
+	LastSamples    int       `json:"lastSamples"`
+	Scrapes        uint64    `json:"scrapes"`
+	Failures       uint64    `json:"failures"`
+	IntervalMs     int64     `json:"intervalMs"`
+}
+
+// Scrape target health values.
+const (
+	scrapeHealthUnknown = "unknown"
+	scrapeHealthUp      = "up"
+	scrapeHealthDown    = "down"
+)
+
+// scrapeTargetAddr identifies a scrape target as the peer of its exports.
+type scrapeTargetAddr string
+
+func (a scrapeTargetAddr) Network() string { return "tcp" }
+func (a scrapeTargetAddr) String() string  { return string(a) }
+
+// scraper periodically pulls metrics from each configured target.
+type scraper struct {
+	receiver *OTLPReceiver
+	client   *http.Client
+	timeout  time.Duration
+	targets  []*scrapeLoop
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// scrapeLoop holds a single target's schedule and health.
+type scrapeLoop struct {
+	url      string
+	job      string
+	instance string
+	interval time.Duration
+
+	mu    sync.Mutex
+	stats ScrapeTargetStats
+}
+
+// newScraper validates the configuration and prepares a loop per target.
+func newScraper(r *OTLPReceiver, config ScrapeConfig) (*scraper, error) {
+	if config.Interval <= 0 {
+		config.Interval = 15 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	s := &scraper{
+		receiver: r,
+		client:   &http.Client{},
+		timeout:  config.Timeout,
+	}
+	for _, target := range config.Targets {
+		u, err := url.Parse(target.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid scrape target %q", target.URL)
+		}
+		interval := target.Interval
+		if interval <= 0 {
+			interval = config.Interval
+		}
+		job := target.Job
+		if job == "" {
+			job = u.Hostname()
+		}
+
+		loop := &scrapeLoop{url: target.URL, job: job, instance: u.Host, interval: interval}
+		loop.stats = ScrapeTargetStats{
+			URL:        target.URL,
+			Job:        job,
+			Health:     scrapeHealthUnknown,
+			IntervalMs: interval.Milliseconds(),
+		}
+		s.targets = append(s.targets, loop)
+	}
+	return s, nil
+}
+
+// start launches one goroutine per target.
+func (s *scraper) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	for _, loop := range s.targets {
+		log.Printf("[Phosphor] Scraping %s every %s", loop.url, loop.interval)
+		s.wg.Add(1)
+		go func(loop *scrapeLoop) {
+			defer s.wg.Done()
+			s.run(ctx, loop)
+		}(loop)
+	}
+}
+
+// stop cancels all scrape loops and waits for in-progress scrapes to finish.
+func (s *scraper) stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// run scrapes a target immediately and then on every interval tick.
+func (s *scraper) run(ctx context.Context, loop *scrapeLoop) {
+	ticker := time.NewTicker(loop.interval)
+	defer ticker.Stop()
+
+	for {
+		s.scrape(ctx, loop)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scrape performs one scrape and records its outcome.
+func (s *scraper) scrape(ctx context.Context, loop *scrapeLoop) {
+	start := time.Now()
+	samples, err := s.scrapeOnce(ctx, loop, start)
+	if ctx.Err() != nil {
+		return
+	}
+
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+	loop.stats.Scrapes++
+	loop.stats.LastScrape = start
+	loop.stats.LastDurationMs = float64(time.Since(start).Microseconds()) / 1000
+	loop.stats.LastSamples = samples
+	if err != nil {
+		loop.stats.Health = scrapeHealthDown
+		loop.stats.LastError = err.Error()
+		loop.stats.Failures++
+		return
+	}
+	loop.stats.Health = scrapeHealthUp
+	loop.stats.LastError = ""
+}
+
+// scrapeOnce fetches and ingests a target's metrics, returning the number of samples.
+func (s *scraper) scrapeOnce(ctx context.Context, loop *scrapeLoop, start time.Time) (int, error) {
+	timeout := s.timeout
+	if timeout > loop.interval {
+		timeout = loop.interval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loop.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", scrapeAccept)
+	req.Header.Set("User-Agent", "Phosphor-Scraper")
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", fmt.Sprintf("%g", timeout.Seconds()))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("server returned HTTP status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read body: %w", err)
+	}
+	if len(body) > maxHTTPBodySize {
+		return 0, fmt.Errorf("body exceeds %d bytes", maxHTTPBodySize)
+	}
+
+	series, md, err := parseExposition(body, resp.Header.Get("Content-Type"), start.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	for _, ts := range series {
+		ts.labels[promLabelJob] = loop.job
+		ts.labels[promLabelInstance] = loop.instance
+	}
+
+	exportReq := remoteWriteToOTLP(series, md)
+	if len(exportReq.ResourceMetrics) > 0 {
+		exportCtx := withTransport(context.Background(), transportScrape)
+		exportCtx = peer.NewContext(exportCtx, &peer.Peer{Addr: scrapeTargetAddr(loop.instance)})
+		if _, err := s.receiver.metricsService.Export(exportCtx, exportReq); err != nil {
+			return len(series), fmt.Errorf("failed to ingest metrics: %w", err)
+		}
+	}
+	return len(series), nil
+}
+
+// stats returns a snapshot of every target's health.
+func (s *scraper) stats() []ScrapeTargetStats {
+	result := make([]ScrapeTargetStats, 0, len(s.targets))
+	for _, loop := range s.targets {
+		loop.mu.Lock()
+		result = append(result, loop.stats)
+		loop.mu.Unlock()
+	}
+	return result
+}
+
+// GetScrapeTargets returns the health of each configured scrape target.
+func (r *OTLPReceiver) GetScrapeTargets() []ScrapeTargetStats {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.scraper == nil {
+		return []ScrapeTargetStats{}
+	}
+	return r.scraper.stats()
+}
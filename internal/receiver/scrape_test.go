@@ -0,0 +1,189 @@
+package receiver
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+const testExposition = `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",path="/a\"b"} 42 1700000000000
+http_requests_total{method="POST"} 7
+# TYPE temperature gauge
+temperature -Inf
+`
+
+const testOpenMetrics = `# TYPE requests counter
+# HELP requests Requests served.
+requests_total{code="200"} 10 1700000000.5 # {trace_id="abc"} 1.0
+requests_created{code="200"} 1699999000
+# TYPE queue_depth gauge
+# UNIT queue_depth items
+queue_depth 3
+# EOF
+ignored_after_eof 1
+`
+
+func TestParseExposition(t *testing.T) {
+	const defaultTs = 1234
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		wantNames   []string
+		wantValues  []float64
+		wantTs      []int64
+	}{
+		{
+			name:        "text format",
+			body:        testExposition,
+			contentType: "text/plain; version=0.0.4",
+			wantNames:   []string{"http_requests_total", "http_requests_total", "temperature"},
+			wantValues:  []float64{42, 7, math.Inf(-1)},
+			wantTs:      []int64{1700000000000, defaultTs, defaultTs},
+		},
+		{
+			name:        "openmetrics",
+			body:        testOpenMetrics,
+			contentType: "application/openmetrics-text; version=1.0.0; charset=utf-8",
+			wantNames:   []string{"requests_total", "queue_depth"},
+			wantValues:  []float64{10, 3},
+			wantTs:      []int64{1700000000500, defaultTs},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, _, err := parseExposition([]byte(tt.body), tt.contentType, defaultTs)
+			if err != nil {
+				t.Fatalf("parseExposition() error = %v", err)
+			}
+			if len(series) != len(tt.wantNames) {
+				t.Fatalf("parseExposition() returned %d series, want %d", len(series), len(tt.wantNames))
+			}
+			for i, s := range series {
+				if s.labels[promLabelName] != tt.wantNames[i] {
+					t.Errorf("series %d name = %q, want %q", i, s.labels[promLabelName], tt.wantNames[i])
+				}
+				if s.samples[0].value != tt.wantValues[i] {
+					t.Errorf("series %d value = %v, want %v", i, s.samples[0].value, tt.wantValues[i])
+				}
+				if s.samples[0].timestampMs != tt.wantTs[i] {
+					t.Errorf("series %d timestamp = %d, want %d", i, s.samples[0].timestampMs, tt.wantTs[i])
+				}
+			}
+		})
+	}
+
+	series, md, _ := parseExposition([]byte(testExposition), "text/plain", defaultTs)
+	if got := series[0].labels["path"]; got != `/a"b` {
+		t.Errorf("escaped label = %q, want %q", got, `/a"b`)
+	}
+	if md["http_requests_total"].typ != promTypeCounter || md["http_requests_total"].help != "Requests served." {
+		t.Errorf("metadata = %+v, want counter with help", md["http_requests_total"])
+	}
+
+	for _, bad := range []string{"no_value", `bad{label=unquoted} 1`, `bad{label="open} 1`, "bad NotANumber"} {
+		if _, _, err := parseExposition([]byte(bad), "text/plain", defaultTs); err == nil {
+			t.Errorf("parseExposition(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestScrapeTarget(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !strings.Contains(req.Header.Get("Accept"), contentTypeOpenMetrics) {
+			t.Errorf("Accept = %q, want OpenMetrics preferred", req.Header.Get("Accept"))
+		}
+		if !healthy {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0")
+		w.Write([]byte(testOpenMetrics))
+	}))
+	defer server.Close()
+
+	r := NewOTLPReceiver(DefaultConfig())
+	s, err := newScraper(r, ScrapeConfig{Targets: []ScrapeTarget{{URL: server.URL + "/metrics", Job: "api"}}})
+	if err != nil {
+		t.Fatalf("newScraper() error = %v", err)
+	}
+	loop := s.targets[0]
+
+	s.scrape(context.Background(), loop)
+
+	stats := s.stats()[0]
+	if stats.Health != scrapeHealthUp || stats.Scrapes != 1 || stats.LastSamples != 2 {
+		t.Errorf("stats = %+v, want one healthy scrape of 2 samples", stats)
+	}
+
+	metrics := make(map[string]models.Metric)
+	for _, m := range r.GetMetrics() {
+		metrics[m.Name] = m
+	}
+	counter, ok := metrics["requests_total"]
+	if !ok {
+		t.Fatalf("GetMetrics() = %v, want requests_total", metrics)
+	}
+	if counter.Type != models.MetricTypeSum {
+		t.Errorf("requests_total type = %q, want sum", counter.Type)
+	}
+	if counter.Resource.ServiceName != "api" {
+		t.Errorf("ServiceName = %q, want target job", counter.Resource.ServiceName)
+	}
+	if got := attributeValue(counter.Resource.Attributes, attrServiceInstanceID); got != loop.instance {
+		t.Errorf("service.instance.id = %v, want %q", got, loop.instance)
+	}
+	if !strings.HasPrefix(counter.Source, transportScrape+"://") {
+		t.Errorf("Source = %q, want scrape transport", counter.Source)
+	}
+
+	healthy = false
+	s.scrape(context.Background(), loop)
+
+	stats = s.stats()[0]
+	if stats.Health != scrapeHealthDown || stats.Failures != 1 || stats.LastError == "" {
+		t.Errorf("stats = %+v, want failed scrape with error", stats)
+	}
+}
+
+func TestScrapeConfigValidation(t *testing.T) {
+	for _, target := range []string{"", "localhost:9100", "ftp://host/metrics", "http:///metrics"} {
+		if _, err := newScraper(nil, ScrapeConfig{Targets: []ScrapeTarget{{URL: target}}}); err == nil {
+			t.Errorf("newScraper(%q) error = nil, want error", target)
+		}
+	}
+}
+
+func TestStopForgetsScrapeTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(testOpenMetrics))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"127.0.0.1:0"}
+	config.DisableHTTP = true
+	config.Scrape = ScrapeConfig{Targets: []ScrapeTarget{{URL: server.URL + "/metrics", Job: "api"}}}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := len(r.GetScrapeTargets()); got != 1 {
+		t.Fatalf("GetScrapeTargets() returned %d targets, want 1", got)
+	}
+
+	r.Stop()
+	if got := r.GetScrapeTargets(); len(got) != 0 {
+		t.Errorf("GetScrapeTargets() = %+v after Stop, want none", got)
+	}
+}
@@ -0,0 +1,525 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/codes"
+)
+
+// durationBounds are the histogram buckets, in seconds, for internal latencies.
+var durationBounds = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// selfSignals lists the signals reported in internal metrics, in output order.
+var selfSignals = []models.SignalType{
+	models.SignalTypeTrace,
+	models.SignalTypeMetric,
+	models.SignalTypeLog,
+	models.SignalTypeProfile,
+}
+
+// SelfTelemetryConfig controls the metrics Phosphor records about its own pipeline.
+type SelfTelemetryConfig struct {
+	Interval       time.Duration `yaml:"interval"`        // How often internal metrics are added to the metric buffer under the "phosphor" service (0 disables)
+	MetricsAddress string        `yaml:"metrics_address"` // Serve internal metrics in the Prometheus text format at /metrics on this address, e.g. ":9464"
+}
+
+// enabled reports whether internal metrics are reported anywhere.
+func (c SelfTelemetryConfig) enabled() bool {
+	return c.Interval > 0 || c.MetricsAddress != ""
+}
+
+// histogram is a fixed-bucket histogram.
+type histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // Per bucket, with a final +Inf bucket
+	sum    float64
+	count  uint64
+}
+
+// histogramSnapshot is a point-in-time copy of a histogram.
+type histogramSnapshot struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	h.counts[sort.SearchFloat64s(h.bounds, v)]++
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+func (h *histogram) snapshot() histogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return histogramSnapshot{
+		bounds: h.bounds,
+		counts: append([]uint64(nil), h.counts...),
+		sum:    h.sum,
+		count:  h.count,
+	}
+}
+
+// selfTelemetry holds the instruments that have no counterpart in ReceiverStats.
+type selfTelemetry struct {
+	start          time.Time
+	exportDuration map[models.SignalType]*histogram
+	bytes          map[models.SignalType]*atomic.Uint64
+	emitDuration   *histogram
+
+	rateMu     sync.Mutex
+	rateAt     time.Time
+	rateTotals map[models.SignalType]uint64
+	rates      map[models.SignalType]float64
+}
+
+func newSelfTelemetry() *selfTelemetry {
+	t := &selfTelemetry{
+		start:          time.Now(),
+		exportDuration: make(map[models.SignalType]*histogram),
+		bytes:          make(map[models.SignalType]*atomic.Uint64),
+		emitDuration:   newHistogram(durationBounds),
+		rates:          make(map[models.SignalType]float64),
+	}
+	for _, signal := range selfSignals {
+		t.exportDuration[signal] = newHistogram(durationBounds)
+		t.bytes[signal] = &atomic.Uint64{}
+	}
+	return t
+}
+
+// observeExport records the duration and encoded size of an export.
+func (t *selfTelemetry) observeExport(signal models.SignalType, size int, start time.Time) {
+	t.exportDuration[signal].observe(time.Since(start).Seconds())
+	t.bytes[signal].Add(uint64(size))
+}
+
+// itemRates returns items received per second for each signal, averaged
+// over at least the last second so concurrent readers see stable values.
+func (t *selfTelemetry) itemRates(totals map[models.SignalType]uint64) map[models.SignalType]float64 {
+	t.rateMu.Lock()
+	defer t.rateMu.Unlock()
+
+	now := time.Now()
+	if elapsed := now.Sub(t.rateAt); t.rateTotals == nil || elapsed >= time.Second {
+		for signal, total := range totals {
+			rate := 0.0
+			if prev, ok := t.rateTotals[signal]; ok && total >= prev {
+				rate = float64(total-prev) / elapsed.Seconds()
+			}
+			t.rates[signal] = rate
+		}
+		t.rateTotals = totals
+		t.rateAt = now
+	}
+
+	rates := make(map[models.SignalType]float64, len(t.rates))
+	for signal, rate := range t.rates {
+		rates[signal] = rate
+	}
+	return rates
+}
+
+// ObserveEmit records how long the frontend bridge took to deliver a batch
+// of events.
+func (r *OTLPReceiver) ObserveEmit(d time.Duration) {
+	r.telemetry.emitDuration.observe(d.Seconds())
+}
+
+// countsAsError reports whether a failed export is counted in
+// ReceiverStats.Errors. Authentication failures and backpressure, injected
+// or not, have their own counters, and cancellation is the client's choice.
+func countsAsError(code codes.Code) bool {
+	switch code {
+	case codes.OK, codes.Canceled, codes.Unauthenticated, codes.Unavailable, codes.ResourceExhausted:
+		return false
+	}
+	return true
+}
+
+// errorMiddleware counts failed OTLP/HTTP requests, using the same
+// exclusions as countsAsError.
+func (r *OTLPReceiver) errorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+		switch {
+		case rec.status < 400, rec.status == http.StatusUnauthorized,
+			rec.status == http.StatusTooManyRequests, rec.status == http.StatusServiceUnavailable:
+			return
+		}
+		r.recordError("HTTP %s %s failed with status %d", req.Method, req.URL.Path, rec.status)
+	})
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *statusRecorder) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// selfMetricKind is the type of an internal metric.
+type selfMetricKind int
+
+const (
+	selfCounter selfMetricKind = iota
+	selfGauge
+	selfHistogram
+)
+
+// selfMetric is one internal metric with a point per label value.
+type selfMetric struct {
+	name   string
+	help   string
+	unit   string
+	kind   selfMetricKind
+	label  string // Label shared by all points; empty for a single unlabelled point
+	points []selfPoint
+}
+
+type selfPoint struct {
+	labelValue string
+	value      float64
+	hist       histogramSnapshot
+}
+
+// selfMetrics collects the current value of every internal metric.
+func (r *OTLPReceiver) selfMetrics() []selfMetric {
+	stats := r.GetReceiverStats()
+	received := map[models.SignalType]uint64{
+		models.SignalTypeTrace:   stats.TracesReceived,
+		models.SignalTypeMetric:  stats.MetricsReceived,
+		models.SignalTypeLog:     stats.LogsReceived,
+		models.SignalTypeProfile: stats.ProfilesReceived,
+	}
+	rejected := map[models.SignalType]uint64{
+		models.SignalTypeTrace:   stats.TracesRejected,
+		models.SignalTypeMetric:  stats.MetricsRejected,
+		models.SignalTypeLog:     stats.LogsRejected,
+		models.SignalTypeProfile: stats.ProfilesRejected,
+	}
+	type bufferInfo struct {
+		len, cap int
+		evicted  uint64
+	}
+	buffers := map[models.SignalType]bufferInfo{
+		models.SignalTypeTrace:   {r.traces.Len(), r.traces.Cap(), r.traces.Evicted()},
+		models.SignalTypeMetric:  {r.metrics.Len(), r.metrics.Cap(), r.metrics.Evicted()},
+		models.SignalTypeLog:     {r.logs.Len(), r.logs.Cap(), r.logs.Evicted()},
+		models.SignalTypeProfile: {r.profiles.Len(), r.profiles.Cap(), r.profiles.Evicted()},
+	}
+	rates := r.telemetry.itemRates(received)
+
+	perSignal := func(name, help, unit string, kind selfMetricKind, value func(models.SignalType) float64) selfMetric {
+		m := selfMetric{name: name, help: help, unit: unit, kind: kind, label: "signal"}
+		for _, signal := range selfSignals {
+			m.points = append(m.points, selfPoint{labelValue: string(signal), value: value(signal)})
+		}
+		return m
+	}
+	single := func(name, help, unit string, kind selfMetricKind, value float64) selfMetric {
+		return selfMetric{name: name, help: help, unit: unit, kind: kind, points: []selfPoint{{value: value}}}
+	}
+
+	exportDuration := selfMetric{
+		name: "phosphor_receiver_export_duration_seconds", help: "Time spent handling an export request.",
+		unit: "s", kind: selfHistogram, label: "signal",
+	}
+	for _, signal := range selfSignals {
+		exportDuration.points = append(exportDuration.points, selfPoint{
+			labelValue: string(signal),
+			hist:       r.telemetry.exportDuration[signal].snapshot(),
+		})
+	}
+
+	metrics := []selfMetric{
+		exportDuration,
+		perSignal("phosphor_receiver_items_total", "Items accepted by the receiver.", "1", selfCounter,
+			func(s models.SignalType) float64 { return float64(received[s]) }),
+		perSignal("phosphor_receiver_items_per_second", "Items accepted per second over the last second.", "1/s", selfGauge,
+			func(s models.SignalType) float64 { return rates[s] }),
+		perSignal("phosphor_receiver_rejected_items_total", "Items refused as invalid.", "1", selfCounter,
+			func(s models.SignalType) float64 { return float64(rejected[s]) }),
+		perSignal("phosphor_receiver_bytes_total", "Encoded size of export requests received.", "By", selfCounter,
+			func(s models.SignalType) float64 { return float64(r.telemetry.bytes[s].Load()) }),
+		single("phosphor_receiver_errors_total", "Failed requests and ingestion errors.", "1", selfCounter, float64(stats.Errors)),
+		single("phosphor_receiver_throttled_total", "Exports refused by admission control.", "1", selfCounter, float64(stats.Throttled)),
+		single("phosphor_receiver_auth_failures_total", "Exports refused for missing or invalid credentials.", "1", selfCounter, float64(stats.AuthFailures)),
+		perSignal("phosphor_buffer_items", "Items held in the ring buffer.", "1", selfGauge,
+			func(s models.SignalType) float64 { return float64(buffers[s].len) }),
+		perSignal("phosphor_buffer_capacity", "Ring buffer capacity.", "1", selfGauge,
+			func(s models.SignalType) float64 { return float64(buffers[s].cap) }),
+		perSignal("phosphor_buffer_evictions_total", "Items overwritten by newer ones in the ring buffer.", "1", selfCounter,
+			func(s models.SignalType) float64 { return float64(buffers[s].evicted) }),
+	}
+
+	lag := selfMetric{name: "phosphor_events_callback_lag_seconds", help: "Publish-to-delivery delay of the last event.", unit: "s", kind: selfGauge, label: "subscriber"}
+	pending := selfMetric{name: "phosphor_events_pending", help: "Events queued for a subscriber.", unit: "1", kind: selfGauge, label: "subscriber"}
+	dropped := selfMetric{name: "phosphor_events_dropped_total", help: "Events dropped because a subscriber fell behind.", unit: "1", kind: selfCounter, label: "subscriber"}
+	for _, sub := range stats.Subscribers {
+		lag.points = append(lag.points, selfPoint{labelValue: sub.Name, value: sub.LagMs / 1000})
+		pending.points = append(pending.points, selfPoint{labelValue: sub.Name, value: float64(sub.Pending)})
+		dropped.points = append(dropped.points, selfPoint{labelValue: sub.Name, value: float64(sub.Dropped)})
+	}
+	metrics = append(metrics, lag, pending, dropped, selfMetric{
+		name: "phosphor_bridge_emit_duration_seconds", help: "Time taken to deliver an event batch to the frontend.",
+		unit: "s", kind: selfHistogram, points: []selfPoint{{hist: r.telemetry.emitDuration.snapshot()}},
+	})
+	return metrics
+}
+
+// writeSelfMetrics renders internal metrics in the Prometheus text format.
+func writeSelfMetrics(w io.Writer, metrics []selfMetric) {
+	types := map[selfMetricKind]string{selfCounter: "counter", selfGauge: "gauge", selfHistogram: "histogram"}
+	for _, m := range metrics {
+		if len(m.points) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, types[m.kind])
+		for _, p := range m.points {
+			var labels []string
+			if m.label != "" {
+				labels = append(labels, fmt.Sprintf("%s=%q", m.label, p.labelValue))
+			}
+			if m.kind != selfHistogram {
+				fmt.Fprintf(w, "%s%s %s\n", m.name, promLabels(labels), formatFloat(p.value))
+				continue
+			}
+			var cumulative uint64
+			for i, count := range p.hist.counts {
+				cumulative += count
+				le := "+Inf"
+				if i < len(p.hist.bounds) {
+					le = formatFloat(p.hist.bounds[i])
+				}
+				fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, promLabels(append(labels, fmt.Sprintf("le=%q", le))), cumulative)
+			}
+			fmt.Fprintf(w, "%s_sum%s %s\n", m.name, promLabels(labels), formatFloat(p.hist.sum))
+			fmt.Fprintf(w, "%s_count%s %d\n", m.name, promLabels(labels), p.hist.count)
+		}
+	}
+}
+
+func promLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	s := "{" + labels[0]
+	for _, l := range labels[1:] {
+		s += "," + l
+	}
+	return s + "}"
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// selfMetricsRequest converts internal metrics to an OTLP request from the
+// internal phosphor resource. Counters and histograms are cumulative since start.
+func selfMetricsRequest(metrics []selfMetric, start, now time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	startNano, nowNano := uint64(start.UnixNano()), uint64(now.UnixNano())
+	scope := &metricspb.ScopeMetrics{Scope: &commonpb.InstrumentationScope{Name: "phosphor/self"}}
+
+	for _, m := range metrics {
+		if len(m.points) == 0 {
+			continue
+		}
+		attributes := func(p selfPoint) []*commonpb.KeyValue {
+			if m.label == "" {
+				return nil
+			}
+			return []*commonpb.KeyValue{stringKV(m.label, p.labelValue)}
+		}
+		out := &metricspb.Metric{Name: m.name, Description: m.help, Unit: m.unit}
+
+		switch m.kind {
+		case selfHistogram:
+			hist := &metricspb.Histogram{AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE}
+			for _, p := range m.points {
+				sum := p.hist.sum
+				hist.DataPoints = append(hist.DataPoints, &metricspb.HistogramDataPoint{
+					Attributes:        attributes(p),
+					StartTimeUnixNano: startNano,
+					TimeUnixNano:      nowNano,
+					Count:             p.hist.count,
+					Sum:               &sum,
+					BucketCounts:      p.hist.counts,
+					ExplicitBounds:    p.hist.bounds,
+				})
+			}
+			out.Data = &metricspb.Metric_Histogram{Histogram: hist}
+		default:
+			var points []*metricspb.NumberDataPoint
+			for _, p := range m.points {
+				point := &metricspb.NumberDataPoint{
+					Attributes:   attributes(p),
+					TimeUnixNano: nowNano,
+					Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: p.value},
+				}
+				if m.kind == selfCounter {
+					point.StartTimeUnixNano = startNano
+				}
+				points = append(points, point)
+			}
+			if m.kind == selfCounter {
+				out.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+					DataPoints:             points,
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+					IsMonotonic:            true,
+				}}
+			} else {
+				out.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}
+			}
+		}
+		scope.Metrics = append(scope.Metrics, out)
+	}
+
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{stringKV("service.name", internalServiceName)},
+			},
+			ScopeMetrics: []*metricspb.ScopeMetrics{scope},
+		}},
+	}
+}
+
+// recordSelfMetrics stores a snapshot of the internal metrics in the metric
+// buffer. It bypasses the Export handler so the snapshot does not count as
+// received telemetry.
+func (r *OTLPReceiver) recordSelfMetrics() {
+	req := selfMetricsRequest(r.selfMetrics(), r.telemetry.start, time.Now())
+	for _, resourceMetrics := range req.ResourceMetrics {
+		resource := models.ConvertResource(resourceMetrics.Resource)
+		for _, scopeMetrics := range resourceMetrics.ScopeMetrics {
+			scope := models.ConvertInstrumentationScope(scopeMetrics.Scope)
+			for _, metric := range scopeMetrics.Metrics {
+				converted, err := models.ConvertMetric(metric, resource, scope)
+				if err != nil {
+					continue
+				}
+				r.metrics.Push(converted)
+				r.emitEvent(models.TelemetryEvent{
+					Type:      models.SignalTypeMetric,
+					Metric:    &converted,
+					Timestamp: converted.ReceivedAt,
+				})
+			}
+		}
+	}
+}
+
+// selfReporter periodically records internal metrics and serves /metrics.
+type selfReporter struct {
+	receiver *OTLPReceiver
+	interval time.Duration
+	listener net.Listener
+	server   *http.Server
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newSelfReporter opens the metrics listener, if configured.
+func newSelfReporter(r *OTLPReceiver, config SelfTelemetryConfig) (*selfReporter, error) {
+	s := &selfReporter{receiver: r, interval: config.Interval, done: make(chan struct{})}
+	if config.MetricsAddress != "" {
+		l, err := listenOn(config.MetricsAddress)
+		if err != nil {
+			return nil, err
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			writeSelfMetrics(w, r.selfMetrics())
+		})
+		s.listener = l
+		s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	}
+	return s, nil
+}
+
+func (s *selfReporter) start() {
+	if s.server != nil {
+		log.Printf("[Phosphor] Serving internal metrics on %s/metrics", listenerAddress(s.listener))
+		go func() {
+			if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.receiver.recordError("Internal metrics server error: %v", err)
+			}
+		}()
+	}
+	if s.interval > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			ticker := time.NewTicker(s.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-s.done:
+					return
+				case <-ticker.C:
+					s.receiver.recordSelfMetrics()
+				}
+			}
+		}()
+	}
+}
+
+func (s *selfReporter) stop() {
+	close(s.done)
+	s.wg.Wait()
+	if s.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.server.Shutdown(ctx)
+		closeListeners([]net.Listener{s.listener})
+	}
+}
+
+// MetricsAddress returns the address internal metrics are served on, or ""
+// when the endpoint is disabled.
+func (r *OTLPReceiver) MetricsAddress() string {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.self == nil || r.self.listener == nil {
+		return ""
+	}
+	return listenerAddress(r.self.listener)
+}
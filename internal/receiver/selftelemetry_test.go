@@ -0,0 +1,95 @@
+package receiver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+func TestSelfTelemetry(t *testing.T) {
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(t.TempDir(), "otlp.sock")}
+	config.HTTPListenAddresses = []string{"127.0.0.1:0"}
+	config.TraceCapacity = 1
+	config.SelfTelemetry = SelfTelemetryConfig{Interval: 20 * time.Millisecond, MetricsAddress: "127.0.0.1:0"}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+	defer r.OnEvent("test", func(models.TelemetryEvent) {})()
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.traceService.Export(context.Background(), testTraceRequest()); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+	resp, err := http.Post("http://"+r.HTTPAddresses()[0]+"/v1/traces", contentTypeProtobuf, strings.NewReader("not protobuf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := r.GetReceiverStats().Errors; got != 1 {
+		t.Errorf("Errors = %d, want 1 for the undecodable request", got)
+	}
+
+	resp, err = http.Get("http://" + r.MetricsAddress() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, want := range []string{
+		`# TYPE phosphor_receiver_export_duration_seconds histogram`,
+		`phosphor_receiver_export_duration_seconds_count{signal="trace"} 2`,
+		`phosphor_receiver_export_duration_seconds_bucket{signal="trace",le="+Inf"} 2`,
+		`phosphor_receiver_items_total{signal="trace"} 2`,
+		`phosphor_receiver_errors_total 1`,
+		`phosphor_buffer_evictions_total{signal="trace"} 1`,
+		`phosphor_events_callback_lag_seconds{subscriber="test"}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics is missing %q", want)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, m := range r.GetMetrics() {
+			if m.Resource.ServiceName == internalServiceName && m.Name == "phosphor_receiver_bytes_total" {
+				if got := r.GetReceiverStats().MetricsReceived; got != 0 {
+					t.Errorf("MetricsReceived = %d, want internal metrics not counted", got)
+				}
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("internal metrics never reached the metric buffer")
+}
+
+func TestCountsAsError(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want bool
+	}{
+		{codes.InvalidArgument, true},
+		{codes.Internal, true},
+		{codes.Unauthenticated, false},
+		{codes.Unavailable, false},
+		{codes.ResourceExhausted, false},
+		{codes.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := countsAsError(tt.code); got != tt.want {
+			t.Errorf("countsAsError(%v) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
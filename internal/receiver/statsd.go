@@ -0,0 +1,511 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/peer"
+)
+
+// StatsDConfig configures the StatsD/DogStatsD UDP listener.
+type StatsDConfig struct {
+	ListenAddress   string            `yaml:"listen_address"`   // UDP address such as ":8125"; the listener is disabled when empty
+	FlushInterval   time.Duration     `yaml:"flush_interval"`   // Aggregation window (default: 10s)
+	ResourceTags    map[string]string `yaml:"resource_tags"`    // Tag key -> resource attribute (default: DogStatsD service, env, version and host)
+	HistogramBounds []float64         `yaml:"histogram_bounds"` // Bucket bounds for timers, histograms and distributions
+}
+
+// defaultStatsDResourceTags maps DogStatsD unified service tags to resource
+// attributes.
+var defaultStatsDResourceTags = map[string]string{
+	"service": attrServiceName,
+	"env":     "deployment.environment",
+	"version": "service.version",
+	"host":    "host.name",
+}
+
+// defaultStatsDBounds suits timers in milliseconds.
+var defaultStatsDBounds = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// StatsD metric types.
+const (
+	statsdCounter      = "c"
+	statsdGauge        = "g"
+	statsdTimer        = "ms"
+	statsdHistogram    = "h"
+	statsdDistribution = "d"
+	statsdSet          = "s"
+)
+
+const (
+	// statsdMaxPacket is the largest UDP datagram accepted.
+	statsdMaxPacket = 65535
+
+	// statsdGaugeExpiry is how many flush intervals a gauge keeps its last
+	// value without an update. A relative update after that starts from 0,
+	// so series from high-cardinality tags are not kept forever.
+	statsdGaugeExpiry = 6
+
+	// attrContainerID records the DogStatsD container field.
+	attrContainerID = "container.id"
+)
+
+// statsdLine is one parsed StatsD metric line.
+type statsdLine struct {
+	name        string
+	typ         string
+	values      []string // DogStatsD allows several values per line
+	rate        float64
+	tags        map[string]string
+	containerID string
+}
+
+// parseStatsDLine parses `name:value[:value...]|type[|@rate][|#tags][|c:container][|T<timestamp>]`.
+func parseStatsDLine(line string) (statsdLine, error) {
+	parts := strings.Split(line, "|")
+	if len(parts) < 2 {
+		return statsdLine{}, fmt.Errorf("missing metric type in %q", line)
+	}
+
+	name, value, ok := strings.Cut(parts[0], ":")
+	if !ok || name == "" || value == "" {
+		return statsdLine{}, fmt.Errorf("invalid metric %q", parts[0])
+	}
+	l := statsdLine{name: name, typ: parts[1], rate: 1}
+
+	switch l.typ {
+	case statsdCounter, statsdGauge, statsdTimer, statsdHistogram, statsdDistribution:
+		l.values = strings.Split(value, ":")
+		for _, v := range l.values {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return statsdLine{}, fmt.Errorf("invalid value %q for %s", v, name)
+			}
+		}
+	case statsdSet:
+		l.values = []string{value}
+	default:
+		return statsdLine{}, fmt.Errorf("unsupported metric type %q for %s", l.typ, name)
+	}
+
+	for _, field := range parts[2:] {
+		switch {
+		case strings.HasPrefix(field, "@"):
+			rate, err := strconv.ParseFloat(field[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return statsdLine{}, fmt.Errorf("invalid sample rate %q for %s", field, name)
+			}
+			l.rate = rate
+		case strings.HasPrefix(field, "#"):
+			l.tags = make(map[string]string)
+			for _, tag := range strings.Split(field[1:], ",") {
+				if tag == "" {
+					continue
+				}
+				k, v, _ := strings.Cut(tag, ":")
+				l.tags[k] = v
+			}
+		case strings.HasPrefix(field, "c:"):
+			l.containerID = field[2:]
+		}
+		// Other extensions, such as T<timestamp>, are accepted and ignored.
+	}
+	return l, nil
+}
+
+// statsdSeries accumulates one metric and attribute set over a flush interval.
+type statsdSeries struct {
+	name     string
+	typ      string
+	resource map[string]string
+	attrs    map[string]string
+
+	value float64             // counter total or gauge value
+	set   map[string]struct{} // unique set members
+
+	count    float64 // histogram observations, weighted by sample rate
+	sum      float64
+	min, max float64
+	buckets  []float64
+}
+
+// gaugeState is the last value of a gauge and the flush interval it was set in.
+type gaugeState struct {
+	value float64
+	flush uint64
+}
+
+// statsdBatch holds the series received from one client during a flush interval.
+type statsdBatch struct {
+	addr   net.Addr
+	series map[string]*statsdSeries
+}
+
+// statsdServer receives StatsD datagrams and exports aggregated metrics on
+// every flush interval.
+type statsdServer struct {
+	receiver     *OTLPReceiver
+	conn         net.PacketConn
+	interval     time.Duration
+	resourceTags map[string]string
+	bounds       []float64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	batches     map[string]*statsdBatch
+	gauges      map[string]gaugeState // last value per gauge for relative updates
+	flushes     uint64                // completed flush intervals
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newStatsDServer applies defaults and binds the UDP listener.
+func newStatsDServer(r *OTLPReceiver, config StatsDConfig) (*statsdServer, error) {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	if config.ResourceTags == nil {
+		config.ResourceTags = defaultStatsDResourceTags
+	}
+	if len(config.HistogramBounds) == 0 {
+		config.HistogramBounds = defaultStatsDBounds
+	}
+	bounds := slices.Clone(config.HistogramBounds)
+	slices.Sort(bounds)
+
+	conn, err := net.ListenPacket("udp", config.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on udp %s: %w", config.ListenAddress, err)
+	}
+
+	return &statsdServer{
+		receiver:     r,
+		conn:         conn,
+		interval:     config.FlushInterval,
+		resourceTags: config.ResourceTags,
+		bounds:       bounds,
+		windowStart:  time.Now(),
+		batches:      make(map[string]*statsdBatch),
+		gauges:       make(map[string]gaugeState),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// start launches the read and flush loops.
+func (s *statsdServer) start() {
+	log.Printf("[Phosphor] StatsD receiver listening on %s (udp)", s.conn.LocalAddr())
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.readLoop()
+	}()
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.flush()
+			}
+		}
+	}()
+}
+
+// stop closes the socket and flushes whatever was received since the last interval.
+func (s *statsdServer) stop() {
+	close(s.done)
+	s.conn.Close()
+	s.wg.Wait()
+	s.flush()
+}
+
+// readLoop reads datagrams until the socket is closed.
+func (s *statsdServer) readLoop() {
+	buf := make([]byte, statsdMaxPacket)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.receiver.recordError("StatsD read error: %v", err)
+			continue
+		}
+		s.handlePacket(addr, buf[:n])
+	}
+}
+
+// handlePacket aggregates every metric line in a datagram.
+func (s *statsdServer) handlePacket(addr net.Addr, packet []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, line := range strings.Split(string(packet), "\n") {
+		line = strings.TrimSpace(line)
+		// DogStatsD events and service checks have no metric equivalent.
+		if line == "" || strings.HasPrefix(line, "_e{") || strings.HasPrefix(line, "_sc|") {
+			continue
+		}
+		l, err := parseStatsDLine(line)
+		if err != nil {
+			s.receiver.recordRejection(models.SignalTypeMetric, "", fmt.Errorf("statsd: %w", err))
+			continue
+		}
+		s.addLocked(addr, l)
+	}
+}
+
+// addLocked merges a parsed line into the client's batch.
+func (s *statsdServer) addLocked(addr net.Addr, l statsdLine) {
+	resource := make(map[string]string)
+	attrs := make(map[string]string)
+	for k, v := range l.tags {
+		if attr, ok := s.resourceTags[k]; ok {
+			resource[attr] = v
+		} else {
+			attrs[k] = v
+		}
+	}
+	if l.containerID != "" {
+		resource[attrContainerID] = l.containerID
+	}
+	if resource[attrServiceName] == "" {
+		resource[attrServiceName] = "statsd"
+	}
+
+	b, ok := s.batches[addr.String()]
+	if !ok {
+		b = &statsdBatch{addr: addr, series: make(map[string]*statsdSeries)}
+		s.batches[addr.String()] = b
+	}
+
+	seriesKey := l.name + "|" + l.typ + "|" + labelKey(resource) + "|" + labelKey(attrs)
+	series, ok := b.series[seriesKey]
+	if !ok {
+		series = &statsdSeries{name: l.name, typ: l.typ, resource: resource, attrs: attrs}
+		switch l.typ {
+		case statsdSet:
+			series.set = make(map[string]struct{})
+		case statsdTimer, statsdHistogram, statsdDistribution:
+			series.buckets = make([]float64, len(s.bounds)+1)
+			series.min, series.max = math.Inf(+1), math.Inf(-1)
+		case statsdGauge:
+			series.value = s.gauges[seriesKey].value
+		}
+		b.series[seriesKey] = series
+	}
+
+	weight := 1 / l.rate
+	for _, raw := range l.values {
+		if l.typ == statsdSet {
+			series.set[raw] = struct{}{}
+			continue
+		}
+
+		v, _ := strconv.ParseFloat(raw, 64)
+		switch l.typ {
+		case statsdCounter:
+			series.value += v * weight
+		case statsdGauge:
+			// A leading sign makes the update relative to the last value, as
+			// in etsy/statsd; negative gauges must be reset to 0 first.
+			if strings.HasPrefix(raw, "+") || strings.HasPrefix(raw, "-") {
+				series.value += v
+			} else {
+				series.value = v
+			}
+			s.gauges[seriesKey] = gaugeState{value: series.value, flush: s.flushes}
+		default:
+			series.count += weight
+			series.sum += v * weight
+			series.min = math.Min(series.min, v)
+			series.max = math.Max(series.max, v)
+			series.buckets[sort.SearchFloat64s(s.bounds, v)] += weight
+		}
+	}
+}
+
+// labelKey returns a canonical string for a tag set.
+func labelKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "=" + tags[k] + "\xff")
+	}
+	return b.String()
+}
+
+// flush exports the current interval's aggregates, one request per client.
+func (s *statsdServer) flush() {
+	s.mu.Lock()
+	batches := s.batches
+	start, end := s.windowStart, time.Now()
+	s.batches = make(map[string]*statsdBatch)
+	s.windowStart = end
+	s.flushes++
+	for key, g := range s.gauges {
+		if s.flushes-g.flush > statsdGaugeExpiry {
+			delete(s.gauges, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, b := range batches {
+		req := s.buildRequest(b, start, end)
+		ctx := withTransport(context.Background(), transportStatsD)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: b.addr})
+		if _, err := s.receiver.metricsService.Export(ctx, req); err != nil {
+			s.receiver.recordError("Failed to export StatsD metrics from %s: %v", b.addr, err)
+		}
+	}
+}
+
+// buildRequest converts a client's aggregates into an OTLP export request.
+func (s *statsdServer) buildRequest(b *statsdBatch, start, end time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	keys := make([]string, 0, len(b.series))
+	for k := range b.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	scopes := make(map[string]*metricspb.ScopeMetrics)
+	metrics := make(map[string]*metricspb.Metric)
+	startNano, endNano := uint64(start.UnixNano()), uint64(end.UnixNano())
+
+	for _, key := range keys {
+		series := b.series[key]
+
+		resKey := labelKey(series.resource)
+		sm, ok := scopes[resKey]
+		if !ok {
+			sm = &metricspb.ScopeMetrics{Scope: &commonpb.InstrumentationScope{Name: "statsd"}}
+			req.ResourceMetrics = append(req.ResourceMetrics, &metricspb.ResourceMetrics{
+				Resource:     &resourcepb.Resource{Attributes: tagAttributes(series.resource)},
+				ScopeMetrics: []*metricspb.ScopeMetrics{sm},
+			})
+			scopes[resKey] = sm
+		}
+
+		metricKey := resKey + "|" + series.name + "|" + series.typ
+		m, ok := metrics[metricKey]
+		if !ok {
+			m = newStatsDMetric(series)
+			sm.Metrics = append(sm.Metrics, m)
+			metrics[metricKey] = m
+		}
+
+		attrs := tagAttributes(series.attrs)
+		switch series.typ {
+		case statsdCounter:
+			sum := m.GetSum()
+			sum.DataPoints = append(sum.DataPoints, &metricspb.NumberDataPoint{
+				Attributes:        attrs,
+				StartTimeUnixNano: startNano,
+				TimeUnixNano:      endNano,
+				Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: series.value},
+			})
+		case statsdGauge, statsdSet:
+			dp := &metricspb.NumberDataPoint{Attributes: attrs, TimeUnixNano: endNano}
+			if series.typ == statsdSet {
+				dp.Value = &metricspb.NumberDataPoint_AsInt{AsInt: int64(len(series.set))}
+			} else {
+				dp.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: series.value}
+			}
+			m.GetGauge().DataPoints = append(m.GetGauge().DataPoints, dp)
+		default:
+			counts := make([]uint64, len(series.buckets))
+			for i, c := range series.buckets {
+				counts[i] = uint64(math.Round(c))
+			}
+			sum, minimum, maximum := series.sum, series.min, series.max
+			hist := m.GetHistogram()
+			hist.DataPoints = append(hist.DataPoints, &metricspb.HistogramDataPoint{
+				Attributes:        attrs,
+				StartTimeUnixNano: startNano,
+				TimeUnixNano:      endNano,
+				Count:             uint64(math.Round(series.count)),
+				Sum:               &sum,
+				Min:               &minimum,
+				Max:               &maximum,
+				ExplicitBounds:    s.bounds,
+				BucketCounts:      counts,
+			})
+		}
+	}
+	return req
+}
+
+// newStatsDMetric creates the OTLP metric for a StatsD type: counters become
+// delta sums, gauges and sets gauges, and timers, histograms and
+// distributions delta histograms.
+func newStatsDMetric(series *statsdSeries) *metricspb.Metric {
+	m := &metricspb.Metric{Name: series.name}
+	switch series.typ {
+	case statsdCounter:
+		m.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+			IsMonotonic:            true,
+		}}
+	case statsdGauge, statsdSet:
+		m.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+	default:
+		if series.typ == statsdTimer {
+			m.Unit = "ms"
+		}
+		m.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+		}}
+	}
+	return m
+}
+
+// tagAttributes converts a tag set to sorted OTLP attributes.
+func tagAttributes(tags map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, stringKV(k, tags[k]))
+	}
+	return attrs
+}
+
+// StatsDAddress returns the address the StatsD listener is bound to, or an
+// empty string when it is disabled.
+func (r *OTLPReceiver) StatsDAddress() string {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.statsd == nil {
+		return ""
+	}
+	return r.statsd.conn.LocalAddr().String()
+}
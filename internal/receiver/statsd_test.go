@@ -0,0 +1,195 @@
+package receiver
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+func TestParseStatsDLine(t *testing.T) {
+	tests := []struct {
+		line        string
+		wantName    string
+		wantType    string
+		wantValues  int
+		wantRate    float64
+		wantTags    map[string]string
+		wantErr     bool
+		wantContain string
+	}{
+		{line: "requests:1|c", wantName: "requests", wantType: statsdCounter, wantValues: 1, wantRate: 1},
+		{line: "requests:3|c|@0.1|#service:api,env:dev", wantName: "requests", wantType: statsdCounter, wantValues: 1, wantRate: 0.1,
+			wantTags: map[string]string{"service": "api", "env": "dev"}},
+		{line: "latency:12:15:40|d|#route:/a,canary", wantName: "latency", wantType: statsdDistribution, wantValues: 3, wantRate: 1,
+			wantTags: map[string]string{"route": "/a", "canary": ""}},
+		{line: "queue:-2|g|c:abc123|T1700000000", wantName: "queue", wantType: statsdGauge, wantValues: 1, wantRate: 1, wantContain: "abc123"},
+		{line: "users:alice|s", wantName: "users", wantType: statsdSet, wantValues: 1, wantRate: 1},
+		{line: "no_type:1", wantErr: true},
+		{line: "bad_value:abc|c", wantErr: true},
+		{line: "bad_type:1|x", wantErr: true},
+		{line: "bad_rate:1|c|@2", wantErr: true},
+		{line: ":1|c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			l, err := parseStatsDLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatsDLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if l.name != tt.wantName || l.typ != tt.wantType {
+				t.Errorf("name/type = %q/%q, want %q/%q", l.name, l.typ, tt.wantName, tt.wantType)
+			}
+			if len(l.values) != tt.wantValues {
+				t.Errorf("values = %v, want %d", l.values, tt.wantValues)
+			}
+			if l.rate != tt.wantRate {
+				t.Errorf("rate = %v, want %v", l.rate, tt.wantRate)
+			}
+			for k, v := range tt.wantTags {
+				if got, ok := l.tags[k]; !ok || got != v {
+					t.Errorf("tag %s = %q, want %q", k, got, v)
+				}
+			}
+			if l.containerID != tt.wantContain {
+				t.Errorf("containerID = %q, want %q", l.containerID, tt.wantContain)
+			}
+		})
+	}
+}
+
+func TestStatsDAggregation(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(dir, "otlp.sock")}
+	config.HTTPListenAddresses = []string{"unix://" + filepath.Join(dir, "http.sock")}
+	config.StatsD = StatsDConfig{
+		ListenAddress:   "127.0.0.1:0",
+		FlushInterval:   time.Hour,
+		HistogramBounds: []float64{100, 10},
+	}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	conn, err := net.Dial("udp", r.StatsDAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	packets := []string{
+		"requests:1|c|#service:api,route:/a\nrequests:2|c|@0.5|#service:api,route:/a",
+		"temperature:20|g\ntemperature:+5|g",
+		"latency:5:50|ms|#service:api\nlatency:500|ms|#service:api",
+		"users:alice|s\nusers:bob|s\nusers:alice|s",
+		"_e{5,4}:title|text\nbroken|c",
+	}
+	for _, p := range packets {
+		if _, err := conn.Write([]byte(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Wait for the datagrams to be read, then flush by stopping.
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.GetRejections()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Stop()
+
+	metrics := make(map[string]models.Metric)
+	for _, m := range r.GetMetrics() {
+		metrics[m.Name] = m
+	}
+	if len(metrics) != 4 {
+		t.Fatalf("GetMetrics() returned %v, want 4 metrics", metrics)
+	}
+
+	requests := metrics["requests"]
+	if requests.Type != models.MetricTypeSum || requests.AggregationTemporality != "delta" {
+		t.Errorf("requests type = %q/%q, want delta sum", requests.Type, requests.AggregationTemporality)
+	}
+	if got := *requests.DataPoints[0].ValueDouble; got != 5 {
+		t.Errorf("requests = %v, want 5 (1 + 2 at rate 0.5)", got)
+	}
+	if requests.Resource.ServiceName != "api" {
+		t.Errorf("ServiceName = %q, want service tag", requests.Resource.ServiceName)
+	}
+	if got := attributeValue(requests.DataPoints[0].Attributes, "route"); got != "/a" {
+		t.Errorf("route attribute = %v, want '/a'", got)
+	}
+	if attributeValue(requests.DataPoints[0].Attributes, "service") != nil {
+		t.Error("service tag kept as data point attribute")
+	}
+
+	if got := *metrics["temperature"].DataPoints[0].ValueDouble; got != 25 {
+		t.Errorf("temperature = %v, want 25", got)
+	}
+	if metrics["temperature"].Resource.ServiceName != "statsd" {
+		t.Errorf("untagged ServiceName = %q, want 'statsd'", metrics["temperature"].Resource.ServiceName)
+	}
+	if got := *metrics["users"].DataPoints[0].ValueInt64; got != 2 {
+		t.Errorf("users = %d, want 2 unique members", got)
+	}
+
+	latency := metrics["latency"]
+	if latency.Type != models.MetricTypeHistogram || latency.Unit != "ms" {
+		t.Errorf("latency type/unit = %q/%q, want histogram in ms", latency.Type, latency.Unit)
+	}
+	dp := latency.DataPoints[0]
+	if *dp.Count != 3 || *dp.Sum != 555 {
+		t.Errorf("count/sum = %d/%v, want 3/555", *dp.Count, *dp.Sum)
+	}
+	wantCounts := []uint64{1, 1, 1}
+	for i, c := range wantCounts {
+		if dp.BucketCounts[i] != c {
+			t.Errorf("BucketCounts = %v, want %v", dp.BucketCounts, wantCounts)
+			break
+		}
+	}
+
+	if len(r.GetRejections()) != 1 {
+		t.Errorf("GetRejections() = %+v, want the malformed line", r.GetRejections())
+	}
+}
+
+func TestStatsDGaugeExpiry(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	s, err := newStatsDServer(r, StatsDConfig{ListenAddress: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.conn.Close()
+	addr := s.conn.LocalAddr()
+
+	s.handlePacket(addr, []byte("queue:10|g|#shard:1\nqueue:10|g|#shard:2"))
+	for i := 0; i < statsdGaugeExpiry; i++ {
+		s.flush()
+		// Keep shard 1 alive with a relative update of zero.
+		s.handlePacket(addr, []byte("queue:+0|g|#shard:1"))
+	}
+	s.flush()
+
+	if len(s.gauges) != 1 {
+		t.Fatalf("gauges = %v, want only the updated series kept", s.gauges)
+	}
+
+	// The expired series starts again from 0 on a relative update.
+	s.handlePacket(addr, []byte("queue:+3|g|#shard:2\nqueue:+3|g|#shard:1"))
+	s.flush()
+	got := make(map[interface{}]float64)
+	for _, dp := range r.GetRecentMetrics(1)[0].DataPoints {
+		got[attributeValue(dp.Attributes, "shard")] = *dp.ValueDouble
+	}
+	if got["1"] != 13 || got["2"] != 3 {
+		t.Errorf("queue by shard = %v, want 1:13 and 2:3", got)
+	}
+}
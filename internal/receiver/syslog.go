@@ -0,0 +1,567 @@
+package receiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/peer"
+)
+
+// SyslogConfig configures the syslog listeners.
+type SyslogConfig struct {
+	TCPAddress string `yaml:"tcp_address"` // TCP address such as ":5514"; disabled when empty
+	UDPAddress string `yaml:"udp_address"` // UDP address such as ":5514"; disabled when empty
+}
+
+const (
+	// maxSyslogMessage bounds a single message (RFC 5425 recommends 8KB; allow more).
+	maxSyslogMessage = 64 * 1024
+
+	// maxSyslogLengthDigits is the longest octet count prefix accepted, as
+	// many digits as maxSyslogMessage has.
+	maxSyslogLengthDigits = 5
+
+	// syslogBatchSize is the most messages exported at once from a TCP stream.
+	syslogBatchSize = 100
+
+	// syslogNil is the RFC 5424 NILVALUE.
+	syslogNil = "-"
+)
+
+// Log attribute keys for syslog header fields without a semantic convention.
+const (
+	attrSyslogFacility       = "syslog.facility"
+	attrSyslogVersion        = "syslog.version"
+	attrSyslogProcID         = "syslog.procid"
+	attrSyslogMsgID          = "syslog.msgid"
+	attrSyslogStructuredData = "syslog.structured_data"
+	attrHostName             = "host.name"
+)
+
+// syslogSeverities maps syslog severities (0-7) to OTLP severity numbers and
+// their keywords, following the OpenTelemetry Collector's syslog parser.
+var syslogSeverities = [8]struct {
+	number  logspb.SeverityNumber
+	keyword string
+}{
+	{logspb.SeverityNumber_SEVERITY_NUMBER_FATAL2, "emerg"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_FATAL, "alert"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_ERROR2, "crit"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, "err"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_WARN, "warning"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_INFO2, "notice"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_INFO, "info"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG, "debug"},
+}
+
+// syslogMessage is a parsed RFC 5424 or RFC 3164 message.
+type syslogMessage struct {
+	facility  int
+	severity  int
+	version   int // 1 for RFC 5424, 0 for RFC 3164
+	timestamp time.Time
+	hostname  string
+	appName   string
+	procID    string
+	msgID     string
+	sd        []syslogSDElement
+	message   string
+}
+
+// syslogSDElement is one RFC 5424 structured data element.
+type syslogSDElement struct {
+	id     string
+	params [][2]string
+}
+
+// parseSyslog parses a message in either format, detected from the version
+// field after the priority. now supplies the year for RFC 3164 timestamps.
+func parseSyslog(data []byte, now time.Time) (syslogMessage, error) {
+	s := strings.TrimRight(string(data), "\r\n\x00")
+	if !strings.HasPrefix(s, "<") {
+		return syslogMessage{}, errors.New("missing priority")
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return syslogMessage{}, fmt.Errorf("invalid priority in %q", s[:min(len(s), 16)])
+	}
+	pri, err := strconv.Atoi(s[1:end])
+	if err != nil || pri < 0 || pri > 191 {
+		return syslogMessage{}, fmt.Errorf("invalid priority %q", s[1:end])
+	}
+	msg := syslogMessage{facility: pri / 8, severity: pri % 8}
+	s = s[end+1:]
+
+	if strings.HasPrefix(s, "1 ") {
+		msg.version = 1
+		return msg, parseRFC5424(s[2:], &msg)
+	}
+	parseRFC3164(s, now, &msg)
+	return msg, nil
+}
+
+// parseRFC5424 parses the header, structured data and message after VERSION.
+func parseRFC5424(s string, msg *syslogMessage) error {
+	fields := make([]string, 5)
+	for i := range fields {
+		var ok bool
+		fields[i], s, ok = strings.Cut(s, " ")
+		if !ok && i < len(fields)-1 {
+			return errors.New("truncated RFC 5424 header")
+		}
+	}
+
+	if fields[0] != syslogNil {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", fields[0])
+		}
+		msg.timestamp = ts
+	}
+	msg.hostname = nilToEmpty(fields[1])
+	msg.appName = nilToEmpty(fields[2])
+	msg.procID = nilToEmpty(fields[3])
+	msg.msgID = nilToEmpty(fields[4])
+
+	switch {
+	case s == "" || s == syslogNil:
+		s = ""
+	case strings.HasPrefix(s, syslogNil+" "):
+		s = s[2:]
+	case strings.HasPrefix(s, "["):
+		var err error
+		if msg.sd, s, err = parseStructuredData(s); err != nil {
+			return err
+		}
+		s = strings.TrimPrefix(s, " ")
+	default:
+		return errors.New("invalid structured data")
+	}
+
+	msg.message = strings.TrimPrefix(s, "\ufeff") // UTF-8 BOM
+	return nil
+}
+
+// parseStructuredData parses consecutive [id param="value" ...] elements and
+// returns the remainder of the message.
+func parseStructuredData(s string) ([]syslogSDElement, string, error) {
+	var elements []syslogSDElement
+	for strings.HasPrefix(s, "[") {
+		end := strings.IndexAny(s, " ]")
+		if end < 0 {
+			return nil, "", errors.New("unterminated structured data")
+		}
+		el := syslogSDElement{id: s[1:end]}
+		s = s[end:]
+
+		for strings.HasPrefix(s, " ") {
+			s = s[1:]
+			eq := strings.Index(s, `="`)
+			if eq <= 0 {
+				return nil, "", fmt.Errorf("invalid parameter in structured data %s", el.id)
+			}
+			name := s[:eq]
+			s = s[eq+2:]
+
+			var value strings.Builder
+			i := 0
+			for ; i < len(s) && s[i] != '"'; i++ {
+				// Only ", \ and ] are escaped; other backslashes are literal.
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0 {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, "", fmt.Errorf("unterminated parameter %s in structured data %s", name, el.id)
+			}
+			el.params = append(el.params, [2]string{name, value.String()})
+			s = s[i+1:]
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, "", fmt.Errorf("unterminated structured data %s", el.id)
+		}
+		s = s[1:]
+		elements = append(elements, el)
+	}
+	return elements, s, nil
+}
+
+// parseRFC3164 parses `TIMESTAMP HOSTNAME TAG[PID]: MSG`. The format is
+// loosely followed in practice, so missing parts are tolerated and whatever
+// cannot be parsed is kept in the message.
+func parseRFC3164(s string, now time.Time, msg *syslogMessage) {
+	if len(s) >= 15 {
+		if ts, err := time.ParseInLocation(time.Stamp, s[:15], now.Location()); err == nil {
+			// RFC 3164 omits the year; assume the most recent occurrence.
+			ts = ts.AddDate(now.Year(), 0, 0)
+			if ts.After(now.Add(24 * time.Hour)) {
+				ts = ts.AddDate(-1, 0, 0)
+			}
+			msg.timestamp = ts
+			s = strings.TrimPrefix(s[15:], " ")
+		}
+	}
+	if msg.timestamp.IsZero() {
+		// Some senders use RFC 3339 timestamps with the legacy header.
+		if field, rest, ok := strings.Cut(s, " "); ok {
+			if ts, err := time.Parse(time.RFC3339Nano, field); err == nil {
+				msg.timestamp = ts
+				s = rest
+			}
+		}
+	}
+
+	// The hostname is absent when the first word is already the tag.
+	if field, rest, ok := strings.Cut(s, " "); ok && !msg.timestamp.IsZero() && !isSyslogTag(field) {
+		msg.hostname = field
+		s = rest
+	}
+	if field, rest, ok := strings.Cut(s, " "); ok && isSyslogTag(field) {
+		tag := strings.TrimSuffix(field, ":")
+		if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+			msg.procID = tag[open+1 : len(tag)-1]
+			tag = tag[:open]
+		}
+		msg.appName = tag
+		s = rest
+	}
+	msg.message = s
+}
+
+// isSyslogTag reports whether field looks like an RFC 3164 TAG such as
+// "sshd[42]:" or "cron:".
+func isSyslogTag(field string) bool {
+	return strings.HasSuffix(field, ":")
+}
+
+func nilToEmpty(s string) string {
+	if s == syslogNil {
+		return ""
+	}
+	return s
+}
+
+// syslogToOTLP converts parsed messages into an OTLP logs request, grouping
+// them into resources by hostname and app-name.
+func syslogToOTLP(messages []syslogMessage, observed time.Time) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	scopes := make(map[[2]string]*logspb.ScopeLogs)
+
+	for _, msg := range messages {
+		key := [2]string{msg.hostname, msg.appName}
+		sl, ok := scopes[key]
+		if !ok {
+			serviceName := msg.appName
+			if serviceName == "" {
+				serviceName = "syslog"
+			}
+			attrs := []*commonpb.KeyValue{stringKV(attrServiceName, serviceName)}
+			if msg.hostname != "" {
+				attrs = append(attrs, stringKV(attrHostName, msg.hostname))
+			}
+			sl = &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: "syslog"}}
+			req.ResourceLogs = append(req.ResourceLogs, &logspb.ResourceLogs{
+				Resource:  &resourcepb.Resource{Attributes: attrs},
+				ScopeLogs: []*logspb.ScopeLogs{sl},
+			})
+			scopes[key] = sl
+		}
+		sl.LogRecords = append(sl.LogRecords, syslogLogRecord(msg, observed))
+	}
+	return req
+}
+
+// syslogLogRecord maps a message's severity, header fields and structured
+// data onto a log record.
+func syslogLogRecord(msg syslogMessage, observed time.Time) *logspb.LogRecord {
+	severity := syslogSeverities[msg.severity]
+	record := &logspb.LogRecord{
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+		SeverityNumber:       severity.number,
+		SeverityText:         severity.keyword,
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: msg.message}},
+		Attributes:           []*commonpb.KeyValue{intKV(attrSyslogFacility, int64(msg.facility))},
+	}
+	if !msg.timestamp.IsZero() {
+		record.TimeUnixNano = uint64(msg.timestamp.UnixNano())
+	}
+	if msg.version > 0 {
+		record.Attributes = append(record.Attributes, intKV(attrSyslogVersion, int64(msg.version)))
+	}
+	if msg.procID != "" {
+		record.Attributes = append(record.Attributes, stringKV(attrSyslogProcID, msg.procID))
+	}
+	if msg.msgID != "" {
+		record.Attributes = append(record.Attributes, stringKV(attrSyslogMsgID, msg.msgID))
+	}
+	if len(msg.sd) > 0 {
+		elements := &commonpb.KeyValueList{}
+		for _, el := range msg.sd {
+			params := &commonpb.KeyValueList{}
+			for _, p := range el.params {
+				params.Values = append(params.Values, stringKV(p[0], p[1]))
+			}
+			elements.Values = append(elements.Values, &commonpb.KeyValue{
+				Key:   el.id,
+				Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: params}},
+			})
+		}
+		record.Attributes = append(record.Attributes, &commonpb.KeyValue{
+			Key:   attrSyslogStructuredData,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: elements}},
+		})
+	}
+	return record
+}
+
+// syslogServer accepts syslog over TCP and UDP.
+type syslogServer struct {
+	receiver *OTLPReceiver
+	listener net.Listener
+	conn     net.PacketConn
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+
+	wg sync.WaitGroup
+}
+
+// newSyslogServer binds the configured listeners.
+func newSyslogServer(r *OTLPReceiver, config SyslogConfig) (*syslogServer, error) {
+	s := &syslogServer{receiver: r, conns: make(map[net.Conn]struct{})}
+
+	if config.TCPAddress != "" {
+		l, err := net.Listen("tcp", config.TCPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on tcp %s: %w", config.TCPAddress, err)
+		}
+		s.listener = l
+	}
+	if config.UDPAddress != "" {
+		conn, err := net.ListenPacket("udp", config.UDPAddress)
+		if err != nil {
+			if s.listener != nil {
+				s.listener.Close()
+			}
+			return nil, fmt.Errorf("failed to listen on udp %s: %w", config.UDPAddress, err)
+		}
+		s.conn = conn
+	}
+	return s, nil
+}
+
+// start launches the accept and read loops.
+func (s *syslogServer) start() {
+	if s.listener != nil {
+		log.Printf("[Phosphor] Syslog receiver listening on %s (tcp)", s.listener.Addr())
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.acceptLoop()
+		}()
+	}
+	if s.conn != nil {
+		log.Printf("[Phosphor] Syslog receiver listening on %s (udp)", s.conn.LocalAddr())
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.readLoop()
+		}()
+	}
+}
+
+// stop closes the listeners and open connections and waits for readers to exit.
+func (s *syslogServer) stop() {
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// addresses returns the bound listeners as tcp:// and udp:// URLs.
+func (s *syslogServer) addresses() []string {
+	var addrs []string
+	if s.listener != nil {
+		addrs = append(addrs, "tcp://"+s.listener.Addr().String())
+	}
+	if s.conn != nil {
+		addrs = append(addrs, "udp://"+s.conn.LocalAddr().String())
+	}
+	return addrs
+}
+
+func (s *syslogServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.receiver.recordError("Syslog accept error: %v", err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			conn.Close()
+			s.receiver.clients.disconnect(transportSyslog, peerAddress(conn.RemoteAddr()))
+		}()
+	}
+}
+
+// serveConn reads framed messages from a TCP stream, exporting whatever has
+// been read each time the stream goes idle or a batch fills up.
+func (s *syslogServer) serveConn(conn net.Conn) {
+	reader := bufio.NewReaderSize(conn, maxSyslogMessage)
+	var batch [][]byte
+	for {
+		frame, err := readSyslogFrame(reader)
+		if len(frame) > 0 {
+			batch = append(batch, frame)
+		}
+		if len(batch) > 0 && (err != nil || reader.Buffered() == 0 || len(batch) >= syslogBatchSize) {
+			s.export(conn.RemoteAddr(), batch)
+			batch = nil
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				s.receiver.recordError("Syslog connection from %s closed: %v", peerAddress(conn.RemoteAddr()), err)
+			}
+			return
+		}
+	}
+}
+
+// readSyslogFrame reads one RFC 6587 frame: octet-counted ("LEN SP MSG") when
+// the stream starts with a digit, otherwise newline-delimited. Blank lines
+// between frames are skipped.
+func readSyslogFrame(reader *bufio.Reader) ([]byte, error) {
+	for {
+		first, err := reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if first[0] >= '0' && first[0] <= '9' {
+			return readOctetCountedFrame(reader)
+		}
+
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("message exceeds %d bytes", maxSyslogMessage)
+		}
+		frame := bytes.TrimRight(line, "\r\n")
+		if len(frame) > 0 || err != nil {
+			return bytes.Clone(frame), err
+		}
+	}
+}
+
+// readOctetCountedFrame reads a "LEN SP MSG" frame, refusing a length prefix
+// longer than maxSyslogLengthDigits before buffering any more of it.
+func readOctetCountedFrame(reader *bufio.Reader) ([]byte, error) {
+	var prefix []byte
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == ' ' && len(prefix) > 0 {
+			break
+		}
+		prefix = append(prefix, b)
+		if b < '0' || b > '9' || len(prefix) > maxSyslogLengthDigits {
+			return nil, fmt.Errorf("invalid frame length %q", prefix)
+		}
+	}
+
+	n, err := strconv.Atoi(string(prefix))
+	if err != nil || n <= 0 || n > maxSyslogMessage {
+		return nil, fmt.Errorf("invalid frame length %q", prefix)
+	}
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+func (s *syslogServer) readLoop() {
+	buf := make([]byte, maxSyslogMessage)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.receiver.recordError("Syslog read error: %v", err)
+			continue
+		}
+		s.export(addr, [][]byte{bytes.Clone(buf[:n])})
+	}
+}
+
+// export parses frames and passes them through the shared logs handler.
+func (s *syslogServer) export(addr net.Addr, frames [][]byte) {
+	now := time.Now()
+	messages := make([]syslogMessage, 0, len(frames))
+	for _, frame := range frames {
+		msg, err := parseSyslog(frame, now)
+		if err != nil {
+			s.receiver.recordRejection(models.SignalTypeLog, "", fmt.Errorf("syslog: %w", err))
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	ctx := withTransport(context.Background(), transportSyslog)
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	if _, err := s.receiver.logsService.Export(ctx, syslogToOTLP(messages, now)); err != nil {
+		s.receiver.recordError("Failed to export syslog messages from %s: %v", peerAddress(addr), err)
+	}
+}
+
+// SyslogAddresses returns the addresses the syslog listeners are bound to.
+func (r *OTLPReceiver) SyslogAddresses() []string {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.syslog == nil {
+		return []string{}
+	}
+	return r.syslog.addresses()
+}
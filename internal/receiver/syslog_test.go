@@ -0,0 +1,219 @@
+package receiver
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+func TestParseSyslog(t *testing.T) {
+	now := time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    syslogMessage
+		wantErr bool
+	}{
+		{
+			name:  "rfc5424 with structured data",
+			input: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 42 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication"][meta seq="1"] ` + "\ufeff" + `An application event`,
+			want: syslogMessage{
+				facility: 20, severity: 5, version: 1,
+				timestamp: time.Date(2003, time.October, 11, 22, 14, 15, 3e6, time.UTC),
+				hostname:  "mymachine.example.com", appName: "evntslog", procID: "42", msgID: "ID47",
+				message: "An application event",
+			},
+		},
+		{
+			name:  "rfc5424 nil values",
+			input: `<34>1 - - - - - -`,
+			want:  syslogMessage{facility: 4, severity: 2, version: 1},
+		},
+		{
+			name:  "rfc3164",
+			input: `<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick`,
+			want: syslogMessage{
+				facility: 4, severity: 2,
+				timestamp: time.Date(2023, time.October, 11, 22, 14, 15, 0, time.UTC),
+				hostname:  "mymachine", appName: "su", procID: "230",
+				message: "'su root' failed for lonvick",
+			},
+		},
+		{
+			name:  "rfc3164 without hostname",
+			input: `<13>Jan  5 11:59:00 cron: job finished`,
+			want: syslogMessage{
+				facility: 1, severity: 5,
+				timestamp: time.Date(2024, time.January, 5, 11, 59, 0, 0, time.UTC),
+				appName:   "cron", message: "job finished",
+			},
+		},
+		{
+			name:  "rfc3164 free-form",
+			input: `<14>something happened`,
+			want:  syslogMessage{facility: 1, severity: 6, message: "something happened"},
+		},
+		{name: "missing priority", input: `hello`, wantErr: true},
+		{name: "priority out of range", input: `<192>hello`, wantErr: true},
+		{name: "bad rfc5424 timestamp", input: `<34>1 yesterday host app - - -`, wantErr: true},
+		{name: "unterminated structured data", input: `<34>1 - host app - - [id a="1"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSyslog([]byte(tt.input), now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSyslog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got.sd = nil
+			if !got.timestamp.Equal(tt.want.timestamp) {
+				t.Errorf("timestamp = %v, want %v", got.timestamp, tt.want.timestamp)
+			}
+			got.timestamp, tt.want.timestamp = time.Time{}, time.Time{}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parseSyslog() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	msg, _ := parseSyslog([]byte(tests[0].input), now)
+	if len(msg.sd) != 2 || msg.sd[0].id != "exampleSDID@32473" || msg.sd[0].params[1] != [2]string{"eventSource", `App"lication`} {
+		t.Errorf("structured data = %+v", msg.sd)
+	}
+}
+
+func TestSyslogListeners(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(dir, "otlp.sock")}
+	config.HTTPListenAddresses = []string{"unix://" + filepath.Join(dir, "http.sock")}
+	config.Syslog = SyslogConfig{TCPAddress: "127.0.0.1:0", UDPAddress: "127.0.0.1:0"}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	addrs := r.SyslogAddresses()
+	if len(addrs) != 2 {
+		t.Fatalf("SyslogAddresses() = %v, want tcp and udp", addrs)
+	}
+
+	tcp, err := net.Dial("tcp", strings.TrimPrefix(addrs[0], "tcp://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	octet := `<11>1 2024-01-05T12:00:00Z db postgres 99 - - checkpoint failed`
+	fmt.Fprintf(tcp, "%d %s", len(octet), octet)
+	fmt.Fprint(tcp, "<30>Jan  5 12:00:01 web nginx[7]: GET /\n")
+	tcp.Close()
+
+	udp, err := net.Dial("udp", strings.TrimPrefix(addrs[1], "udp://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	fmt.Fprint(udp, "<15>1 - - - - - - debug line")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.GetLogs()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	logs := make(map[string]models.LogRecord)
+	for _, l := range r.GetLogs() {
+		logs[fmt.Sprint(l.Body)] = l
+	}
+	if len(logs) != 3 {
+		t.Fatalf("GetLogs() = %v, want 3 records", logs)
+	}
+
+	tests := []struct {
+		body         string
+		wantService  string
+		wantSeverity models.SeverityLevel
+		wantText     string
+	}{
+		{"checkpoint failed", "postgres", models.SeverityError, "err"},
+		{"GET /", "nginx", models.SeverityInfo, "info"},
+		{"debug line", "syslog", models.SeverityDebug, "debug"},
+	}
+	for _, tt := range tests {
+		l, ok := logs[tt.body]
+		if !ok {
+			t.Errorf("log %q missing", tt.body)
+			continue
+		}
+		if l.Resource.ServiceName != tt.wantService {
+			t.Errorf("%q ServiceName = %q, want %q", tt.body, l.Resource.ServiceName, tt.wantService)
+		}
+		if l.Severity != tt.wantSeverity || l.SeverityText != tt.wantText {
+			t.Errorf("%q severity = %q/%q, want %q/%q", tt.body, l.Severity, l.SeverityText, tt.wantSeverity, tt.wantText)
+		}
+	}
+
+	pg := logs["checkpoint failed"]
+	if got := attributeValue(pg.Resource.Attributes, attrHostName); got != "db" {
+		t.Errorf("host.name = %v, want 'db'", got)
+	}
+	if got := attributeValue(pg.Attributes, attrSyslogFacility); got != int64(1) {
+		t.Errorf("syslog.facility = %v, want 1", got)
+	}
+	if !strings.HasPrefix(pg.Source, transportSyslog+"://") {
+		t.Errorf("Source = %q, want syslog transport", pg.Source)
+	}
+}
+
+func TestReadSyslogFrame(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     string
+		wantFrames []string
+		wantErr    string
+	}{
+		{"octet counted then newline", "5 hello<1>x\n", []string{"hello", "<1>x"}, ""},
+		{"blank lines", "\n\r\none\n\n\ntwo", []string{"one", "two"}, ""},
+		{"many blank lines", strings.Repeat("\n", 20_000_000) + "last\n", []string{"last"}, ""},
+		{"longest prefix", "65536 " + strings.Repeat("x", 65536), []string{strings.Repeat("x", 65536)}, ""},
+		{"length over the limit", "65537 x", nil, "invalid frame length"},
+		{"endless digits", strings.Repeat("9", 20_000_000), nil, `invalid frame length "999999"`},
+		{"digits then letters", "12a hello", nil, `invalid frame length "12a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReaderSize(strings.NewReader(tt.stream), maxSyslogMessage)
+			var frames []string
+			var err error
+			for {
+				var frame []byte
+				if frame, err = readSyslogFrame(reader); len(frame) > 0 {
+					frames = append(frames, string(frame))
+				}
+				if err != nil {
+					break
+				}
+			}
+			if tt.wantErr == "" && !errors.Is(err, io.EOF) {
+				t.Errorf("readSyslogFrame() error = %v, want EOF", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("readSyslogFrame() error = %v, want %q", err, tt.wantErr)
+			}
+			if fmt.Sprint(frames) != fmt.Sprint(tt.wantFrames) {
+				t.Errorf("frames = %q, want %q", frames, tt.wantFrames)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package receiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Thrift wire types used by the binary protocol.
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+)
+
+// maxThriftDepth bounds struct, list, set and map nesting so hostile payloads
+// cannot exhaust the stack.
+const maxThriftDepth = 64
+
+var (
+	errThriftTruncated = errors.New("thrift: unexpected end of input")
+	errThriftTooDeep   = errors.New("thrift: values nested too deeply")
+)
+
+// thriftReader decodes the Thrift binary protocol (TBinaryProtocol) from an
+// in-memory buffer. It implements only what Jaeger's Batch struct needs.
+type thriftReader struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+// next returns the following n bytes and advances past them.
+func (r *thriftReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.buf)-r.pos < n {
+		return nil, errThriftTruncated
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *thriftReader) readBool() (bool, error) {
+	b, err := r.readByte()
+	return b != 0, err
+}
+
+func (r *thriftReader) readI16() (int16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func (r *thriftReader) readDouble() (float64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	n, err := r.readI32()
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(n))
+}
+
+func (r *thriftReader) readString() (string, error) {
+	b, err := r.readBinary()
+	return string(b), err
+}
+
+// enter records one more level of nesting and refuses input nested deeper
+// than maxThriftDepth. Every successful enter must be paired with leave.
+func (r *thriftReader) enter() error {
+	if r.depth >= maxThriftDepth {
+		return errThriftTooDeep
+	}
+	r.depth++
+	return nil
+}
+
+func (r *thriftReader) leave() {
+	r.depth--
+}
+
+// readStruct calls field for every field in the next struct. field must
+// consume the value, typically by calling skip for unknown IDs.
+func (r *thriftReader) readStruct(field func(id int16, typ byte) error) error {
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer r.leave()
+
+	for {
+		typ, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if typ == thriftStop {
+			return nil
+		}
+		id, err := r.readI16()
+		if err != nil {
+			return err
+		}
+		if err := field(id, typ); err != nil {
+			return err
+		}
+	}
+}
+
+// readList calls elem once per element of the next list, after checking the
+// element type matches want.
+func (r *thriftReader) readList(want byte, elem func() error) error {
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer r.leave()
+
+	typ, err := r.readByte()
+	if err != nil {
+		return err
+	}
+	n, err := r.readI32()
+	if err != nil {
+		return err
+	}
+	if typ != want {
+		return fmt.Errorf("thrift: list of type %d, want %d", typ, want)
+	}
+	// Every element occupies at least one byte, which bounds hostile sizes.
+	if n < 0 || int(n) > len(r.buf)-r.pos {
+		return errThriftTruncated
+	}
+	for i := int32(0); i < n; i++ {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip consumes a value of the given type without decoding it.
+func (r *thriftReader) skip(typ byte) error {
+	var err error
+	switch typ {
+	case thriftBool, thriftByte:
+		_, err = r.next(1)
+	case thriftI16:
+		_, err = r.next(2)
+	case thriftI32:
+		_, err = r.next(4)
+	case thriftI64, thriftDouble:
+		_, err = r.next(8)
+	case thriftString:
+		_, err = r.readBinary()
+	case thriftStruct:
+		err = r.readStruct(func(_ int16, typ byte) error { return r.skip(typ) })
+	case thriftMap:
+		if err = r.enter(); err != nil {
+			return err
+		}
+		defer r.leave()
+		var kt, vt byte
+		var n int32
+		if kt, err = r.readByte(); err != nil {
+			return err
+		}
+		if vt, err = r.readByte(); err != nil {
+			return err
+		}
+		if n, err = r.readI32(); err != nil {
+			return err
+		}
+		if n < 0 || int(n) > len(r.buf)-r.pos {
+			return errThriftTruncated
+		}
+		for i := int32(0); i < n && err == nil; i++ {
+			if err = r.skip(kt); err == nil {
+				err = r.skip(vt)
+			}
+		}
+	case thriftSet, thriftList:
+		var et byte
+		if et, err = r.readByte(); err != nil {
+			return err
+		}
+		r.pos-- // Let readList re-read the element type
+		err = r.readList(et, func() error { return r.skip(et) })
+	default:
+		err = fmt.Errorf("thrift: unknown type %d", typ)
+	}
+	return err
+}
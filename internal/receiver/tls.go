@@ -0,0 +1,197 @@
+package receiver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Generated development certificate file names inside TLSConfig.CertDir.
+const (
+	devCertFile = "phosphor-dev-cert.pem"
+	devKeyFile  = "phosphor-dev-key.pem"
+
+	// devCertRenewBefore is how long before expiry the development
+	// certificate is replaced on start.
+	devCertRenewBefore = 30 * 24 * time.Hour
+)
+
+// TLSConfig holds transport security settings for the OTLP listeners.
+type TLSConfig struct {
+	Enabled           bool   `yaml:"enabled"`             // Serve TLS instead of plaintext
+	CertFile          string `yaml:"cert_file"`           // PEM server certificate
+	KeyFile           string `yaml:"key_file"`            // PEM server private key
+	ClientCAFile      string `yaml:"client_ca_file"`      // PEM CA bundle used to verify client certificates (enables mTLS)
+	RequireClientCert bool   `yaml:"require_client_cert"` // Reject clients without a valid certificate; otherwise verify only if presented
+	SelfSigned        bool   `yaml:"self_signed"`         // Generate a self-signed dev certificate when CertFile/KeyFile are unset
+	CertDir           string `yaml:"cert_dir"`            // Where generated certificates are stored (default: user config dir)
+}
+
+// buildTLSConfig assembles a *tls.Config from the receiver's TLS settings.
+// It returns nil when TLS is disabled.
+func buildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	certFile, keyFile := cfg.CertFile, cfg.KeyFile
+	if certFile == "" && keyFile == "" {
+		if !cfg.SelfSigned {
+			return nil, errors.New("TLS enabled but no certificate configured")
+		}
+		var err error
+		certFile, keyFile, err = ensureSelfSignedCert(cfg.CertDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pemData, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if cfg.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if cfg.RequireClientCert {
+		return nil, errors.New("requireClientCert set but no client CA file configured")
+	}
+
+	return tlsConfig, nil
+}
+
+// ensureSelfSignedCert returns the paths of the development certificate in dir,
+// generating a new one on first run.
+func ensureSelfSignedCert(dir string) (string, string, error) {
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to locate config dir: %w", err)
+		}
+		dir = filepath.Join(configDir, "phosphor", "tls")
+	}
+
+	certPath := filepath.Join(dir, devCertFile)
+	keyPath := filepath.Join(dir, devKeyFile)
+
+	if fileExists(certPath) && fileExists(keyPath) {
+		if devCertUsable(certPath, keyPath) {
+			return certPath, keyPath, nil
+		}
+		log.Printf("[Phosphor] Development certificate at %s is invalid or about to expire, regenerating", certPath)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", "", fmt.Errorf("failed to create cert dir: %w", err)
+	}
+
+	certPEM, keyPEM, err := generateSelfSignedCert()
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write certificate: %w", err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return "", "", fmt.Errorf("failed to write private key: %w", err)
+	}
+
+	log.Printf("[Phosphor] Generated self-signed development certificate at %s", certPath)
+	return certPath, keyPath, nil
+}
+
+// generateSelfSignedCert creates a PEM-encoded certificate and key valid for
+// localhost, suitable for pointing TLS-only SDK exporters at Phosphor.
+func generateSelfSignedCert() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial: %w", err)
+	}
+
+	hostnames := []string{"localhost"}
+	if h, err := os.Hostname(); err == nil && h != "" && h != "localhost" {
+		hostnames = append(hostnames, h)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Phosphor"}, CommonName: "Phosphor Dev Certificate"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              hostnames,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// fileExists reports whether path exists and is a regular file.
+// devCertUsable reports whether the stored development certificate loads with
+// its key and stays valid for at least devCertRenewBefore.
+func devCertUsable(certPath, keyPath string) bool {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	return time.Now().Add(devCertRenewBefore).Before(cert.NotAfter)
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
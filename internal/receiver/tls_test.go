@@ -0,0 +1,305 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBuildTLSConfigDisabled(t *testing.T) {
+	cfg, err := buildTLSConfig(TLSConfig{})
+	if err != nil {
+		t.Fatalf("buildTLSConfig() error = %v", err)
+	}
+	if cfg != nil {
+		t.Error("buildTLSConfig() returned config for disabled TLS")
+	}
+}
+
+func TestBuildTLSConfigSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := buildTLSConfig(TLSConfig{Enabled: true, SelfSigned: true, CertDir: dir})
+	if err != nil {
+		t.Fatalf("buildTLSConfig() error = %v", err)
+	}
+	if len(cfg.Certificates) != 1 {
+		t.Fatalf("Certificates = %d, want 1", len(cfg.Certificates))
+	}
+
+	first, err := os.ReadFile(filepath.Join(dir, devCertFile))
+	if err != nil {
+		t.Fatalf("certificate not written: %v", err)
+	}
+
+	// A second start must reuse the existing certificate rather than regenerate it.
+	if _, err := buildTLSConfig(TLSConfig{Enabled: true, SelfSigned: true, CertDir: dir}); err != nil {
+		t.Fatalf("buildTLSConfig() second run error = %v", err)
+	}
+	second, _ := os.ReadFile(filepath.Join(dir, devCertFile))
+	if !bytes.Equal(first, second) {
+		t.Error("self-signed certificate was regenerated on second run")
+	}
+}
+
+func TestBuildTLSConfigClientCA(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath, err := ensureSelfSignedCert(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      TLSConfig
+		wantAuth tls.ClientAuthType
+		wantErr  bool
+	}{
+		{"optional client cert", TLSConfig{Enabled: true, CertFile: certPath, KeyFile: keyPath, ClientCAFile: certPath}, tls.VerifyClientCertIfGiven, false},
+		{"required client cert", TLSConfig{Enabled: true, CertFile: certPath, KeyFile: keyPath, ClientCAFile: certPath, RequireClientCert: true}, tls.RequireAndVerifyClientCert, false},
+		{"require without CA", TLSConfig{Enabled: true, CertFile: certPath, KeyFile: keyPath, RequireClientCert: true}, 0, true},
+		{"no certificate", TLSConfig{Enabled: true}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := buildTLSConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.ClientAuth != tt.wantAuth {
+				t.Errorf("ClientAuth = %v, want %v", cfg.ClientAuth, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestEnsureSelfSignedCertRenews(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, certPath, keyPath string)
+	}{
+		{"about to expire", func(t *testing.T, certPath, keyPath string) {
+			// The client certificate is valid for one more hour.
+			cert, key := writeClientCert(t, filepath.Dir(certPath))
+			if err := os.Rename(cert, certPath); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(key, keyPath); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"corrupt key", func(t *testing.T, certPath, keyPath string) {
+			if _, _, err := ensureSelfSignedCert(filepath.Dir(certPath)); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(keyPath, []byte("not a key"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, filepath.Join(dir, devCertFile), filepath.Join(dir, devKeyFile))
+
+			certPath, keyPath, err := ensureSelfSignedCert(dir)
+			if err != nil {
+				t.Fatalf("ensureSelfSignedCert() error = %v", err)
+			}
+			pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+			if err != nil {
+				t.Fatalf("regenerated pair does not load: %v", err)
+			}
+			cert, err := x509.ParseCertificate(pair.Certificate[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if until := time.Until(cert.NotAfter); until < devCertRenewBefore {
+				t.Errorf("certificate expires in %v, want a renewed certificate", until)
+			}
+		})
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and key to dir. The
+// certificate is its own CA, so it also serves as the receiver's client CA.
+func writeClientCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "phosphor-test-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath, keyPath := filepath.Join(dir, "client-cert.pem"), filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+// startTLSReceiver starts a receiver on loopback TCP with a self-signed
+// certificate and returns it with a client config that trusts the certificate.
+func startTLSReceiver(t *testing.T, settings TLSConfig) (*OTLPReceiver, *tls.Config) {
+	t.Helper()
+	settings.Enabled = true
+	settings.SelfSigned = true
+	settings.CertDir = t.TempDir()
+
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"127.0.0.1:0"}
+	config.HTTPListenAddresses = []string{"127.0.0.1:0"}
+	config.QueryAddress = "127.0.0.1:0"
+	config.TLS = settings
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(r.Stop)
+
+	serverCert, err := os.ReadFile(filepath.Join(settings.CertDir, devCertFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(serverCert)
+	return r, &tls.Config{RootCAs: roots, ServerName: "localhost"}
+}
+
+// exportOverTLS sends one trace export over gRPC and one over HTTPS and
+// returns their errors.
+func exportOverTLS(t *testing.T, r *OTLPReceiver, client *tls.Config) (error, error) {
+	t.Helper()
+	conn, err := grpc.NewClient(r.Addresses()[0], grpc.WithTransportCredentials(credentials.NewTLS(client)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, grpcErr := coltracepb.NewTraceServiceClient(conn).Export(ctx, testTraceRequest())
+
+	body, err := proto.Marshal(testTraceRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: client}, Timeout: 5 * time.Second}
+	resp, httpErr := httpClient.Post("https://"+r.HTTPAddresses()[0]+"/v1/traces", contentTypeProtobuf, bytes.NewReader(body))
+	if httpErr == nil {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			httpErr = fmt.Errorf("status %d", resp.StatusCode)
+		}
+	}
+	return grpcErr, httpErr
+}
+
+func TestTLSExport(t *testing.T) {
+	r, client := startTLSReceiver(t, TLSConfig{})
+
+	grpcErr, httpErr := exportOverTLS(t, r, client)
+	if grpcErr != nil {
+		t.Errorf("gRPC Export() error = %v", grpcErr)
+	}
+	if httpErr != nil {
+		t.Errorf("HTTPS POST error = %v", httpErr)
+	}
+	if got := len(r.GetTraces()); got != 2 {
+		t.Errorf("GetTraces() returned %d spans, want 2", got)
+	}
+
+	// A plaintext client must not get through the TLS listener.
+	resp, err := http.Post("http://"+r.HTTPAddresses()[0]+"/v1/traces", contentTypeProtobuf, nil)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("plaintext POST succeeded against a TLS listener")
+		}
+	}
+
+	// The query API is served over the same TLS settings.
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: client}, Timeout: 5 * time.Second}
+	resp, err = httpClient.Get("https://" + r.QueryAddress() + "/healthz")
+	if err != nil {
+		t.Fatalf("HTTPS GET /healthz error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("HTTPS GET /healthz = %d, want 200", resp.StatusCode)
+	}
+	resp, err = http.Get("http://" + r.QueryAddress() + "/healthz")
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("plaintext GET /healthz succeeded against the TLS query API")
+		}
+	}
+}
+
+func TestMTLSRequiresClientCert(t *testing.T) {
+	certPath, keyPath := writeClientCert(t, t.TempDir())
+	r, client := startTLSReceiver(t, TLSConfig{ClientCAFile: certPath, RequireClientCert: true})
+
+	grpcErr, httpErr := exportOverTLS(t, r, client)
+	if grpcErr == nil {
+		t.Error("gRPC Export() without a client certificate succeeded, want it refused")
+	}
+	if httpErr == nil {
+		t.Error("HTTPS POST without a client certificate succeeded, want it refused")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Certificates = []tls.Certificate{cert}
+	grpcErr, httpErr = exportOverTLS(t, r, client)
+	if grpcErr != nil {
+		t.Errorf("gRPC Export() with a client certificate error = %v", grpcErr)
+	}
+	if httpErr != nil {
+		t.Errorf("HTTPS POST with a client certificate error = %v", httpErr)
+	}
+	if got := len(r.GetTraces()); got != 2 {
+		t.Errorf("GetTraces() returned %d spans, want 2", got)
+	}
+}
@@ -0,0 +1,171 @@
+package receiver
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Attribute keys used when mapping legacy tracing endpoints, following the
+// OpenTelemetry Collector's Zipkin and Jaeger translations.
+const (
+	attrServiceName = "service.name"
+	attrHostIP      = "net.host.ip"
+	attrHostPort    = "net.host.port"
+	attrPeerService = "peer.service"
+	attrPeerIP      = "net.peer.ip"
+	attrPeerPort    = "net.peer.port"
+)
+
+// Tags that carry OpenTelemetry span fields rather than attributes when spans
+// from OpenTelemetry SDKs are exported in a legacy format.
+const (
+	tagError          = "error"
+	tagStatusCode     = "otel.status_code"
+	tagStatusMessage  = "otel.status_description"
+	tagScopeName      = "otel.scope.name"
+	tagScopeVersion   = "otel.scope.version"
+	tagLibraryName    = "otel.library.name"
+	tagLibraryVersion = "otel.library.version"
+)
+
+// stringKV builds a string-valued OTLP attribute.
+func stringKV(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+// intKV builds an int-valued OTLP attribute.
+func intKV(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}}
+}
+
+// anyValue converts a decoded JSON or msgpack value to an OTLP value.
+func anyValue(v interface{}) *commonpb.AnyValue {
+	switch v := v.(type) {
+	case nil:
+		return &commonpb.AnyValue{}
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int8:
+		return intValue(int64(v))
+	case int16:
+		return intValue(int64(v))
+	case int32:
+		return intValue(int64(v))
+	case int64:
+		return intValue(v)
+	case int:
+		return intValue(int64(v))
+	case uint8:
+		return intValue(int64(v))
+	case uint16:
+		return intValue(int64(v))
+	case uint32:
+		return intValue(int64(v))
+	case uint64:
+		return intValue(int64(v))
+	case float32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: float64(v)}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case []byte:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: v}}
+	case []interface{}:
+		values := make([]*commonpb.AnyValue, len(v))
+		for i, elem := range v {
+			values[i] = anyValue(elem)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		kvs := &commonpb.KeyValueList{}
+		for _, k := range keys {
+			kvs.Values = append(kvs.Values, &commonpb.KeyValue{Key: k, Value: anyValue(v[k])})
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: kvs}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
+
+func intValue(i int64) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}}
+}
+
+// severityFromText maps a log level name, as written by common logging
+// libraries, to an OTLP severity number.
+func severityFromText(level string) logspb.SeverityNumber {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "trace":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_TRACE
+	case "debug", "dbug":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case "info", "information", "informational":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case "notice":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO2
+	case "warn", "warning":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case "error", "err", "eror":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case "crit", "critical":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR2
+	case "fatal", "panic", "alert", "emerg", "emergency":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+	}
+}
+
+// otelScope returns the instrumentation scope name and version recorded in
+// tags, accepting both current and legacy OpenTelemetry tag names.
+func otelScope(tag func(key string) string) [2]string {
+	name := tag(tagScopeName)
+	if name == "" {
+		name = tag(tagLibraryName)
+	}
+	version := tag(tagScopeVersion)
+	if version == "" {
+		version = tag(tagLibraryVersion)
+	}
+	return [2]string{name, version}
+}
+
+// statusCodeFromTag maps an otel.status_code tag value to an OTLP status code.
+func statusCodeFromTag(value string) tracepb.Status_StatusCode {
+	switch strings.ToUpper(value) {
+	case "ERROR":
+		return tracepb.Status_STATUS_CODE_ERROR
+	case "OK":
+		return tracepb.Status_STATUS_CODE_OK
+	default:
+		return tracepb.Status_STATUS_CODE_UNSET
+	}
+}
+
+// traceIDBytes encodes a 64- or 128-bit trace ID as 16 bytes; 64-bit IDs
+// (high == 0) are left-padded with zeros.
+func traceIDBytes(high, low uint64) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[:8], high)
+	binary.BigEndian.PutUint64(b[8:], low)
+	return b
+}
+
+// spanIDBytes encodes a span ID as 8 bytes.
+func spanIDBytes(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
@@ -0,0 +1,490 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	colprofilespb "go.opentelemetry.io/proto/otlp/collector/profiles/v1development"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Upstream protocols, named as in OTEL_EXPORTER_OTLP_PROTOCOL.
+const (
+	UpstreamGRPC = "grpc"
+	UpstreamHTTP = "http/protobuf"
+)
+
+// maxUpstreamBackoff caps the delay between retries, including delays the
+// upstream asks for.
+const maxUpstreamBackoff = 30 * time.Second
+
+// UpstreamConfig configures forwarding a copy of every export to other OTLP
+// endpoints, so Phosphor can sit inline in front of a real backend.
+type UpstreamConfig struct {
+	Endpoints  []UpstreamEndpoint `yaml:"endpoints"`   // Endpoints to forward to; forwarding is disabled when empty
+	QueueSize  int                `yaml:"queue_size"`  // Requests buffered per endpoint; the oldest are dropped when full (default: 1000)
+	MaxRetries int                `yaml:"max_retries"` // Retries after a retryable failure (default: 5; negative disables retries)
+	Backoff    time.Duration      `yaml:"backoff"`     // Delay before the first retry, doubling up to 30s (default: 500ms)
+	Timeout    time.Duration      `yaml:"timeout"`     // Per-attempt deadline (default: 10s)
+}
+
+// UpstreamEndpoint is an OTLP endpoint that receives forwarded exports.
+type UpstreamEndpoint struct {
+	Endpoint           string            `yaml:"endpoint"`             // host:port or unix:///path for gRPC; base URL such as http://collector:4318 for HTTP
+	Protocol           string            `yaml:"protocol"`             // UpstreamGRPC (default) or UpstreamHTTP
+	TLS                bool              `yaml:"tls"`                  // Use TLS for gRPC; HTTP follows the URL scheme
+	InsecureSkipVerify bool              `yaml:"insecure_skip_verify"` // Skip upstream certificate verification
+	Headers            map[string]string `yaml:"headers"`              // Sent with every request, e.g. authorization
+}
+
+// UpstreamStats reports the health of an upstream endpoint.
+type UpstreamStats struct {
+	Endpoint      string    `json:"endpoint"`
+	Protocol      string    `json:"protocol"`
+	Health        string    `json:"health"` // up, down, unknown
+	Queued        int       `json:"queued"`
+	QueueCapacity int       `json:"queueCapacity"`
+	Forwarded     uint64    `json:"forwarded"` // Requests accepted upstream
+	Retries       uint64    `json:"retries"`
+	Failed        uint64    `json:"failed"`  // Requests abandoned after a permanent error or exhausted retries
+	Dropped       uint64    `json:"dropped"` // Requests discarded because the queue was full
+	LastSuccess   time.Time `json:"lastSuccess"`
+	LastLatencyMs float64   `json:"lastLatencyMs"`
+	LastError     string    `json:"lastError,omitempty"`
+}
+
+// retryableError marks an upstream failure worth retrying, with the delay
+// the upstream asked for, if any.
+type retryableError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// upstreamExporter sends export requests to one endpoint.
+type upstreamExporter interface {
+	export(ctx context.Context, req proto.Message) error
+	close()
+}
+
+// forwarder tees export requests to every configured upstream.
+type forwarder struct {
+	upstreams []*upstream
+}
+
+// newForwarder validates the configuration and connects to each endpoint.
+// gRPC connections are established lazily, so an unreachable upstream does
+// not prevent the receiver from starting.
+func newForwarder(config UpstreamConfig) (*forwarder, error) {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+	switch {
+	case config.MaxRetries == 0:
+		config.MaxRetries = 5
+	case config.MaxRetries < 0:
+		config.MaxRetries = 0
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 500 * time.Millisecond
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	f := &forwarder{}
+	for _, endpoint := range config.Endpoints {
+		if endpoint.Protocol == "" {
+			endpoint.Protocol = UpstreamGRPC
+		}
+		var (
+			exporter upstreamExporter
+			err      error
+		)
+		switch endpoint.Protocol {
+		case UpstreamGRPC:
+			exporter, err = newGRPCUpstream(endpoint)
+		case UpstreamHTTP:
+			exporter, err = newHTTPUpstream(endpoint)
+		default:
+			err = fmt.Errorf("unsupported protocol %q", endpoint.Protocol)
+		}
+		if err != nil {
+			f.close()
+			return nil, fmt.Errorf("invalid upstream %q: %w", endpoint.Endpoint, err)
+		}
+		f.upstreams = append(f.upstreams, newUpstream(endpoint, config, exporter))
+	}
+	return f, nil
+}
+
+// start launches a sender per upstream.
+func (f *forwarder) start() {
+	for _, u := range f.upstreams {
+		log.Printf("[Phosphor] Forwarding exports to %s (%s)", u.stats.Endpoint, u.stats.Protocol)
+		u.start()
+	}
+}
+
+// stop flushes what it can and closes every upstream.
+func (f *forwarder) stop() {
+	for _, u := range f.upstreams {
+		u.stop()
+	}
+}
+
+// close releases connections of upstreams that were never started.
+func (f *forwarder) close() {
+	for _, u := range f.upstreams {
+		u.exporter.close()
+	}
+}
+
+// forward queues req for every upstream without blocking.
+func (f *forwarder) forward(req proto.Message) {
+	for _, u := range f.upstreams {
+		u.enqueue(req)
+	}
+}
+
+// stats returns a snapshot of each upstream's health.
+func (f *forwarder) stats() []UpstreamStats {
+	result := make([]UpstreamStats, 0, len(f.upstreams))
+	for _, u := range f.upstreams {
+		result = append(result, u.snapshot())
+	}
+	return result
+}
+
+// upstream delivers queued requests to one endpoint, in order, with retries.
+type upstream struct {
+	exporter upstreamExporter
+	config   UpstreamConfig
+	queue    chan proto.Message
+
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	mu    sync.Mutex
+	stats UpstreamStats
+}
+
+func newUpstream(endpoint UpstreamEndpoint, config UpstreamConfig, exporter upstreamExporter) *upstream {
+	return &upstream{
+		exporter: exporter,
+		config:   config,
+		queue:    make(chan proto.Message, config.QueueSize),
+		done:     make(chan struct{}),
+		stats: UpstreamStats{
+			Endpoint:      endpoint.Endpoint,
+			Protocol:      endpoint.Protocol,
+			Health:        scrapeHealthUnknown,
+			QueueCapacity: config.QueueSize,
+		},
+	}
+}
+
+// enqueue adds req to the queue, discarding the oldest request when full so
+// the most recent telemetry keeps flowing.
+func (u *upstream) enqueue(req proto.Message) {
+	for {
+		select {
+		case u.queue <- req:
+			return
+		default:
+		}
+		select {
+		case <-u.queue:
+			u.mu.Lock()
+			u.stats.Dropped++
+			u.mu.Unlock()
+		default:
+		}
+	}
+}
+
+func (u *upstream) start() {
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		for {
+			select {
+			case <-u.done:
+				return
+			case req := <-u.queue:
+				u.send(req)
+			}
+		}
+	}()
+}
+
+// stop ends retries and makes one last attempt to deliver the requests still
+// queued, within a single attempt timeout.
+func (u *upstream) stop() {
+	close(u.done)
+	u.wg.Wait()
+	defer u.exporter.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), u.config.Timeout)
+	defer cancel()
+	for {
+		select {
+		case req := <-u.queue:
+			if ctx.Err() != nil {
+				u.mu.Lock()
+				u.stats.Dropped++
+				u.mu.Unlock()
+				continue
+			}
+			start := time.Now()
+			err := u.exporter.export(ctx, req)
+			u.record(err, time.Since(start), err != nil)
+		default:
+			return
+		}
+	}
+}
+
+// send delivers req, retrying retryable failures with exponential backoff.
+func (u *upstream) send(req proto.Message) {
+	backoff := u.config.Backoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), u.config.Timeout)
+		start := time.Now()
+		err := u.exporter.export(ctx, req)
+		cancel()
+
+		var retry *retryableError
+		final := err == nil || !errors.As(err, &retry) || attempt >= u.config.MaxRetries
+		u.record(err, time.Since(start), final)
+		if final {
+			if err != nil {
+				log.Printf("[Phosphor] Failed to forward to %s: %v", u.stats.Endpoint, err)
+			}
+			return
+		}
+
+		select {
+		case <-u.done:
+			// Leave the request for stop's final flush.
+			u.enqueue(req)
+			return
+		case <-time.After(nextRetryDelay(backoff, retry)):
+		}
+		backoff = min(backoff*2, maxUpstreamBackoff)
+	}
+}
+
+// nextRetryDelay returns how long to wait before the next attempt: the delay the
+// upstream asked for, if any, otherwise the current backoff. Both are capped
+// at maxUpstreamBackoff so a long Retry-After cannot stall the sender while
+// its queue overflows.
+func nextRetryDelay(backoff time.Duration, retry *retryableError) time.Duration {
+	if retry.delay > 0 {
+		return min(retry.delay, maxUpstreamBackoff)
+	}
+	return backoff
+}
+
+// record updates health after an attempt. final reports whether the request
+// is finished with, either delivered or abandoned.
+func (u *upstream) record(err error, latency time.Duration, final bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.stats.LastLatencyMs = float64(latency) / float64(time.Millisecond)
+	switch {
+	case err == nil:
+		u.stats.Health = scrapeHealthUp
+		u.stats.Forwarded++
+		u.stats.LastSuccess = time.Now()
+		u.stats.LastError = ""
+	case final:
+		u.stats.Health = scrapeHealthDown
+		u.stats.Failed++
+		u.stats.LastError = err.Error()
+	default:
+		u.stats.Health = scrapeHealthDown
+		u.stats.Retries++
+		u.stats.LastError = err.Error()
+	}
+}
+
+func (u *upstream) snapshot() UpstreamStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	stats := u.stats
+	stats.Queued = len(u.queue)
+	return stats
+}
+
+// grpcUpstream exports over OTLP/gRPC.
+type grpcUpstream struct {
+	conn    *grpc.ClientConn
+	headers metadata.MD
+}
+
+func newGRPCUpstream(endpoint UpstreamEndpoint) (*grpcUpstream, error) {
+	creds := insecure.NewCredentials()
+	if endpoint.TLS {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: endpoint.InsecureSkipVerify})
+	}
+	conn, err := grpc.NewClient(endpoint.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcUpstream{conn: conn, headers: metadata.New(endpoint.Headers)}, nil
+}
+
+func (u *grpcUpstream) export(ctx context.Context, req proto.Message) error {
+	ctx = metadata.NewOutgoingContext(ctx, u.headers)
+
+	var err error
+	switch msg := req.(type) {
+	case *coltracepb.ExportTraceServiceRequest:
+		_, err = coltracepb.NewTraceServiceClient(u.conn).Export(ctx, msg)
+	case *colmetricspb.ExportMetricsServiceRequest:
+		_, err = colmetricspb.NewMetricsServiceClient(u.conn).Export(ctx, msg)
+	case *collogspb.ExportLogsServiceRequest:
+		_, err = collogspb.NewLogsServiceClient(u.conn).Export(ctx, msg)
+	case *colprofilespb.ExportProfilesServiceRequest:
+		_, err = colprofilespb.NewProfilesServiceClient(u.conn).Export(ctx, msg)
+	default:
+		return fmt.Errorf("cannot forward %T", req)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Retryable codes per the OTLP specification.
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.Canceled, codes.DeadlineExceeded, codes.Aborted, codes.OutOfRange,
+		codes.Unavailable, codes.DataLoss, codes.ResourceExhausted:
+		delay, _ := retryDelay(st)
+		return &retryableError{err: err, delay: delay}
+	}
+	return err
+}
+
+func (u *grpcUpstream) close() {
+	u.conn.Close()
+}
+
+// httpUpstream exports over OTLP/HTTP with protobuf bodies.
+type httpUpstream struct {
+	client  *http.Client
+	baseURL string
+	headers map[string]string
+}
+
+func newHTTPUpstream(endpoint UpstreamEndpoint) (*httpUpstream, error) {
+	u, err := url.Parse(endpoint.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("want an http:// or https:// base URL")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if endpoint.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &httpUpstream{
+		client:  &http.Client{Transport: transport},
+		baseURL: strings.TrimSuffix(endpoint.Endpoint, "/"),
+		headers: endpoint.Headers,
+	}, nil
+}
+
+func (u *httpUpstream) export(ctx context.Context, req proto.Message) error {
+	var path string
+	switch req.(type) {
+	case *coltracepb.ExportTraceServiceRequest:
+		path = "/v1/traces"
+	case *colmetricspb.ExportMetricsServiceRequest:
+		path = "/v1/metrics"
+	case *collogspb.ExportLogsServiceRequest:
+		path = "/v1/logs"
+	case *colprofilespb.ExportProfilesServiceRequest:
+		path = profilesPath
+	default:
+		return fmt.Errorf("cannot forward %T", req)
+	}
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", contentTypeProtobuf)
+	for k, v := range u.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := u.client.Do(httpReq)
+	if err != nil {
+		return &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("upstream returned %s", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		var delay time.Duration
+		if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && secs > 0 {
+			delay = time.Duration(secs) * time.Second
+		}
+		return &retryableError{err: err, delay: delay}
+	}
+	return err
+}
+
+func (u *httpUpstream) close() {
+	u.client.CloseIdleConnections()
+}
+
+// forwardUpstream queues a copy of an export request for every upstream.
+func (r *OTLPReceiver) forwardUpstream(req proto.Message) {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.upstreams != nil {
+		r.upstreams.forward(req)
+	}
+}
+
+// GetUpstreams returns the health of each upstream forwarding endpoint.
+func (r *OTLPReceiver) GetUpstreams() []UpstreamStats {
+	r.runMu.RLock()
+	defer r.runMu.RUnlock()
+	if r.upstreams == nil {
+		return []UpstreamStats{}
+	}
+	return r.upstreams.stats()
+}
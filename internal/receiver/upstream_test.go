@@ -0,0 +1,207 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestForwardToUpstreams(t *testing.T) {
+	dir := t.TempDir()
+
+	// A second receiver stands in for the real gRPC backend.
+	backendConfig := DefaultConfig()
+	backendSock := filepath.Join(dir, "backend.sock")
+	backendConfig.ListenAddresses = []string{"unix://" + backendSock}
+	backendConfig.HTTPListenAddresses = []string{"unix://" + filepath.Join(dir, "backend-http.sock")}
+	backend := NewOTLPReceiver(backendConfig)
+	if err := backend.Start(); err != nil {
+		t.Fatalf("backend Start() error = %v", err)
+	}
+	defer backend.Stop()
+
+	// The HTTP upstream is unavailable once before accepting.
+	var attempts atomic.Int32
+	var forwarded atomic.Value
+	httpBackend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		msg := &coltracepb.ExportTraceServiceRequest{}
+		if req.URL.Path != "/v1/traces" || req.Header.Get("Authorization") != "Bearer secret" || proto.Unmarshal(body, msg) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		forwarded.Store(msg)
+	}))
+	defer httpBackend.Close()
+
+	config := DefaultConfig()
+	config.ListenAddresses = []string{"unix://" + filepath.Join(dir, "otlp.sock")}
+	config.HTTPListenAddresses = []string{"unix://" + filepath.Join(dir, "http.sock")}
+	config.Upstream = UpstreamConfig{
+		Endpoints: []UpstreamEndpoint{
+			{Endpoint: "unix://" + backendSock},
+			{Endpoint: httpBackend.URL, Protocol: UpstreamHTTP, Headers: map[string]string{"Authorization": "Bearer secret"}},
+		},
+		Backoff: 10 * time.Millisecond,
+	}
+	r := NewOTLPReceiver(config)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	req := testTraceRequest()
+	if _, err := r.traceService.Export(context.Background(), req); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for (len(backend.GetTraces()) == 0 || forwarded.Load() == nil) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(backend.GetTraces()); got != 1 {
+		t.Fatalf("backend received %d spans, want 1", got)
+	}
+	if msg, _ := forwarded.Load().(*coltracepb.ExportTraceServiceRequest); !proto.Equal(msg, req) {
+		t.Errorf("HTTP upstream received %v, want the original request", msg)
+	}
+
+	stats := r.GetUpstreams()
+	if len(stats) != 2 {
+		t.Fatalf("GetUpstreams() returned %d, want 2", len(stats))
+	}
+	if stats[0].Protocol != UpstreamGRPC || stats[0].Health != scrapeHealthUp || stats[0].Forwarded != 1 {
+		t.Errorf("gRPC upstream = %+v, want up with 1 forwarded", stats[0])
+	}
+	if stats[1].Health != scrapeHealthUp || stats[1].Forwarded != 1 || stats[1].Retries != 1 {
+		t.Errorf("HTTP upstream = %+v, want up after 1 retry", stats[1])
+	}
+}
+
+func TestUpstreamPermanentFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	f, err := newForwarder(UpstreamConfig{Endpoints: []UpstreamEndpoint{{Endpoint: server.URL, Protocol: UpstreamHTTP}}})
+	if err != nil {
+		t.Fatalf("newForwarder() error = %v", err)
+	}
+	u := f.upstreams[0]
+	u.send(testTraceRequest())
+
+	stats := u.snapshot()
+	if stats.Failed != 1 || stats.Retries != 0 || stats.Health != scrapeHealthDown {
+		t.Errorf("stats = %+v, want 1 failure without retries", stats)
+	}
+	if stats.LastError != "upstream returned 400 Bad Request" {
+		t.Errorf("LastError = %q", stats.LastError)
+	}
+	f.close()
+}
+
+func TestUpstreamRetriesDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	f, err := newForwarder(UpstreamConfig{
+		Endpoints:  []UpstreamEndpoint{{Endpoint: server.URL, Protocol: UpstreamHTTP}},
+		MaxRetries: -1,
+	})
+	if err != nil {
+		t.Fatalf("newForwarder() error = %v", err)
+	}
+	defer f.close()
+	u := f.upstreams[0]
+	u.send(testTraceRequest())
+
+	if stats := u.snapshot(); stats.Failed != 1 || stats.Retries != 0 {
+		t.Errorf("stats = %+v, want 1 failure without retries", stats)
+	}
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		hint time.Duration
+		want time.Duration
+	}{
+		{"no hint", 0, time.Second},
+		{"short hint", 3 * time.Second, 3 * time.Second},
+		{"day-long hint", 24 * time.Hour, maxUpstreamBackoff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry := &retryableError{err: errors.New("unavailable"), delay: tt.hint}
+			if got := nextRetryDelay(time.Second, retry); got != tt.want {
+				t.Errorf("nextRetryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// blockingExporter holds every export until released.
+type blockingExporter struct {
+	release chan struct{}
+	sent    atomic.Int32
+}
+
+func (e *blockingExporter) export(ctx context.Context, req proto.Message) error {
+	<-e.release
+	e.sent.Add(1)
+	return nil
+}
+
+func (e *blockingExporter) close() {}
+
+func TestUpstreamQueueDropsOldest(t *testing.T) {
+	exporter := &blockingExporter{release: make(chan struct{})}
+	u := newUpstream(UpstreamEndpoint{Endpoint: "test"}, UpstreamConfig{QueueSize: 2, Timeout: time.Second}, exporter)
+
+	for i := 0; i < 5; i++ {
+		u.enqueue(testTraceRequest())
+	}
+	stats := u.snapshot()
+	if stats.Queued != 2 || stats.Dropped != 3 {
+		t.Errorf("Queued = %d, Dropped = %d; want 2, 3", stats.Queued, stats.Dropped)
+	}
+
+	// Stopping flushes what is still queued.
+	close(exporter.release)
+	u.start()
+	u.stop()
+	if got := exporter.sent.Load(); got != 2 {
+		t.Errorf("sent %d requests, want 2", got)
+	}
+	if got := u.snapshot().Forwarded; got != 2 {
+		t.Errorf("Forwarded = %d, want 2", got)
+	}
+}
+
+func TestNewForwarderRejectsInvalidEndpoint(t *testing.T) {
+	tests := []UpstreamEndpoint{
+		{Endpoint: "collector:4318", Protocol: UpstreamHTTP},
+		{Endpoint: "collector:4317", Protocol: "thrift"},
+	}
+	for _, endpoint := range tests {
+		if _, err := newForwarder(UpstreamConfig{Endpoints: []UpstreamEndpoint{endpoint}}); err == nil {
+			t.Errorf("newForwarder(%+v) error = nil, want error", endpoint)
+		}
+	}
+}
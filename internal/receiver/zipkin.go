@@ -0,0 +1,238 @@
+package receiver
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/proto/zipkin_proto3"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/status"
+)
+
+// zipkinSpansPath is the Zipkin v2 span ingestion endpoint.
+const zipkinSpansPath = "/api/v2/spans"
+
+// handleZipkinSpans serves POST /api/v2/spans with a JSON or protobuf list of
+// Zipkin v2 spans. Spans are translated to OTLP and passed to the trace
+// handler so they share validation, buffering and client tracking.
+func (h *httpHandler) handleZipkinSpans(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Zipkin reporters commonly omit the content type for JSON.
+	mediaType := contentTypeJSON
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		parsed, _, err := mime.ParseMediaType(ct)
+		if err != nil || (parsed != contentTypeProtobuf && parsed != contentTypeJSON) {
+			http.Error(w, fmt.Sprintf("unsupported content type %q", ct), http.StatusUnsupportedMediaType)
+			return
+		}
+		mediaType = parsed
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	spans, err := decodeZipkinSpans(body, mediaType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.receiver.traceService.Export(exportContext(req), zipkinToOTLP(spans))
+	if err != nil {
+		st := status.Convert(err)
+		setRetryAfter(w, st)
+		http.Error(w, st.Message(), httpStatusFromCode(st.Code()))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// decodeZipkinSpans parses a Zipkin v2 span list in JSON or proto3 encoding.
+func decodeZipkinSpans(body []byte, contentType string) ([]*zipkinmodel.SpanModel, error) {
+	if contentType == contentTypeProtobuf {
+		spans, err := zipkin_proto3.ParseSpans(body, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zipkin protobuf: %w", err)
+		}
+		return spans, nil
+	}
+
+	var spans []*zipkinmodel.SpanModel
+	if err := json.Unmarshal(body, &spans); err != nil {
+		return nil, fmt.Errorf("invalid zipkin JSON: %w", err)
+	}
+	return spans, nil
+}
+
+// zipkinToOTLP translates Zipkin spans into an OTLP export request, grouping
+// spans that share a local endpoint under one resource.
+func zipkinToOTLP(spans []*zipkinmodel.SpanModel) *coltracepb.ExportTraceServiceRequest {
+	req := &coltracepb.ExportTraceServiceRequest{}
+	resources := make(map[string]*tracepb.ResourceSpans)
+	scopes := make(map[*tracepb.ResourceSpans]map[[2]string]*tracepb.ScopeSpans)
+
+	for _, zs := range spans {
+		if zs == nil {
+			continue
+		}
+
+		key := endpointKey(zs.LocalEndpoint)
+		rs, ok := resources[key]
+		if !ok {
+			rs = &tracepb.ResourceSpans{Resource: zipkinResource(zs.LocalEndpoint)}
+			resources[key] = rs
+			scopes[rs] = make(map[[2]string]*tracepb.ScopeSpans)
+			req.ResourceSpans = append(req.ResourceSpans, rs)
+		}
+
+		scope := zipkinScope(zs.Tags)
+		ss, ok := scopes[rs][scope]
+		if !ok {
+			ss = &tracepb.ScopeSpans{Scope: &commonpb.InstrumentationScope{Name: scope[0], Version: scope[1]}}
+			scopes[rs][scope] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+		ss.Spans = append(ss.Spans, zipkinSpanToOTLP(zs))
+	}
+	return req
+}
+
+// endpointKey identifies an endpoint for resource grouping.
+func endpointKey(ep *zipkinmodel.Endpoint) string {
+	if ep == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s|%s|%s|%d", ep.ServiceName, ep.IPv4, ep.IPv6, ep.Port)
+}
+
+// zipkinResource maps a span's local endpoint to resource attributes.
+func zipkinResource(ep *zipkinmodel.Endpoint) *resourcepb.Resource {
+	serviceName := "unknown"
+	if ep != nil && ep.ServiceName != "" {
+		serviceName = ep.ServiceName
+	}
+	attrs := []*commonpb.KeyValue{stringKV(attrServiceName, serviceName)}
+	if ep != nil {
+		if ip := endpointIP(ep); ip != "" {
+			attrs = append(attrs, stringKV(attrHostIP, ip))
+		}
+		if ep.Port != 0 {
+			attrs = append(attrs, intKV(attrHostPort, int64(ep.Port)))
+		}
+	}
+	return &resourcepb.Resource{Attributes: attrs}
+}
+
+// zipkinScope extracts the instrumentation scope name and version from tags.
+func zipkinScope(tags map[string]string) [2]string {
+	return otelScope(func(key string) string { return tags[key] })
+}
+
+// zipkinSpanToOTLP converts a single Zipkin span. The remote endpoint becomes
+// peer attributes and annotations become span events.
+func zipkinSpanToOTLP(zs *zipkinmodel.SpanModel) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId: traceIDBytes(zs.TraceID.High, zs.TraceID.Low),
+		SpanId:  spanIDBytes(uint64(zs.ID)),
+		Name:    zs.Name,
+		Kind:    zipkinKind(zs.Kind),
+	}
+	if !zs.Timestamp.IsZero() {
+		span.StartTimeUnixNano = uint64(zs.Timestamp.UnixNano())
+		span.EndTimeUnixNano = uint64(zs.Timestamp.Add(zs.Duration).UnixNano())
+	}
+	if zs.ParentID != nil {
+		span.ParentSpanId = spanIDBytes(uint64(*zs.ParentID))
+	}
+
+	// Sort tags so attribute order is stable across identical spans.
+	keys := make([]string, 0, len(zs.Tags))
+	for k := range zs.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	st := &tracepb.Status{}
+	for _, k := range keys {
+		v := zs.Tags[k]
+		switch k {
+		case tagStatusCode:
+			st.Code = statusCodeFromTag(v)
+		case tagStatusMessage:
+			st.Message = v
+		case tagScopeName, tagScopeVersion, tagLibraryName, tagLibraryVersion:
+		case tagError:
+			// Zipkin marks failed spans with an "error" tag whose value is the message.
+			st.Code = tracepb.Status_STATUS_CODE_ERROR
+			if st.Message == "" && v != "" && v != "true" {
+				st.Message = v
+			}
+			span.Attributes = append(span.Attributes, stringKV(k, v))
+		default:
+			span.Attributes = append(span.Attributes, stringKV(k, v))
+		}
+	}
+	span.Status = st
+
+	if ep := zs.RemoteEndpoint; ep != nil {
+		if ep.ServiceName != "" {
+			span.Attributes = append(span.Attributes, stringKV(attrPeerService, ep.ServiceName))
+		}
+		if ip := endpointIP(ep); ip != "" {
+			span.Attributes = append(span.Attributes, stringKV(attrPeerIP, ip))
+		}
+		if ep.Port != 0 {
+			span.Attributes = append(span.Attributes, intKV(attrPeerPort, int64(ep.Port)))
+		}
+	}
+
+	for _, a := range zs.Annotations {
+		span.Events = append(span.Events, &tracepb.Span_Event{
+			Name:         a.Value,
+			TimeUnixNano: uint64(a.Timestamp.UnixNano()),
+		})
+	}
+	return span
+}
+
+// zipkinKind maps a Zipkin span kind to its OTLP equivalent.
+func zipkinKind(kind zipkinmodel.Kind) tracepb.Span_SpanKind {
+	switch kind {
+	case zipkinmodel.Client:
+		return tracepb.Span_SPAN_KIND_CLIENT
+	case zipkinmodel.Server:
+		return tracepb.Span_SPAN_KIND_SERVER
+	case zipkinmodel.Producer:
+		return tracepb.Span_SPAN_KIND_PRODUCER
+	case zipkinmodel.Consumer:
+		return tracepb.Span_SPAN_KIND_CONSUMER
+	default:
+		return tracepb.Span_SPAN_KIND_INTERNAL
+	}
+}
+
+// endpointIP returns the endpoint's IPv4 address, falling back to IPv6.
+func endpointIP(ep *zipkinmodel.Endpoint) string {
+	if len(ep.IPv4) > 0 {
+		return ep.IPv4.String()
+	}
+	if len(ep.IPv6) > 0 {
+		return ep.IPv6.String()
+	}
+	return ""
+}
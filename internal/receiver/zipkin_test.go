@@ -0,0 +1,174 @@
+package receiver
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/proto/zipkin_proto3"
+	"github.com/phosphor-project/phosphor/pkg/models"
+)
+
+func attributeValue(attrs []models.Attribute, key string) interface{} {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+func TestZipkinJSONSpans(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	handler := newHTTPHandler(r)
+
+	body := `[{
+		"traceId": "463ac35c9f6413ad",
+		"parentId": "0020000000000001",
+		"id": "a2fb4a1d1a96d312",
+		"kind": "CLIENT",
+		"name": "get /api",
+		"timestamp": 1556604172355737,
+		"duration": 1431,
+		"localEndpoint": {"serviceName": "frontend", "ipv4": "192.168.99.1", "port": 3306},
+		"remoteEndpoint": {"serviceName": "backend", "ipv4": "172.19.0.2", "port": 9000},
+		"annotations": [{"timestamp": 1556604172355800, "value": "ws"}],
+		"tags": {"http.method": "GET", "error": "connection refused"}
+	}]`
+
+	req := httptest.NewRequest(http.MethodPost, zipkinSpansPath, bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+
+	traces := r.GetTraces()
+	if len(traces) != 1 {
+		t.Fatalf("GetTraces() returned %d spans, want 1", len(traces))
+	}
+	span := traces[0]
+
+	if span.TraceID != "0000000000000000463ac35c9f6413ad" {
+		t.Errorf("TraceID = %q, want 64-bit ID left-padded to 128 bits", span.TraceID)
+	}
+	if span.SpanID != "a2fb4a1d1a96d312" || span.ParentSpanID != "0020000000000001" {
+		t.Errorf("SpanID/ParentSpanID = %q/%q", span.SpanID, span.ParentSpanID)
+	}
+	if span.Kind != models.SpanKindClient {
+		t.Errorf("Kind = %q, want client", span.Kind)
+	}
+	if span.DurationMs != 1.431 {
+		t.Errorf("DurationMs = %f, want 1.431", span.DurationMs)
+	}
+	if span.StatusCode != models.StatusCodeError || span.StatusMessage != "connection refused" {
+		t.Errorf("status = %q %q, want error from tag", span.StatusCode, span.StatusMessage)
+	}
+
+	if span.Resource.ServiceName != "frontend" {
+		t.Errorf("ServiceName = %q, want 'frontend'", span.Resource.ServiceName)
+	}
+	if got := attributeValue(span.Resource.Attributes, attrHostIP); got != "192.168.99.1" {
+		t.Errorf("resource %s = %v, want 192.168.99.1", attrHostIP, got)
+	}
+
+	wantAttrs := map[string]interface{}{
+		"http.method":   "GET",
+		attrPeerService: "backend",
+		attrPeerIP:      "172.19.0.2",
+		attrPeerPort:    int64(9000),
+	}
+	for key, want := range wantAttrs {
+		if got := attributeValue(span.Attributes, key); got != want {
+			t.Errorf("attribute %s = %v, want %v", key, got, want)
+		}
+	}
+
+	if len(span.Events) != 1 || span.Events[0].Name != "ws" {
+		t.Fatalf("Events = %+v, want one 'ws' annotation", span.Events)
+	}
+	if span.Events[0].TimestampUnixNano != 1556604172355800000 {
+		t.Errorf("event timestamp = %d, want annotation time", span.Events[0].TimestampUnixNano)
+	}
+}
+
+func TestZipkinProtobufSpans(t *testing.T) {
+	r := NewOTLPReceiver(DefaultConfig())
+	handler := newHTTPHandler(r)
+
+	start := time.Unix(1556604172, 0)
+	body, err := zipkin_proto3.SpanSerializer{}.Serialize([]*zipkinmodel.SpanModel{{
+		SpanContext: zipkinmodel.SpanContext{
+			TraceID: zipkinmodel.TraceID{High: 1, Low: 2},
+			ID:      3,
+		},
+		Name:          "consume",
+		Kind:          zipkinmodel.Consumer,
+		Timestamp:     start,
+		Duration:      time.Second,
+		LocalEndpoint: &zipkinmodel.Endpoint{ServiceName: "worker", IPv4: net.ParseIP("10.0.0.1")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, zipkinSpansPath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentTypeProtobuf)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body.String())
+	}
+
+	traces := r.GetTraces()
+	if len(traces) != 1 {
+		t.Fatalf("GetTraces() returned %d spans, want 1", len(traces))
+	}
+	if traces[0].TraceID != "00000000000000010000000000000002" {
+		t.Errorf("TraceID = %q, want 128-bit ID", traces[0].TraceID)
+	}
+	if traces[0].Kind != models.SpanKindConsumer || traces[0].Resource.ServiceName != "worker" {
+		t.Errorf("span = %+v, want consumer span from worker", traces[0])
+	}
+	if traces[0].DurationMs != 1000 {
+		t.Errorf("DurationMs = %f, want 1000", traces[0].DurationMs)
+	}
+}
+
+func TestZipkinRejectsBadRequests(t *testing.T) {
+	handler := newHTTPHandler(NewOTLPReceiver(DefaultConfig()))
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"wrong method", http.MethodGet, "", "", http.StatusMethodNotAllowed},
+		{"unsupported content type", http.MethodPost, "application/x-thrift", "x", http.StatusUnsupportedMediaType},
+		{"malformed json", http.MethodPost, contentTypeJSON, "[{", http.StatusBadRequest},
+		{"missing span id", http.MethodPost, contentTypeJSON, `[{"traceId":"463ac35c9f6413ad"}]`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, zipkinSpansPath, bytes.NewBufferString(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
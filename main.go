@@ -3,9 +3,13 @@ package main
 
 import (
 	"embed"
+	"errors"
+	"flag"
 	"log"
+	"os"
 
 	"github.com/phosphor-project/phosphor/internal/bridge"
+	"github.com/phosphor-project/phosphor/internal/config"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -17,11 +21,20 @@ import (
 var assets embed.FS
 
 func main() {
+	// Load settings from the config file, PHOSPHOR_* variables and flags
+	settings, configPath, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal("[Phosphor] ", err)
+	}
+
 	// Create the application bridge
-	app := bridge.NewApp()
+	app := bridge.NewApp(settings, configPath)
 
 	// Configure and run the Wails application
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:     "Phosphor",
 		Width:     1400,
 		Height:    900,
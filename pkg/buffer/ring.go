@@ -12,11 +12,12 @@ import (
 type RingBuffer[T any] struct {
 	mu       sync.RWMutex
 	items    []T
-	head     int  // Points to the next write position
-	tail     int  // Points to the oldest item
-	count    int  // Current number of items
-	capacity int  // Maximum capacity
-	full     bool // Indicates if the buffer has wrapped around
+	head     int    // Points to the next write position
+	tail     int    // Points to the oldest item
+	count    int    // Current number of items
+	capacity int    // Maximum capacity
+	full     bool   // Indicates if the buffer has wrapped around
+	evicted  uint64 // Items overwritten since creation
 }
 
 // NewRingBuffer creates a new RingBuffer with the specified capacity.
@@ -43,6 +44,7 @@ func (rb *RingBuffer[T]) Push(item T) {
 	if rb.full {
 		// Move tail forward since we're overwriting
 		rb.tail = (rb.tail + 1) % rb.capacity
+		rb.evicted++
 	} else {
 		rb.count++
 		if rb.count == rb.capacity {
@@ -67,6 +69,7 @@ func (rb *RingBuffer[T]) PushBatch(items []T) {
 
 		if rb.full {
 			rb.tail = (rb.tail + 1) % rb.capacity
+			rb.evicted++
 		} else {
 			rb.count++
 			if rb.count == rb.capacity {
@@ -141,9 +144,55 @@ func (rb *RingBuffer[T]) Len() int {
 
 // Cap returns the maximum capacity of the buffer.
 func (rb *RingBuffer[T]) Cap() int {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
 	return rb.capacity
 }
 
+// Resize changes the buffer's capacity, keeping its items in order. When the
+// new capacity is smaller than the current count, the oldest items are
+// evicted and their number is returned. A capacity of 0 or less is ignored.
+func (rb *RingBuffer[T]) Resize(capacity int) int {
+	if capacity <= 0 {
+		return 0
+	}
+
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if capacity == rb.capacity {
+		return 0
+	}
+
+	keep := rb.count
+	if keep > capacity {
+		keep = capacity
+	}
+	dropped := rb.count - keep
+
+	items := make([]T, capacity)
+	for i := 0; i < keep; i++ {
+		items[i] = rb.items[(rb.tail+dropped+i)%rb.capacity]
+	}
+
+	rb.items = items
+	rb.capacity = capacity
+	rb.tail = 0
+	rb.head = keep % capacity
+	rb.count = keep
+	rb.full = keep == capacity
+	rb.evicted += uint64(dropped)
+	return dropped
+}
+
+// Evicted returns how many items have been overwritten by newer ones. Clear
+// does not reset it.
+func (rb *RingBuffer[T]) Evicted() uint64 {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+	return rb.evicted
+}
+
 // Clear removes all items from the buffer.
 func (rb *RingBuffer[T]) Clear() {
 	rb.mu.Lock()
@@ -194,6 +243,7 @@ type BufferStats struct {
 	Capacity int     `json:"capacity"`
 	Usage    float64 `json:"usage"` // Percentage 0.0-1.0
 	IsFull   bool    `json:"isFull"`
+	Evicted  uint64  `json:"evicted"`
 }
 
 func (rb *RingBuffer[T]) Stats() BufferStats {
@@ -205,5 +255,6 @@ func (rb *RingBuffer[T]) Stats() BufferStats {
 		Capacity: rb.capacity,
 		Usage:    float64(rb.count) / float64(rb.capacity),
 		IsFull:   rb.full,
+		Evicted:  rb.evicted,
 	}
 }
@@ -77,6 +77,14 @@ func TestPushOverflow(t *testing.T) {
 	if !rb.IsFull() {
 		t.Error("IsFull() = false, want true")
 	}
+	if got := rb.Evicted(); got != 2 {
+		t.Errorf("Evicted() = %d, want 2", got)
+	}
+
+	rb.PushBatch([]int{6, 7})
+	if got := rb.Stats().Evicted; got != 4 {
+		t.Errorf("Stats().Evicted = %d, want 4", got)
+	}
 }
 
 func TestGetLast(t *testing.T) {
@@ -185,6 +193,52 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name        string
+		capacity    int
+		want        []int
+		wantDropped int
+	}{
+		{"grow", 8, []int{3, 4, 5, 6, 7}, 0},
+		{"same size", 5, []int{3, 4, 5, 6, 7}, 0},
+		{"shrink", 3, []int{5, 6, 7}, 2},
+		{"invalid capacity", 0, []int{3, 4, 5, 6, 7}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Wrap around so the oldest item is not at index 0
+			rb := NewRingBuffer[int](5)
+			for i := 1; i <= 7; i++ {
+				rb.Push(i)
+			}
+
+			if dropped := rb.Resize(tt.capacity); dropped != tt.wantDropped {
+				t.Errorf("Resize() = %d, want %d", dropped, tt.wantDropped)
+			}
+			items := rb.GetAll()
+			if len(items) != len(tt.want) {
+				t.Fatalf("GetAll() = %v, want %v", items, tt.want)
+			}
+			for i := range tt.want {
+				if items[i] != tt.want[i] {
+					t.Errorf("items[%d] = %d, want %d", i, items[i], tt.want[i])
+				}
+			}
+
+			// The buffer keeps rotating at its new size
+			rb.Push(8)
+			if latest, _ := rb.GetLatest(); latest != 8 {
+				t.Errorf("GetLatest() = %d, want 8", latest)
+			}
+			if rb.Len() > rb.Cap() {
+				t.Errorf("Len() = %d exceeds Cap() = %d", rb.Len(), rb.Cap())
+			}
+		})
+	}
+}
+
 func TestForEach(t *testing.T) {
 	rb := NewRingBuffer[int](5)
 	for i := 1; i <= 5; i++ {
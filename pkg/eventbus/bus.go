@@ -0,0 +1,178 @@
+// Package eventbus provides an ordered, bounded publish/subscribe bus.
+// Each subscriber owns a fixed-size queue drained by a single goroutine, so
+// events are delivered in publish order and a slow subscriber can never block
+// publishers or other subscribers; it drops events instead.
+package eventbus
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultQueueSize is used when a non-positive queue size is requested.
+const DefaultQueueSize = 4096
+
+// envelope carries a published value with its publish time for lag tracking.
+type envelope[T any] struct {
+	value       T
+	publishedAt time.Time
+}
+
+// Bus fans out published values to subscribers in order.
+type Bus[T any] struct {
+	mu        sync.RWMutex
+	subs      map[uint64]*Subscription[T]
+	nextID    uint64
+	queueSize int
+	closed    bool
+}
+
+// Subscription is a handle to a registered subscriber.
+type Subscription[T any] struct {
+	id    uint64
+	name  string
+	bus   *Bus[T]
+	queue chan envelope[T]
+	done  chan struct{}
+	once  sync.Once
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+	lastLagNs atomic.Int64
+}
+
+// SubscriberStats reports delivery counters for a single subscriber.
+type SubscriberStats struct {
+	Name      string  `json:"name"`
+	Delivered uint64  `json:"delivered"`
+	Dropped   uint64  `json:"dropped"`
+	Pending   int     `json:"pending"`  // Events queued but not yet delivered
+	Capacity  int     `json:"capacity"` // Queue size
+	LagMs     float64 `json:"lagMs"`    // Publish-to-delivery delay of the last event
+}
+
+// New creates a Bus whose subscribers each buffer up to queueSize events.
+func New[T any](queueSize int) *Bus[T] {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	return &Bus[T]{
+		subs:      make(map[uint64]*Subscription[T]),
+		queueSize: queueSize,
+	}
+}
+
+// Subscribe registers fn to receive every subsequently published value.
+// fn is always called from the same goroutine, in publish order.
+func (b *Bus[T]) Subscribe(name string, fn func(T)) *Subscription[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &Subscription[T]{
+		id:    b.nextID,
+		name:  name,
+		bus:   b,
+		queue: make(chan envelope[T], b.queueSize),
+		done:  make(chan struct{}),
+	}
+	if b.closed {
+		close(sub.done)
+		return sub
+	}
+	b.subs[sub.id] = sub
+
+	go sub.run(fn)
+	return sub
+}
+
+// Publish enqueues v for every subscriber without blocking. Subscribers whose
+// queues are full drop the value and count it.
+func (b *Bus[T]) Publish(v T) {
+	env := envelope[T]{value: v, publishedAt: time.Now()}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subs {
+		select {
+		case sub.queue <- env:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Stats returns per-subscriber counters ordered by subscription time.
+func (b *Bus[T]) Stats() []SubscriberStats {
+	b.mu.RLock()
+	subs := make([]*Subscription[T], 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+
+	sort.Slice(subs, func(i, j int) bool { return subs[i].id < subs[j].id })
+
+	stats := make([]SubscriberStats, 0, len(subs))
+	for _, sub := range subs {
+		stats = append(stats, sub.Stats())
+	}
+	return stats
+}
+
+// Close unsubscribes all subscribers. Subsequent subscriptions are inert.
+func (b *Bus[T]) Close() {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = make(map[uint64]*Subscription[T])
+	b.closed = true
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.stop()
+	}
+}
+
+// run delivers queued values until the subscription is cancelled.
+func (s *Subscription[T]) run(fn func(T)) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case env := <-s.queue:
+			fn(env.value)
+			s.delivered.Add(1)
+			s.lastLagNs.Store(int64(time.Since(env.publishedAt)))
+		}
+	}
+}
+
+// Unsubscribe stops delivery to this subscriber. Queued values are discarded.
+// It is safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s.id)
+	s.bus.mu.Unlock()
+
+	s.stop()
+}
+
+// stop signals the delivery goroutine to exit.
+func (s *Subscription[T]) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Stats returns the subscriber's delivery counters.
+func (s *Subscription[T]) Stats() SubscriberStats {
+	return SubscriberStats{
+		Name:      s.name,
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+		Pending:   len(s.queue),
+		Capacity:  cap(s.queue),
+		LagMs:     float64(s.lastLagNs.Load()) / 1e6,
+	}
+}
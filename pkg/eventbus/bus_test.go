@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOrderedDelivery(t *testing.T) {
+	bus := New[int](1000)
+	defer bus.Close()
+
+	var mu sync.Mutex
+	var got []int
+	bus.Subscribe("collector", func(v int) {
+		mu.Lock()
+		got = append(got, v)
+		mu.Unlock()
+	})
+
+	for i := 0; i < 500; i++ {
+		bus.Publish(i)
+	}
+
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 500
+	})
+
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("got[%d] = %d, want %d (out of order)", i, v, i)
+		}
+	}
+}
+
+func TestSlowSubscriberDrops(t *testing.T) {
+	bus := New[int](2)
+	defer bus.Close()
+
+	block := make(chan struct{})
+	slow := bus.Subscribe("slow", func(int) { <-block })
+
+	for i := 0; i < 10; i++ {
+		bus.Publish(i)
+	}
+
+	// One value is held by the blocked callback, two fill the queue.
+	waitFor(t, func() bool { return slow.Stats().Dropped >= 7 })
+	close(block)
+
+	waitFor(t, func() bool { return slow.Stats().Delivered+slow.Stats().Dropped == 10 })
+	if stats := slow.Stats(); stats.Capacity != 2 {
+		t.Errorf("Capacity = %d, want 2", stats.Capacity)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := New[int](10)
+	defer bus.Close()
+
+	var mu sync.Mutex
+	count := 0
+	sub := bus.Subscribe("counter", func(int) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+
+	bus.Publish(1)
+	waitFor(t, func() bool { return sub.Stats().Delivered == 1 })
+
+	sub.Unsubscribe()
+	sub.Unsubscribe() // idempotent
+
+	bus.Publish(2)
+	time.Sleep(10 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if count != 1 {
+		t.Errorf("count = %d after Unsubscribe, want 1", count)
+	}
+	if len(bus.Stats()) != 0 {
+		t.Errorf("Stats() returned %d subscribers, want 0", len(bus.Stats()))
+	}
+}
@@ -9,6 +9,7 @@ import (
 	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
 	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
 	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	profilespb "go.opentelemetry.io/proto/otlp/profiles/v1development"
 	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
 	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
 )
@@ -146,9 +147,13 @@ func getValueType(val *commonpb.AnyValue) string {
 }
 
 // ConvertSpan converts an OTLP span to our domain model.
-func ConvertSpan(span *tracepb.Span, resource Resource, scope InstrumentationScope) Span {
+// It returns an error wrapping ErrInvalidItem if the span fails validation.
+func ConvertSpan(span *tracepb.Span, resource Resource, scope InstrumentationScope) (Span, error) {
 	if span == nil {
-		return Span{}
+		return Span{}, invalid("nil span")
+	}
+	if err := validateSpan(span); err != nil {
+		return Span{}, err
 	}
 
 	startTime := time.Unix(0, int64(span.StartTimeUnixNano))
@@ -179,7 +184,7 @@ func ConvertSpan(span *tracepb.Span, resource Resource, scope InstrumentationSco
 		DroppedEventsCount:     span.DroppedEventsCount,
 		DroppedLinksCount:      span.DroppedLinksCount,
 		ReceivedAt:             time.Now(),
-	}
+	}, nil
 }
 
 // convertSpanKind converts an OTLP span kind to our domain model.
@@ -263,9 +268,13 @@ func convertSpanLinks(links []*tracepb.Span_Link) []SpanLink {
 }
 
 // ConvertMetric converts an OTLP metric to our domain model.
-func ConvertMetric(metric *metricspb.Metric, resource Resource, scope InstrumentationScope) Metric {
+// It returns an error wrapping ErrInvalidItem if the metric fails validation.
+func ConvertMetric(metric *metricspb.Metric, resource Resource, scope InstrumentationScope) (Metric, error) {
 	if metric == nil {
-		return Metric{}
+		return Metric{}, invalid("nil metric")
+	}
+	if err := validateMetric(metric); err != nil {
+		return Metric{}, err
 	}
 
 	m := Metric{
@@ -281,26 +290,26 @@ func ConvertMetric(metric *metricspb.Metric, resource Resource, scope Instrument
 	switch data := metric.Data.(type) {
 	case *metricspb.Metric_Gauge:
 		m.Type = MetricTypeGauge
-		m.DataPoints = convertNumberDataPoints(data.Gauge.DataPoints)
+		m.DataPoints = convertNumberDataPoints(data.Gauge.GetDataPoints())
 	case *metricspb.Metric_Sum:
 		m.Type = MetricTypeSum
-		m.AggregationTemporality = convertAggregationTemporality(data.Sum.AggregationTemporality)
-		m.DataPoints = convertNumberDataPoints(data.Sum.DataPoints)
+		m.AggregationTemporality = convertAggregationTemporality(data.Sum.GetAggregationTemporality())
+		m.DataPoints = convertNumberDataPoints(data.Sum.GetDataPoints())
 	case *metricspb.Metric_Histogram:
 		m.Type = MetricTypeHistogram
-		m.AggregationTemporality = convertAggregationTemporality(data.Histogram.AggregationTemporality)
-		m.DataPoints = convertHistogramDataPoints(data.Histogram.DataPoints)
+		m.AggregationTemporality = convertAggregationTemporality(data.Histogram.GetAggregationTemporality())
+		m.DataPoints = convertHistogramDataPoints(data.Histogram.GetDataPoints())
 	case *metricspb.Metric_Summary:
 		m.Type = MetricTypeSummary
-		m.DataPoints = convertSummaryDataPoints(data.Summary.DataPoints)
+		m.DataPoints = convertSummaryDataPoints(data.Summary.GetDataPoints())
 	case *metricspb.Metric_ExponentialHistogram:
 		m.Type = MetricTypeExponentialHistogram
-		m.AggregationTemporality = convertAggregationTemporality(data.ExponentialHistogram.AggregationTemporality)
+		m.AggregationTemporality = convertAggregationTemporality(data.ExponentialHistogram.GetAggregationTemporality())
 		// Simplified handling for exponential histograms
 		m.DataPoints = []DataPoint{}
 	}
 
-	return m
+	return m, nil
 }
 
 // convertAggregationTemporality converts OTLP temporality to string.
@@ -402,9 +411,13 @@ func convertSummaryDataPoints(dps []*metricspb.SummaryDataPoint) []DataPoint {
 }
 
 // ConvertLogRecord converts an OTLP log record to our domain model.
-func ConvertLogRecord(log *logspb.LogRecord, resource Resource, scope InstrumentationScope) LogRecord {
+// It returns an error wrapping ErrInvalidItem if the record fails validation.
+func ConvertLogRecord(log *logspb.LogRecord, resource Resource, scope InstrumentationScope) (LogRecord, error) {
 	if log == nil {
-		return LogRecord{}
+		return LogRecord{}, invalid("nil log record")
+	}
+	if err := validateLogRecord(log); err != nil {
+		return LogRecord{}, err
 	}
 
 	return LogRecord{
@@ -425,7 +438,7 @@ func ConvertLogRecord(log *logspb.LogRecord, resource Resource, scope Instrument
 		Attributes:             convertAttributes(log.Attributes),
 		DroppedAttributesCount: log.DroppedAttributesCount,
 		ReceivedAt:             time.Now(),
-	}
+	}, nil
 }
 
 // normalizeSeverity converts OTLP severity number to our severity level.
@@ -447,3 +460,147 @@ func normalizeSeverity(num int32) SeverityLevel {
 		return SeverityFatal
 	}
 }
+
+// ConvertProfile converts an OTLP profile to our domain model, resolving its
+// references into the export's shared dictionary.
+// It returns an error wrapping ErrInvalidItem if the profile fails validation.
+func ConvertProfile(profile *profilespb.Profile, dict *profilespb.ProfilesDictionary, resource Resource, scope InstrumentationScope) (Profile, error) {
+	if profile == nil {
+		return Profile{}, invalid("nil profile")
+	}
+	if dict == nil {
+		dict = &profilespb.ProfilesDictionary{}
+	}
+	if err := validateProfile(profile, dict); err != nil {
+		return Profile{}, err
+	}
+
+	d := profileDictionary{dict}
+	p := Profile{
+		ID:                     generateID("profile"),
+		ProfileID:              hex.EncodeToString(profile.ProfileId),
+		TimeUnixNano:           int64(profile.TimeUnixNano),
+		Timestamp:              time.Unix(0, int64(profile.TimeUnixNano)),
+		DurationMs:             float64(profile.DurationNano) / 1e6,
+		SampleType:             d.valueType(profile.SampleType),
+		PeriodType:             d.valueType(profile.PeriodType),
+		Period:                 profile.Period,
+		Samples:                make([]ProfileSample, 0, len(profile.Samples)),
+		Locations:              []ProfileLocation{},
+		Functions:              []ProfileFunction{},
+		OriginalPayloadFormat:  profile.OriginalPayloadFormat,
+		Resource:               resource,
+		InstrumentationScope:   scope,
+		Attributes:             d.attributes(profile.AttributeIndices),
+		DroppedAttributesCount: profile.DroppedAttributesCount,
+		ReceivedAt:             time.Now(),
+	}
+	// The dictionary is shared by every profile in the export, so copy only
+	// the locations and functions this profile references.
+	functions := make(map[int32]int)
+	addFunction := func(i int32) int {
+		if idx, ok := functions[i]; ok {
+			return idx
+		}
+		f := dict.FunctionTable[i]
+		functions[i] = len(p.Functions)
+		p.Functions = append(p.Functions, ProfileFunction{
+			Name:       d.str(f.NameStrindex),
+			SystemName: d.str(f.SystemNameStrindex),
+			Filename:   d.str(f.FilenameStrindex),
+			StartLine:  f.StartLine,
+		})
+		return functions[i]
+	}
+	locations := make(map[int32]int)
+	addLocation := func(i int32) int {
+		if idx, ok := locations[i]; ok {
+			return idx
+		}
+		loc := dict.LocationTable[i]
+		var converted ProfileLocation
+		if loc.Address != 0 {
+			converted.Address = fmt.Sprintf("0x%x", loc.Address)
+		}
+		// Index 0 is the table's empty entry, meaning no mapping
+		if loc.MappingIndex != 0 {
+			converted.Mapping = d.str(dict.MappingTable[loc.MappingIndex].FilenameStrindex)
+		}
+		for _, line := range loc.Lines {
+			converted.Lines = append(converted.Lines, ProfileLine{
+				FunctionIndex: addFunction(line.FunctionIndex),
+				Line:          line.Line,
+				Column:        line.Column,
+			})
+		}
+		locations[i] = len(p.Locations)
+		p.Locations = append(p.Locations, converted)
+		return locations[i]
+	}
+
+	for _, s := range profile.Samples {
+		var stack []int32
+		if int(s.StackIndex) < len(dict.StackTable) {
+			stack = dict.StackTable[s.StackIndex].GetLocationIndices()
+		}
+		sample := ProfileSample{
+			LocationIndices: make([]int, 0, len(stack)),
+			Values:          append([]int64{}, s.Values...),
+			Attributes:      d.attributes(s.AttributeIndices),
+		}
+		for _, i := range stack {
+			sample.LocationIndices = append(sample.LocationIndices, addLocation(i))
+		}
+		for _, ts := range s.TimestampsUnixNano {
+			sample.TimestampsUnixNano = append(sample.TimestampsUnixNano, int64(ts))
+		}
+		// Index 0 is the table's empty entry, meaning no link
+		if s.LinkIndex != 0 {
+			link := dict.LinkTable[s.LinkIndex]
+			sample.TraceID = hex.EncodeToString(link.TraceId)
+			sample.SpanID = hex.EncodeToString(link.SpanId)
+		}
+		p.Samples = append(p.Samples, sample)
+	}
+
+	return p, nil
+}
+
+// profileDictionary resolves the string and attribute references of a
+// profiles export. Indices that validation does not check are looked up
+// leniently, yielding empty values when out of range.
+type profileDictionary struct {
+	*profilespb.ProfilesDictionary
+}
+
+// str returns the string at index i of the string table.
+func (d profileDictionary) str(i int32) string {
+	if i < 0 || int(i) >= len(d.StringTable) {
+		return ""
+	}
+	return d.StringTable[i]
+}
+
+// attributes resolves indices into the attribute table, whose keys are
+// string table references.
+func (d profileDictionary) attributes(indices []int32) []Attribute {
+	kvs := make([]*commonpb.KeyValue, 0, len(indices))
+	for _, i := range indices {
+		if i >= 0 && int(i) < len(d.AttributeTable) {
+			attr := d.AttributeTable[i]
+			kvs = append(kvs, &commonpb.KeyValue{Key: d.str(attr.GetKeyStrindex()), Value: attr.GetValue()})
+		}
+	}
+	return convertAttributes(kvs)
+}
+
+// valueType converts an OTLP profile value type.
+func (d profileDictionary) valueType(vt *profilespb.ValueType) ProfileValueType {
+	if vt == nil {
+		return ProfileValueType{}
+	}
+	return ProfileValueType{
+		Type: d.str(vt.TypeStrindex),
+		Unit: d.str(vt.UnitStrindex),
+	}
+}
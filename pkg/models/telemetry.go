@@ -14,6 +14,9 @@ const (
 	SignalTypeTrace  SignalType = "trace"
 	SignalTypeMetric SignalType = "metric"
 	SignalTypeLog    SignalType = "log"
+
+	// SignalTypeProfile is the development OTLP profiles signal.
+	SignalTypeProfile SignalType = "profile"
 )
 
 // SeverityLevel represents the severity of a log or span status.
@@ -128,6 +131,7 @@ type Span struct {
 
 	// Metadata
 	ReceivedAt time.Time `json:"receivedAt"`
+	Source     string    `json:"source,omitempty"` // Client that exported the span (see ClientInfo.ID)
 }
 
 // IsError returns true if the span has an error status.
@@ -196,6 +200,7 @@ type Metric struct {
 
 	// Metadata
 	ReceivedAt time.Time `json:"receivedAt"`
+	Source     string    `json:"source,omitempty"` // Client that exported the metric (see ClientInfo.ID)
 }
 
 // LogRecord represents a log entry.
@@ -230,6 +235,7 @@ type LogRecord struct {
 
 	// Metadata
 	ReceivedAt time.Time `json:"receivedAt"`
+	Source     string    `json:"source,omitempty"` // Client that exported the log (see ClientInfo.ID)
 }
 
 // IsError returns true if the log has an error or higher severity.
@@ -237,24 +243,153 @@ func (l *LogRecord) IsError() bool {
 	return l.SeverityNumber >= 17 // ERROR and above in OTLP
 }
 
+// ProfileValueType describes the type and unit of a profile value,
+// e.g. "cpu"/"nanoseconds" or "alloc_space"/"bytes".
+type ProfileValueType struct {
+	Type string `json:"type"`
+	Unit string `json:"unit"`
+}
+
+// ProfileFunction is a function referenced by a profile's locations.
+type ProfileFunction struct {
+	Name       string `json:"name"`
+	SystemName string `json:"systemName,omitempty"` // Mangled name, when different
+	Filename   string `json:"filename,omitempty"`
+	StartLine  int64  `json:"startLine,omitempty"`
+}
+
+// ProfileLine is a source position within a location.
+type ProfileLine struct {
+	FunctionIndex int   `json:"functionIndex"` // Index into Profile.Functions
+	Line          int64 `json:"line,omitempty"`
+	Column        int64 `json:"column,omitempty"`
+}
+
+// ProfileLocation is an instruction address and the source lines it maps to.
+// Several lines mean inlined calls, innermost first.
+type ProfileLocation struct {
+	Address string        `json:"address,omitempty"` // Hex, e.g. 0x4a2f10
+	Mapping string        `json:"mapping,omitempty"` // Binary or shared library
+	Lines   []ProfileLine `json:"lines,omitempty"`
+}
+
+// ProfileSample is a stack trace and the values measured for it.
+type ProfileSample struct {
+	LocationIndices    []int       `json:"locationIndices"` // Stack as indices into Profile.Locations, leaf first
+	Values             []int64     `json:"values"`          // Measured in Profile.SampleType
+	Attributes         []Attribute `json:"attributes,omitempty"`
+	TimestampsUnixNano []int64     `json:"timestampsUnixNano,omitempty"`
+
+	// Span the sample was taken in, if the profiler linked it
+	TraceID string `json:"traceId,omitempty"`
+	SpanID  string `json:"spanId,omitempty"`
+}
+
+// Profile represents a collected profile. Locations and functions are
+// resolved from the export's shared dictionary into per-profile tables.
+type Profile struct {
+	// Identity
+	ID        string `json:"id"`                  // Internal ID for React keys
+	ProfileID string `json:"profileId,omitempty"` // Hex-encoded profile ID
+
+	// Timing
+	TimeUnixNano int64     `json:"timeUnixNano"`
+	Timestamp    time.Time `json:"timestamp"`
+	DurationMs   float64   `json:"durationMs"`
+
+	// What was sampled
+	SampleType ProfileValueType `json:"sampleType"`
+	PeriodType ProfileValueType `json:"periodType"`
+	Period     int64            `json:"period,omitempty"`
+
+	// Data
+	Samples   []ProfileSample   `json:"samples"`
+	Locations []ProfileLocation `json:"locations"`
+	Functions []ProfileFunction `json:"functions"`
+
+	OriginalPayloadFormat string `json:"originalPayloadFormat,omitempty"`
+
+	// Context
+	Resource             Resource             `json:"resource"`
+	InstrumentationScope InstrumentationScope `json:"instrumentationScope"`
+	Attributes           []Attribute          `json:"attributes,omitempty"`
+
+	// Counts
+	DroppedAttributesCount uint32 `json:"droppedAttributesCount,omitempty"`
+
+	// Metadata
+	ReceivedAt time.Time `json:"receivedAt"`
+	Source     string    `json:"source,omitempty"` // Client that exported the profile (see ClientInfo.ID)
+}
+
+// SamplesForSpan returns the samples linked to the given span.
+func (p *Profile) SamplesForSpan(traceID, spanID string) []ProfileSample {
+	var samples []ProfileSample
+	for _, s := range p.Samples {
+		if s.SpanID == spanID && s.TraceID == traceID {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// ClientInfo describes a client connection that has exported telemetry,
+// similar to an "Endpoints" view in a packet analyzer.
+type ClientInfo struct {
+	ID           string    `json:"id"`        // Source identifier stamped on stored items
+	Transport    string    `json:"transport"` // grpc, http
+	PeerAddress  string    `json:"peerAddress"`
+	UserAgent    string    `json:"userAgent,omitempty"`
+	ServiceNames []string  `json:"serviceNames"`
+	Connected    bool      `json:"connected"`
+	FirstSeen    time.Time `json:"firstSeen"`
+	LastSeen     time.Time `json:"lastSeen"`
+	Exports      uint64    `json:"exports"`
+	Bytes        uint64    `json:"bytes"` // Uncompressed request payload bytes
+	Spans        uint64    `json:"spans"`
+	Metrics      uint64    `json:"metrics"`
+	Logs         uint64    `json:"logs"`
+	Profiles     uint64    `json:"profiles"`
+}
+
+// Rejection records why an exported item was refused by the receiver.
+type Rejection struct {
+	Signal      SignalType `json:"signal"`
+	ServiceName string     `json:"serviceName"`
+	Reason      string     `json:"reason"`
+	Timestamp   time.Time  `json:"timestamp"`
+}
+
 // TelemetryStats represents statistics about stored telemetry.
 type TelemetryStats struct {
-	TraceCount     int     `json:"traceCount"`
-	MetricCount    int     `json:"metricCount"`
-	LogCount       int     `json:"logCount"`
-	TraceCapacity  int     `json:"traceCapacity"`
-	MetricCapacity int     `json:"metricCapacity"`
-	LogCapacity    int     `json:"logCapacity"`
-	TraceUsage     float64 `json:"traceUsage"`
-	MetricUsage    float64 `json:"metricUsage"`
-	LogUsage       float64 `json:"logUsage"`
+	TraceCount      int     `json:"traceCount"`
+	MetricCount     int     `json:"metricCount"`
+	LogCount        int     `json:"logCount"`
+	ProfileCount    int     `json:"profileCount"`
+	TraceCapacity   int     `json:"traceCapacity"`
+	MetricCapacity  int     `json:"metricCapacity"`
+	LogCapacity     int     `json:"logCapacity"`
+	ProfileCapacity int     `json:"profileCapacity"`
+	TraceUsage      float64 `json:"traceUsage"`
+	MetricUsage     float64 `json:"metricUsage"`
+	LogUsage        float64 `json:"logUsage"`
+	ProfileUsage    float64 `json:"profileUsage"`
 }
 
 // TelemetryBatch represents a batch of telemetry data for the frontend.
 type TelemetryBatch struct {
-	Spans   []Span      `json:"spans,omitempty"`
-	Metrics []Metric    `json:"metrics,omitempty"`
-	Logs    []LogRecord `json:"logs,omitempty"`
+	Spans    []Span      `json:"spans,omitempty"`
+	Metrics  []Metric    `json:"metrics,omitempty"`
+	Logs     []LogRecord `json:"logs,omitempty"`
+	Profiles []Profile   `json:"profiles,omitempty"`
+}
+
+// EventBatch is a group of real-time events coalesced into a single push to
+// the frontend. Dropped counts events discarded by rate limiting since the
+// previous batch.
+type EventBatch struct {
+	TelemetryBatch
+	Dropped uint64 `json:"dropped"`
 }
 
 // TelemetryEvent represents a real-time event pushed to the frontend.
@@ -263,5 +398,6 @@ type TelemetryEvent struct {
 	Span      *Span       `json:"span,omitempty"`
 	Metric    *Metric     `json:"metric,omitempty"`
 	Log       *LogRecord  `json:"log,omitempty"`
+	Profile   *Profile    `json:"profile,omitempty"`
 	Timestamp time.Time   `json:"timestamp"`
 }
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	profilespb "go.opentelemetry.io/proto/otlp/profiles/v1development"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Identifier lengths mandated by the OTLP specification.
+const (
+	traceIDLength   = 16
+	spanIDLength    = 8
+	profileIDLength = 16
+)
+
+// Maximum valid OTLP severity number (FATAL4).
+const maxSeverityNumber = 24
+
+// ErrInvalidItem is wrapped by every validation error so callers can
+// distinguish rejected items from other conversion failures.
+var ErrInvalidItem = errors.New("invalid telemetry item")
+
+// invalid builds a validation error wrapping ErrInvalidItem.
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidItem, fmt.Sprintf(format, args...))
+}
+
+// validateSpan checks the identifiers of an OTLP span.
+func validateSpan(span *tracepb.Span) error {
+	if len(span.TraceId) != traceIDLength {
+		return invalid("span %q has trace ID of length %d, want %d", span.Name, len(span.TraceId), traceIDLength)
+	}
+	if len(span.SpanId) != spanIDLength {
+		return invalid("span %q has span ID of length %d, want %d", span.Name, len(span.SpanId), spanIDLength)
+	}
+	if n := len(span.ParentSpanId); n != 0 && n != spanIDLength {
+		return invalid("span %q has parent span ID of length %d, want %d", span.Name, n, spanIDLength)
+	}
+	return nil
+}
+
+// validateMetric checks that an OTLP metric is named and carries data.
+func validateMetric(metric *metricspb.Metric) error {
+	if metric.Name == "" {
+		return invalid("metric has empty name")
+	}
+	if metric.Data == nil {
+		return invalid("metric %q has no data", metric.Name)
+	}
+	return nil
+}
+
+// validateLogRecord checks the severity and correlation IDs of an OTLP log record.
+func validateLogRecord(log *logspb.LogRecord) error {
+	if n := int32(log.SeverityNumber); n < 0 || n > maxSeverityNumber {
+		return invalid("log record has severity number %d outside 0-%d", n, maxSeverityNumber)
+	}
+	if n := len(log.TraceId); n != 0 && n != traceIDLength {
+		return invalid("log record has trace ID of length %d, want %d", n, traceIDLength)
+	}
+	if n := len(log.SpanId); n != 0 && n != spanIDLength {
+		return invalid("log record has span ID of length %d, want %d", n, spanIDLength)
+	}
+	return nil
+}
+
+// validateProfile checks that every stack, function, mapping and link a
+// profile references exists in the dictionary, so conversion can index it
+// directly.
+func validateProfile(profile *profilespb.Profile, dict *profilespb.ProfilesDictionary) error {
+	if n := len(profile.ProfileId); n != 0 && n != profileIDLength {
+		return invalid("profile has ID of length %d, want %d", n, profileIDLength)
+	}
+	for n, s := range profile.Samples {
+		// Index 0 is each table's empty first entry, so it is valid even
+		// when an exporter leaves the table out.
+		if s.StackIndex < 0 || (s.StackIndex != 0 && int(s.StackIndex) >= len(dict.StackTable)) {
+			return invalid("sample %d references stack %d of %d", n, s.StackIndex, len(dict.StackTable))
+		}
+		if int(s.StackIndex) < len(dict.StackTable) {
+			if err := validateStack(dict, s.StackIndex); err != nil {
+				return err
+			}
+		}
+		if s.LinkIndex == 0 {
+			continue
+		}
+		if s.LinkIndex < 0 || int(s.LinkIndex) >= len(dict.LinkTable) {
+			return invalid("sample %d references link %d of %d", n, s.LinkIndex, len(dict.LinkTable))
+		}
+		link := dict.LinkTable[s.LinkIndex]
+		if len(link.TraceId) != traceIDLength || len(link.SpanId) != spanIDLength {
+			return invalid("sample %d links to trace/span IDs of length %d/%d, want %d/%d",
+				n, len(link.TraceId), len(link.SpanId), traceIDLength, spanIDLength)
+		}
+	}
+	return nil
+}
+
+// validateStack checks the locations of a stack and the mappings and
+// functions they reference.
+func validateStack(dict *profilespb.ProfilesDictionary, stack int32) error {
+	for _, i := range dict.StackTable[stack].GetLocationIndices() {
+		if i < 0 || int(i) >= len(dict.LocationTable) {
+			return invalid("stack %d references location %d of %d", stack, i, len(dict.LocationTable))
+		}
+		loc := dict.LocationTable[i]
+		if loc.MappingIndex < 0 || (loc.MappingIndex != 0 && int(loc.MappingIndex) >= len(dict.MappingTable)) {
+			return invalid("location %d references mapping %d of %d", i, loc.MappingIndex, len(dict.MappingTable))
+		}
+		for _, line := range loc.Lines {
+			if line.FunctionIndex < 0 || int(line.FunctionIndex) >= len(dict.FunctionTable) {
+				return invalid("location %d references function %d of %d", i, line.FunctionIndex, len(dict.FunctionTable))
+			}
+		}
+	}
+	return nil
+}
+
+// DataPointCount returns the number of data points carried by an OTLP metric,
+// used to report rejected_data_points in partial-success responses.
+func DataPointCount(metric *metricspb.Metric) int {
+	if metric == nil {
+		return 0
+	}
+	switch data := metric.Data.(type) {
+	case *metricspb.Metric_Gauge:
+		return len(data.Gauge.GetDataPoints())
+	case *metricspb.Metric_Sum:
+		return len(data.Sum.GetDataPoints())
+	case *metricspb.Metric_Histogram:
+		return len(data.Histogram.GetDataPoints())
+	case *metricspb.Metric_ExponentialHistogram:
+		return len(data.ExponentialHistogram.GetDataPoints())
+	case *metricspb.Metric_Summary:
+		return len(data.Summary.GetDataPoints())
+	default:
+		return 0
+	}
+}